@@ -0,0 +1,422 @@
+// Package resourceid provides the Azure resource ID shapes of azurerm resource types.
+package resourceid
+
+import "sort"
+
+// rg is the prefix of resources that are deployed to a resource group.
+const rg = "/subscriptions/{subscription_id}/resourceGroups/{resource_group_name}/providers/"
+
+// sub is the prefix of resources that are deployed to a subscription.
+const sub = "/subscriptions/{subscription_id}/providers/"
+
+// templates maps azurerm resource types to their resource ID template.
+// Resource types whose ID is not an Azure resource ID (e.g. Key Vault secrets, which use
+// the secret URL) or is a composite of several IDs (e.g. most association resources)
+// are not listed.
+var templates = map[string]string{
+	// Resources and management
+	"azurerm_resource_group":                     "/subscriptions/{subscription_id}/resourceGroups/{name}",
+	"azurerm_resource_group_template_deployment": rg + "Microsoft.Resources/deployments/{name}",
+	"azurerm_resource_provider_registration":     sub + "{name}",
+	"azurerm_management_group":                   "/providers/Microsoft.Management/managementGroups/{name}",
+	"azurerm_management_lock":                    "{scope}/providers/Microsoft.Authorization/locks/{name}",
+	"azurerm_maintenance_configuration":          rg + "Microsoft.Maintenance/maintenanceConfigurations/{name}",
+	"azurerm_portal_dashboard":                   rg + "Microsoft.Portal/dashboards/{name}",
+
+	// Authorization and policy
+	"azurerm_role_assignment":                    "{scope}/providers/Microsoft.Authorization/roleAssignments/{name}",
+	"azurerm_policy_definition":                  sub + "Microsoft.Authorization/policyDefinitions/{name}",
+	"azurerm_policy_set_definition":              sub + "Microsoft.Authorization/policySetDefinitions/{name}",
+	"azurerm_management_group_policy_assignment": "{management_group_id}/providers/Microsoft.Authorization/policyAssignments/{name}",
+	"azurerm_subscription_policy_assignment":     "{subscription_id}/providers/Microsoft.Authorization/policyAssignments/{name}",
+	"azurerm_resource_group_policy_assignment":   "{resource_group_id}/providers/Microsoft.Authorization/policyAssignments/{name}",
+	"azurerm_resource_policy_assignment":         "{resource_id}/providers/Microsoft.Authorization/policyAssignments/{name}",
+
+	// Identity and secrets
+	"azurerm_user_assigned_identity":                     rg + "Microsoft.ManagedIdentity/userAssignedIdentities/{name}",
+	"azurerm_federated_identity_credential":              "{user_assigned_identity_id}/federatedIdentityCredentials/{name}",
+	"azurerm_key_vault":                                  rg + "Microsoft.KeyVault/vaults/{name}",
+	"azurerm_key_vault_managed_hardware_security_module": rg + "Microsoft.KeyVault/managedHSMs/{name}",
+
+	// Cost and security
+	"azurerm_consumption_budget_resource_group":    "{resource_group_id}/providers/Microsoft.Consumption/budgets/{name}",
+	"azurerm_consumption_budget_subscription":      "{subscription_id}/providers/Microsoft.Consumption/budgets/{name}",
+	"azurerm_security_center_subscription_pricing": sub + "Microsoft.Security/pricings/{resource_type}",
+
+	// Storage
+	"azurerm_storage_account":               rg + "Microsoft.Storage/storageAccounts/{name}",
+	"azurerm_storage_account_network_rules": "{storage_account_id}",
+	"azurerm_storage_account_local_user":    "{storage_account_id}/localUsers/{name}",
+	"azurerm_storage_management_policy":     "{storage_account_id}/managementPolicies/default",
+	"azurerm_storage_encryption_scope":      "{storage_account_id}/encryptionScopes/{name}",
+	"azurerm_storage_container":             "{storage_account_id}/blobServices/default/containers/{name}",
+	"azurerm_storage_share":                 "{storage_account_id}/fileServices/default/shares/{name}",
+	"azurerm_storage_queue":                 "{storage_account_id}/queueServices/default/queues/{name}",
+	"azurerm_storage_table":                 "{storage_account_id}/tableServices/default/tables/{name}",
+	"azurerm_storage_sync":                  rg + "Microsoft.StorageSync/storageSyncServices/{name}",
+	"azurerm_storage_sync_group":            "{storage_sync_id}/syncGroups/{name}",
+	"azurerm_netapp_account":                rg + "Microsoft.NetApp/netAppAccounts/{name}",
+	"azurerm_netapp_pool":                   rg + "Microsoft.NetApp/netAppAccounts/{account_name}/capacityPools/{name}",
+	"azurerm_netapp_volume":                 rg + "Microsoft.NetApp/netAppAccounts/{account_name}/capacityPools/{pool_name}/volumes/{name}",
+
+	// Virtual networks
+	"azurerm_virtual_network":                              rg + "Microsoft.Network/virtualNetworks/{name}",
+	"azurerm_subnet":                                       rg + "Microsoft.Network/virtualNetworks/{virtual_network_name}/subnets/{name}",
+	"azurerm_virtual_network_peering":                      rg + "Microsoft.Network/virtualNetworks/{virtual_network_name}/virtualNetworkPeerings/{name}",
+	"azurerm_subnet_network_security_group_association":    "{subnet_id}",
+	"azurerm_subnet_route_table_association":               "{subnet_id}",
+	"azurerm_subnet_nat_gateway_association":               "{subnet_id}",
+	"azurerm_network_security_group":                       rg + "Microsoft.Network/networkSecurityGroups/{name}",
+	"azurerm_network_security_rule":                        rg + "Microsoft.Network/networkSecurityGroups/{network_security_group_name}/securityRules/{name}",
+	"azurerm_application_security_group":                   rg + "Microsoft.Network/applicationSecurityGroups/{name}",
+	"azurerm_network_interface":                            rg + "Microsoft.Network/networkInterfaces/{name}",
+	"azurerm_public_ip":                                    rg + "Microsoft.Network/publicIPAddresses/{name}",
+	"azurerm_public_ip_prefix":                             rg + "Microsoft.Network/publicIPPrefixes/{name}",
+	"azurerm_route_table":                                  rg + "Microsoft.Network/routeTables/{name}",
+	"azurerm_route":                                        rg + "Microsoft.Network/routeTables/{route_table_name}/routes/{name}",
+	"azurerm_route_filter":                                 rg + "Microsoft.Network/routeFilters/{name}",
+	"azurerm_route_server":                                 rg + "Microsoft.Network/virtualHubs/{name}",
+	"azurerm_nat_gateway":                                  rg + "Microsoft.Network/natGateways/{name}",
+	"azurerm_ip_group":                                     rg + "Microsoft.Network/ipGroups/{name}",
+	"azurerm_network_profile":                              rg + "Microsoft.Network/networkProfiles/{name}",
+	"azurerm_network_ddos_protection_plan":                 rg + "Microsoft.Network/ddosProtectionPlans/{name}",
+	"azurerm_network_watcher":                              rg + "Microsoft.Network/networkWatchers/{name}",
+	"azurerm_network_watcher_flow_log":                     rg + "Microsoft.Network/networkWatchers/{network_watcher_name}/flowLogs/{name}",
+	"azurerm_network_connection_monitor":                   "{network_watcher_id}/connectionMonitors/{name}",
+	"azurerm_network_manager":                              rg + "Microsoft.Network/networkManagers/{name}",
+	"azurerm_network_manager_network_group":                "{network_manager_id}/networkGroups/{name}",
+	"azurerm_network_manager_connectivity_configuration":   "{network_manager_id}/connectivityConfigurations/{name}",
+	"azurerm_network_manager_security_admin_configuration": "{network_manager_id}/securityAdminConfigurations/{name}",
+	"azurerm_bastion_host":                                 rg + "Microsoft.Network/bastionHosts/{name}",
+	"azurerm_private_endpoint":                             rg + "Microsoft.Network/privateEndpoints/{name}",
+	"azurerm_private_link_service":                         rg + "Microsoft.Network/privateLinkServices/{name}",
+
+	// Load balancing and traffic management
+	"azurerm_lb":                                rg + "Microsoft.Network/loadBalancers/{name}",
+	"azurerm_lb_backend_address_pool":           "{loadbalancer_id}/backendAddressPools/{name}",
+	"azurerm_lb_probe":                          "{loadbalancer_id}/probes/{name}",
+	"azurerm_lb_rule":                           "{loadbalancer_id}/loadBalancingRules/{name}",
+	"azurerm_lb_nat_rule":                       "{loadbalancer_id}/inboundNatRules/{name}",
+	"azurerm_lb_nat_pool":                       "{loadbalancer_id}/inboundNatPools/{name}",
+	"azurerm_lb_outbound_rule":                  "{loadbalancer_id}/outboundRules/{name}",
+	"azurerm_application_gateway":               rg + "Microsoft.Network/applicationGateways/{name}",
+	"azurerm_web_application_firewall_policy":   rg + "Microsoft.Network/ApplicationGatewayWebApplicationFirewallPolicies/{name}",
+	"azurerm_frontdoor":                         rg + "Microsoft.Network/frontDoors/{name}",
+	"azurerm_traffic_manager_profile":           rg + "Microsoft.Network/trafficManagerProfiles/{name}",
+	"azurerm_traffic_manager_azure_endpoint":    "{profile_id}/azureEndpoints/{name}",
+	"azurerm_traffic_manager_external_endpoint": "{profile_id}/externalEndpoints/{name}",
+
+	// Firewalls
+	"azurerm_firewall": rg + "Microsoft.Network/azureFirewalls/{name}",
+	"azurerm_firewall_application_rule_collection":  rg + "Microsoft.Network/azureFirewalls/{azure_firewall_name}/applicationRuleCollections/{name}",
+	"azurerm_firewall_nat_rule_collection":          rg + "Microsoft.Network/azureFirewalls/{azure_firewall_name}/natRuleCollections/{name}",
+	"azurerm_firewall_network_rule_collection":      rg + "Microsoft.Network/azureFirewalls/{azure_firewall_name}/networkRuleCollections/{name}",
+	"azurerm_firewall_policy":                       rg + "Microsoft.Network/firewallPolicies/{name}",
+	"azurerm_firewall_policy_rule_collection_group": "{firewall_policy_id}/ruleCollectionGroups/{name}",
+
+	// Hybrid connectivity
+	"azurerm_virtual_network_gateway":             rg + "Microsoft.Network/virtualNetworkGateways/{name}",
+	"azurerm_virtual_network_gateway_connection":  rg + "Microsoft.Network/connections/{name}",
+	"azurerm_local_network_gateway":               rg + "Microsoft.Network/localNetworkGateways/{name}",
+	"azurerm_express_route_circuit":               rg + "Microsoft.Network/expressRouteCircuits/{name}",
+	"azurerm_express_route_circuit_authorization": rg + "Microsoft.Network/expressRouteCircuits/{express_route_circuit_name}/authorizations/{name}",
+	"azurerm_express_route_circuit_peering":       rg + "Microsoft.Network/expressRouteCircuits/{express_route_circuit_name}/peerings/{peering_type}",
+	"azurerm_express_route_gateway":               rg + "Microsoft.Network/expressRouteGateways/{name}",
+	"azurerm_express_route_connection":            "{express_route_gateway_id}/expressRouteConnections/{name}",
+	"azurerm_express_route_port":                  rg + "Microsoft.Network/expressRoutePorts/{name}",
+	"azurerm_virtual_wan":                         rg + "Microsoft.Network/virtualWans/{name}",
+	"azurerm_virtual_hub":                         rg + "Microsoft.Network/virtualHubs/{name}",
+	"azurerm_virtual_hub_connection":              "{virtual_hub_id}/hubVirtualNetworkConnections/{name}",
+	"azurerm_virtual_hub_route_table":             "{virtual_hub_id}/hubRouteTables/{name}",
+	"azurerm_virtual_hub_ip":                      "{virtual_hub_id}/ipConfigurations/{name}",
+	"azurerm_vpn_gateway":                         rg + "Microsoft.Network/vpnGateways/{name}",
+	"azurerm_vpn_gateway_connection":              "{vpn_gateway_id}/vpnConnections/{name}",
+	"azurerm_vpn_site":                            rg + "Microsoft.Network/vpnSites/{name}",
+	"azurerm_vpn_server_configuration":            rg + "Microsoft.Network/vpnServerConfigurations/{name}",
+	"azurerm_point_to_site_vpn_gateway":           rg + "Microsoft.Network/p2sVpnGateways/{name}",
+
+	// DNS
+	"azurerm_dns_zone":                                    rg + "Microsoft.Network/dnsZones/{name}",
+	"azurerm_dns_a_record":                                rg + "Microsoft.Network/dnsZones/{zone_name}/A/{name}",
+	"azurerm_dns_aaaa_record":                             rg + "Microsoft.Network/dnsZones/{zone_name}/AAAA/{name}",
+	"azurerm_dns_caa_record":                              rg + "Microsoft.Network/dnsZones/{zone_name}/CAA/{name}",
+	"azurerm_dns_cname_record":                            rg + "Microsoft.Network/dnsZones/{zone_name}/CNAME/{name}",
+	"azurerm_dns_mx_record":                               rg + "Microsoft.Network/dnsZones/{zone_name}/MX/{name}",
+	"azurerm_dns_ns_record":                               rg + "Microsoft.Network/dnsZones/{zone_name}/NS/{name}",
+	"azurerm_dns_ptr_record":                              rg + "Microsoft.Network/dnsZones/{zone_name}/PTR/{name}",
+	"azurerm_dns_srv_record":                              rg + "Microsoft.Network/dnsZones/{zone_name}/SRV/{name}",
+	"azurerm_dns_txt_record":                              rg + "Microsoft.Network/dnsZones/{zone_name}/TXT/{name}",
+	"azurerm_private_dns_zone":                            rg + "Microsoft.Network/privateDnsZones/{name}",
+	"azurerm_private_dns_zone_virtual_network_link":       "{private_dns_zone_id}/virtualNetworkLinks/{name}",
+	"azurerm_private_dns_a_record":                        "{private_dns_zone_id}/A/{name}",
+	"azurerm_private_dns_aaaa_record":                     "{private_dns_zone_id}/AAAA/{name}",
+	"azurerm_private_dns_cname_record":                    "{private_dns_zone_id}/CNAME/{name}",
+	"azurerm_private_dns_mx_record":                       "{private_dns_zone_id}/MX/{name}",
+	"azurerm_private_dns_ptr_record":                      "{private_dns_zone_id}/PTR/{name}",
+	"azurerm_private_dns_srv_record":                      "{private_dns_zone_id}/SRV/{name}",
+	"azurerm_private_dns_txt_record":                      "{private_dns_zone_id}/TXT/{name}",
+	"azurerm_private_dns_resolver":                        rg + "Microsoft.Network/dnsResolvers/{name}",
+	"azurerm_private_dns_resolver_inbound_endpoint":       "{private_dns_resolver_id}/inboundEndpoints/{name}",
+	"azurerm_private_dns_resolver_outbound_endpoint":      "{private_dns_resolver_id}/outboundEndpoints/{name}",
+	"azurerm_private_dns_resolver_dns_forwarding_ruleset": rg + "Microsoft.Network/dnsForwardingRulesets/{name}",
+	"azurerm_private_dns_resolver_forwarding_rule":        "{dns_forwarding_ruleset_id}/forwardingRules/{name}",
+	"azurerm_private_dns_resolver_virtual_network_link":   "{dns_forwarding_ruleset_id}/virtualNetworkLinks/{name}",
+
+	// CDN and Front Door
+	"azurerm_cdn_profile":                   rg + "Microsoft.Cdn/profiles/{name}",
+	"azurerm_cdn_endpoint":                  rg + "Microsoft.Cdn/profiles/{profile_name}/endpoints/{name}",
+	"azurerm_cdn_frontdoor_profile":         rg + "Microsoft.Cdn/profiles/{name}",
+	"azurerm_cdn_frontdoor_endpoint":        "{cdn_frontdoor_profile_id}/afdEndpoints/{name}",
+	"azurerm_cdn_frontdoor_origin_group":    "{cdn_frontdoor_profile_id}/originGroups/{name}",
+	"azurerm_cdn_frontdoor_origin":          "{cdn_frontdoor_origin_group_id}/origins/{name}",
+	"azurerm_cdn_frontdoor_route":           "{cdn_frontdoor_endpoint_id}/routes/{name}",
+	"azurerm_cdn_frontdoor_custom_domain":   "{cdn_frontdoor_profile_id}/customDomains/{name}",
+	"azurerm_cdn_frontdoor_rule_set":        "{cdn_frontdoor_profile_id}/ruleSets/{name}",
+	"azurerm_cdn_frontdoor_rule":            "{cdn_frontdoor_rule_set_id}/rules/{name}",
+	"azurerm_cdn_frontdoor_security_policy": "{cdn_frontdoor_profile_id}/securityPolicies/{name}",
+	"azurerm_cdn_frontdoor_firewall_policy": rg + "Microsoft.Network/frontDoorWebApplicationFirewallPolicies/{name}",
+
+	// Compute
+	"azurerm_linux_virtual_machine":                  rg + "Microsoft.Compute/virtualMachines/{name}",
+	"azurerm_windows_virtual_machine":                rg + "Microsoft.Compute/virtualMachines/{name}",
+	"azurerm_virtual_machine":                        rg + "Microsoft.Compute/virtualMachines/{name}",
+	"azurerm_virtual_machine_extension":              "{virtual_machine_id}/extensions/{name}",
+	"azurerm_linux_virtual_machine_scale_set":        rg + "Microsoft.Compute/virtualMachineScaleSets/{name}",
+	"azurerm_windows_virtual_machine_scale_set":      rg + "Microsoft.Compute/virtualMachineScaleSets/{name}",
+	"azurerm_orchestrated_virtual_machine_scale_set": rg + "Microsoft.Compute/virtualMachineScaleSets/{name}",
+	"azurerm_virtual_machine_scale_set":              rg + "Microsoft.Compute/virtualMachineScaleSets/{name}",
+	"azurerm_virtual_machine_scale_set_extension":    "{virtual_machine_scale_set_id}/extensions/{name}",
+	"azurerm_availability_set":                       rg + "Microsoft.Compute/availabilitySets/{name}",
+	"azurerm_proximity_placement_group":              rg + "Microsoft.Compute/proximityPlacementGroups/{name}",
+	"azurerm_dedicated_host_group":                   rg + "Microsoft.Compute/hostGroups/{name}",
+	"azurerm_dedicated_host":                         "{dedicated_host_group_id}/hosts/{name}",
+	"azurerm_capacity_reservation_group":             rg + "Microsoft.Compute/capacityReservationGroups/{name}",
+	"azurerm_capacity_reservation":                   "{capacity_reservation_group_id}/capacityReservations/{name}",
+	"azurerm_managed_disk":                           rg + "Microsoft.Compute/disks/{name}",
+	"azurerm_snapshot":                               rg + "Microsoft.Compute/snapshots/{name}",
+	"azurerm_image":                                  rg + "Microsoft.Compute/images/{name}",
+	"azurerm_disk_encryption_set":                    rg + "Microsoft.Compute/diskEncryptionSets/{name}",
+	"azurerm_disk_access":                            rg + "Microsoft.Compute/diskAccesses/{name}",
+	"azurerm_ssh_public_key":                         rg + "Microsoft.Compute/sshPublicKeys/{name}",
+	"azurerm_shared_image_gallery":                   rg + "Microsoft.Compute/galleries/{name}",
+	"azurerm_shared_image":                           rg + "Microsoft.Compute/galleries/{gallery_name}/images/{name}",
+	"azurerm_shared_image_version":                   rg + "Microsoft.Compute/galleries/{gallery_name}/images/{image_name}/versions/{name}",
+	"azurerm_batch_account":                          rg + "Microsoft.Batch/batchAccounts/{name}",
+	"azurerm_batch_pool":                             rg + "Microsoft.Batch/batchAccounts/{account_name}/pools/{name}",
+	"azurerm_virtual_desktop_host_pool":              rg + "Microsoft.DesktopVirtualization/hostPools/{name}",
+	"azurerm_virtual_desktop_workspace":              rg + "Microsoft.DesktopVirtualization/workspaces/{name}",
+	"azurerm_virtual_desktop_application_group":      rg + "Microsoft.DesktopVirtualization/applicationGroups/{name}",
+	"azurerm_dev_test_lab":                           rg + "Microsoft.DevTestLab/labs/{name}",
+
+	// Containers
+	"azurerm_kubernetes_cluster":                       rg + "Microsoft.ContainerService/managedClusters/{name}",
+	"azurerm_kubernetes_cluster_node_pool":             "{kubernetes_cluster_id}/agentPools/{name}",
+	"azurerm_kubernetes_cluster_extension":             "{cluster_id}/providers/Microsoft.KubernetesConfiguration/extensions/{name}",
+	"azurerm_kubernetes_flux_configuration":            "{cluster_id}/providers/Microsoft.KubernetesConfiguration/fluxConfigurations/{name}",
+	"azurerm_kubernetes_fleet_manager":                 rg + "Microsoft.ContainerService/fleets/{name}",
+	"azurerm_container_registry":                       rg + "Microsoft.ContainerRegistry/registries/{name}",
+	"azurerm_container_registry_webhook":               rg + "Microsoft.ContainerRegistry/registries/{registry_name}/webHooks/{name}",
+	"azurerm_container_registry_scope_map":             rg + "Microsoft.ContainerRegistry/registries/{container_registry_name}/scopeMaps/{name}",
+	"azurerm_container_registry_token":                 rg + "Microsoft.ContainerRegistry/registries/{container_registry_name}/tokens/{name}",
+	"azurerm_container_registry_agent_pool":            rg + "Microsoft.ContainerRegistry/registries/{container_registry_name}/agentPools/{name}",
+	"azurerm_container_registry_task":                  "{container_registry_id}/tasks/{name}",
+	"azurerm_container_group":                          rg + "Microsoft.ContainerInstance/containerGroups/{name}",
+	"azurerm_container_app_environment":                rg + "Microsoft.App/managedEnvironments/{name}",
+	"azurerm_container_app_environment_certificate":    "{container_app_environment_id}/certificates/{name}",
+	"azurerm_container_app_environment_dapr_component": "{container_app_environment_id}/daprComponents/{name}",
+	"azurerm_container_app_environment_storage":        "{container_app_environment_id}/storages/{name}",
+	"azurerm_container_app":                            rg + "Microsoft.App/containerApps/{name}",
+	"azurerm_container_app_job":                        rg + "Microsoft.App/jobs/{name}",
+
+	// App Service
+	"azurerm_service_plan":                                 rg + "Microsoft.Web/serverFarms/{name}",
+	"azurerm_app_service_plan":                             rg + "Microsoft.Web/serverFarms/{name}",
+	"azurerm_app_service_environment_v3":                   rg + "Microsoft.Web/hostingEnvironments/{name}",
+	"azurerm_app_service":                                  rg + "Microsoft.Web/sites/{name}",
+	"azurerm_function_app":                                 rg + "Microsoft.Web/sites/{name}",
+	"azurerm_linux_web_app":                                rg + "Microsoft.Web/sites/{name}",
+	"azurerm_windows_web_app":                              rg + "Microsoft.Web/sites/{name}",
+	"azurerm_linux_function_app":                           rg + "Microsoft.Web/sites/{name}",
+	"azurerm_windows_function_app":                         rg + "Microsoft.Web/sites/{name}",
+	"azurerm_logic_app_standard":                           rg + "Microsoft.Web/sites/{name}",
+	"azurerm_linux_web_app_slot":                           "{app_service_id}/slots/{name}",
+	"azurerm_windows_web_app_slot":                         "{app_service_id}/slots/{name}",
+	"azurerm_linux_function_app_slot":                      "{function_app_id}/slots/{name}",
+	"azurerm_windows_function_app_slot":                    "{function_app_id}/slots/{name}",
+	"azurerm_app_service_custom_hostname_binding":          rg + "Microsoft.Web/sites/{app_service_name}/hostNameBindings/{hostname}",
+	"azurerm_app_service_virtual_network_swift_connection": "{app_service_id}/config/virtualNetwork",
+	"azurerm_app_service_certificate":                      rg + "Microsoft.Web/certificates/{name}",
+	"azurerm_static_site":                                  rg + "Microsoft.Web/staticSites/{name}",
+	"azurerm_static_web_app":                               rg + "Microsoft.Web/staticSites/{name}",
+	"azurerm_signalr_service":                              rg + "Microsoft.SignalRService/signalR/{name}",
+	"azurerm_web_pubsub":                                   rg + "Microsoft.SignalRService/webPubSub/{name}",
+	"azurerm_spring_cloud_service":                         rg + "Microsoft.AppPlatform/spring/{name}",
+	"azurerm_app_configuration":                            rg + "Microsoft.AppConfiguration/configurationStores/{name}",
+
+	// Integration
+	"azurerm_api_management":                rg + "Microsoft.ApiManagement/service/{name}",
+	"azurerm_api_management_backend":        rg + "Microsoft.ApiManagement/service/{api_management_name}/backends/{name}",
+	"azurerm_api_management_logger":         rg + "Microsoft.ApiManagement/service/{api_management_name}/loggers/{name}",
+	"azurerm_api_management_named_value":    rg + "Microsoft.ApiManagement/service/{api_management_name}/namedValues/{name}",
+	"azurerm_api_management_product":        rg + "Microsoft.ApiManagement/service/{api_management_name}/products/{product_id}",
+	"azurerm_logic_app_workflow":            rg + "Microsoft.Logic/workflows/{name}",
+	"azurerm_logic_app_integration_account": rg + "Microsoft.Logic/integrationAccounts/{name}",
+	"azurerm_communication_service":         rg + "Microsoft.Communication/communicationServices/{name}",
+	"azurerm_email_communication_service":   rg + "Microsoft.Communication/emailServices/{name}",
+	"azurerm_bot_service_azure_bot":         rg + "Microsoft.BotService/botServices/{name}",
+	"azurerm_maps_account":                  rg + "Microsoft.Maps/accounts/{name}",
+	"azurerm_nginx_deployment":              rg + "Nginx.NginxPlus/nginxDeployments/{name}",
+
+	// Messaging
+	"azurerm_eventhub_namespace":                        rg + "Microsoft.EventHub/namespaces/{name}",
+	"azurerm_eventhub_namespace_authorization_rule":     rg + "Microsoft.EventHub/namespaces/{namespace_name}/authorizationRules/{name}",
+	"azurerm_eventhub":                                  "{namespace_id}/eventhubs/{name}",
+	"azurerm_eventhub_authorization_rule":               rg + "Microsoft.EventHub/namespaces/{namespace_name}/eventhubs/{eventhub_name}/authorizationRules/{name}",
+	"azurerm_eventhub_consumer_group":                   rg + "Microsoft.EventHub/namespaces/{namespace_name}/eventhubs/{eventhub_name}/consumerGroups/{name}",
+	"azurerm_servicebus_namespace":                      rg + "Microsoft.ServiceBus/namespaces/{name}",
+	"azurerm_servicebus_namespace_authorization_rule":   "{namespace_id}/authorizationRules/{name}",
+	"azurerm_servicebus_queue":                          "{namespace_id}/queues/{name}",
+	"azurerm_servicebus_queue_authorization_rule":       "{queue_id}/authorizationRules/{name}",
+	"azurerm_servicebus_topic":                          "{namespace_id}/topics/{name}",
+	"azurerm_servicebus_topic_authorization_rule":       "{topic_id}/authorizationRules/{name}",
+	"azurerm_servicebus_subscription":                   "{topic_id}/subscriptions/{name}",
+	"azurerm_servicebus_subscription_rule":              "{subscription_id}/rules/{name}",
+	"azurerm_eventgrid_domain":                          rg + "Microsoft.EventGrid/domains/{name}",
+	"azurerm_eventgrid_topic":                           rg + "Microsoft.EventGrid/topics/{name}",
+	"azurerm_eventgrid_system_topic":                    rg + "Microsoft.EventGrid/systemTopics/{name}",
+	"azurerm_eventgrid_system_topic_event_subscription": rg + "Microsoft.EventGrid/systemTopics/{system_topic}/eventSubscriptions/{name}",
+	"azurerm_eventgrid_event_subscription":              "{scope}/providers/Microsoft.EventGrid/eventSubscriptions/{name}",
+	"azurerm_relay_namespace":                           rg + "Microsoft.Relay/namespaces/{name}",
+	"azurerm_relay_hybrid_connection":                   rg + "Microsoft.Relay/namespaces/{relay_namespace_name}/hybridConnections/{name}",
+	"azurerm_notification_hub_namespace":                rg + "Microsoft.NotificationHubs/namespaces/{name}",
+	"azurerm_notification_hub":                          rg + "Microsoft.NotificationHubs/namespaces/{namespace_name}/notificationHubs/{name}",
+	"azurerm_iothub":                                    rg + "Microsoft.Devices/iotHubs/{name}",
+	"azurerm_iothub_consumer_group":                     rg + "Microsoft.Devices/iotHubs/{iothub_name}/eventHubEndpoints/{eventhub_endpoint_name}/ConsumerGroups/{name}",
+	"azurerm_iothub_dps":                                rg + "Microsoft.Devices/provisioningServices/{name}",
+
+	// Databases
+	"azurerm_mssql_server":                             rg + "Microsoft.Sql/servers/{name}",
+	"azurerm_mssql_database":                           "{server_id}/databases/{name}",
+	"azurerm_mssql_elasticpool":                        rg + "Microsoft.Sql/servers/{server_name}/elasticPools/{name}",
+	"azurerm_mssql_firewall_rule":                      "{server_id}/firewallRules/{name}",
+	"azurerm_mssql_virtual_network_rule":               "{server_id}/virtualNetworkRules/{name}",
+	"azurerm_mssql_failover_group":                     "{server_id}/failoverGroups/{name}",
+	"azurerm_mssql_managed_instance":                   rg + "Microsoft.Sql/managedInstances/{name}",
+	"azurerm_mssql_managed_database":                   "{managed_instance_id}/databases/{name}",
+	"azurerm_sql_server":                               rg + "Microsoft.Sql/servers/{name}",
+	"azurerm_sql_database":                             rg + "Microsoft.Sql/servers/{server_name}/databases/{name}",
+	"azurerm_sql_firewall_rule":                        rg + "Microsoft.Sql/servers/{server_name}/firewallRules/{name}",
+	"azurerm_postgresql_server":                        rg + "Microsoft.DBforPostgreSQL/servers/{name}",
+	"azurerm_postgresql_database":                      rg + "Microsoft.DBforPostgreSQL/servers/{server_name}/databases/{name}",
+	"azurerm_postgresql_firewall_rule":                 rg + "Microsoft.DBforPostgreSQL/servers/{server_name}/firewallRules/{name}",
+	"azurerm_postgresql_flexible_server":               rg + "Microsoft.DBforPostgreSQL/flexibleServers/{name}",
+	"azurerm_postgresql_flexible_server_database":      "{server_id}/databases/{name}",
+	"azurerm_postgresql_flexible_server_firewall_rule": "{server_id}/firewallRules/{name}",
+	"azurerm_postgresql_flexible_server_configuration": "{server_id}/configurations/{name}",
+	"azurerm_cosmosdb_postgresql_cluster":              rg + "Microsoft.DBforPostgreSQL/serverGroupsv2/{name}",
+	"azurerm_mysql_server":                             rg + "Microsoft.DBforMySQL/servers/{name}",
+	"azurerm_mysql_database":                           rg + "Microsoft.DBforMySQL/servers/{server_name}/databases/{name}",
+	"azurerm_mysql_flexible_server":                    rg + "Microsoft.DBforMySQL/flexibleServers/{name}",
+	"azurerm_mysql_flexible_database":                  rg + "Microsoft.DBforMySQL/flexibleServers/{server_name}/databases/{name}",
+	"azurerm_mysql_flexible_server_configuration":      rg + "Microsoft.DBforMySQL/flexibleServers/{server_name}/configurations/{name}",
+	"azurerm_mysql_flexible_server_firewall_rule":      rg + "Microsoft.DBforMySQL/flexibleServers/{server_name}/firewallRules/{name}",
+	"azurerm_mariadb_server":                           rg + "Microsoft.DBforMariaDB/servers/{name}",
+	"azurerm_mariadb_database":                         rg + "Microsoft.DBforMariaDB/servers/{server_name}/databases/{name}",
+	"azurerm_cosmosdb_account":                         rg + "Microsoft.DocumentDB/databaseAccounts/{name}",
+	"azurerm_cosmosdb_sql_database":                    rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/sqlDatabases/{name}",
+	"azurerm_cosmosdb_sql_container":                   rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/sqlDatabases/{database_name}/containers/{name}",
+	"azurerm_cosmosdb_mongo_database":                  rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/mongodbDatabases/{name}",
+	"azurerm_cosmosdb_mongo_collection":                rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/mongodbDatabases/{database_name}/collections/{name}",
+	"azurerm_cosmosdb_cassandra_keyspace":              rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/cassandraKeyspaces/{name}",
+	"azurerm_cosmosdb_gremlin_database":                rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/gremlinDatabases/{name}",
+	"azurerm_cosmosdb_table":                           rg + "Microsoft.DocumentDB/databaseAccounts/{account_name}/tables/{name}",
+	"azurerm_redis_cache":                              rg + "Microsoft.Cache/redis/{name}",
+	"azurerm_redis_firewall_rule":                      rg + "Microsoft.Cache/redis/{redis_cache_name}/firewallRules/{name}",
+	"azurerm_redis_enterprise_cluster":                 rg + "Microsoft.Cache/redisEnterprise/{name}",
+	"azurerm_redis_enterprise_database":                "{cluster_id}/databases/{name}",
+
+	// Analytics and AI
+	"azurerm_data_factory":                                   rg + "Microsoft.DataFactory/factories/{name}",
+	"azurerm_data_factory_pipeline":                          "{data_factory_id}/pipelines/{name}",
+	"azurerm_data_factory_linked_service_azure_blob_storage": "{data_factory_id}/linkedservices/{name}",
+	"azurerm_data_factory_integration_runtime_azure":         "{data_factory_id}/integrationruntimes/{name}",
+	"azurerm_data_factory_trigger_schedule":                  "{data_factory_id}/triggers/{name}",
+	"azurerm_synapse_workspace":                              rg + "Microsoft.Synapse/workspaces/{name}",
+	"azurerm_synapse_sql_pool":                               "{synapse_workspace_id}/sqlPools/{name}",
+	"azurerm_synapse_spark_pool":                             "{synapse_workspace_id}/bigDataPools/{name}",
+	"azurerm_synapse_firewall_rule":                          "{synapse_workspace_id}/firewallRules/{name}",
+	"azurerm_databricks_workspace":                           rg + "Microsoft.Databricks/workspaces/{name}",
+	"azurerm_databricks_access_connector":                    rg + "Microsoft.Databricks/accessConnectors/{name}",
+	"azurerm_kusto_cluster":                                  rg + "Microsoft.Kusto/clusters/{name}",
+	"azurerm_kusto_database":                                 rg + "Microsoft.Kusto/clusters/{cluster_name}/databases/{name}",
+	"azurerm_stream_analytics_job":                           rg + "Microsoft.StreamAnalytics/streamingJobs/{name}",
+	"azurerm_purview_account":                                rg + "Microsoft.Purview/accounts/{name}",
+	"azurerm_data_share_account":                             rg + "Microsoft.DataShare/accounts/{name}",
+	"azurerm_search_service":                                 rg + "Microsoft.Search/searchServices/{name}",
+	"azurerm_machine_learning_workspace":                     rg + "Microsoft.MachineLearningServices/workspaces/{name}",
+	"azurerm_machine_learning_compute_cluster":               "{machine_learning_workspace_id}/computes/{name}",
+	"azurerm_cognitive_account":                              rg + "Microsoft.CognitiveServices/accounts/{name}",
+	"azurerm_cognitive_deployment":                           "{cognitive_account_id}/deployments/{name}",
+	"azurerm_healthcare_workspace":                           rg + "Microsoft.HealthcareApis/workspaces/{name}",
+	"azurerm_load_test":                                      rg + "Microsoft.LoadTestService/loadTests/{name}",
+
+	// Monitoring
+	"azurerm_log_analytics_workspace":                  rg + "Microsoft.OperationalInsights/workspaces/{name}",
+	"azurerm_log_analytics_saved_search":               "{log_analytics_workspace_id}/savedSearches/{name}",
+	"azurerm_application_insights":                     rg + "Microsoft.Insights/components/{name}",
+	"azurerm_application_insights_web_test":            rg + "Microsoft.Insights/webTests/{name}",
+	"azurerm_application_insights_standard_web_test":   rg + "Microsoft.Insights/webTests/{name}",
+	"azurerm_application_insights_workbook":            rg + "Microsoft.Insights/workbooks/{name}",
+	"azurerm_monitor_action_group":                     rg + "Microsoft.Insights/actionGroups/{name}",
+	"azurerm_monitor_activity_log_alert":               rg + "Microsoft.Insights/activityLogAlerts/{name}",
+	"azurerm_monitor_metric_alert":                     rg + "Microsoft.Insights/metricAlerts/{name}",
+	"azurerm_monitor_scheduled_query_rules_alert":      rg + "Microsoft.Insights/scheduledQueryRules/{name}",
+	"azurerm_monitor_scheduled_query_rules_alert_v2":   rg + "Microsoft.Insights/scheduledQueryRules/{name}",
+	"azurerm_monitor_autoscale_setting":                rg + "Microsoft.Insights/autoScaleSettings/{name}",
+	"azurerm_monitor_data_collection_endpoint":         rg + "Microsoft.Insights/dataCollectionEndpoints/{name}",
+	"azurerm_monitor_data_collection_rule":             rg + "Microsoft.Insights/dataCollectionRules/{name}",
+	"azurerm_monitor_data_collection_rule_association": "{target_resource_id}/providers/Microsoft.Insights/dataCollectionRuleAssociations/{name}",
+	"azurerm_monitor_private_link_scope":               rg + "Microsoft.Insights/privateLinkScopes/{name}",
+	"azurerm_monitor_workspace":                        rg + "Microsoft.Monitor/accounts/{name}",
+	"azurerm_dashboard_grafana":                        rg + "Microsoft.Dashboard/grafana/{name}",
+
+	// Automation, backup and recovery
+	"azurerm_automation_account":                         rg + "Microsoft.Automation/automationAccounts/{name}",
+	"azurerm_automation_credential":                      rg + "Microsoft.Automation/automationAccounts/{automation_account_name}/credentials/{name}",
+	"azurerm_automation_module":                          rg + "Microsoft.Automation/automationAccounts/{automation_account_name}/modules/{name}",
+	"azurerm_automation_runbook":                         rg + "Microsoft.Automation/automationAccounts/{automation_account_name}/runbooks/{name}",
+	"azurerm_automation_schedule":                        rg + "Microsoft.Automation/automationAccounts/{automation_account_name}/schedules/{name}",
+	"azurerm_automation_variable_string":                 rg + "Microsoft.Automation/automationAccounts/{automation_account_name}/variables/{name}",
+	"azurerm_recovery_services_vault":                    rg + "Microsoft.RecoveryServices/vaults/{name}",
+	"azurerm_backup_policy_vm":                           rg + "Microsoft.RecoveryServices/vaults/{recovery_vault_name}/backupPolicies/{name}",
+	"azurerm_backup_policy_file_share":                   rg + "Microsoft.RecoveryServices/vaults/{recovery_vault_name}/backupPolicies/{name}",
+	"azurerm_site_recovery_fabric":                       rg + "Microsoft.RecoveryServices/vaults/{recovery_vault_name}/replicationFabrics/{name}",
+	"azurerm_data_protection_backup_vault":               rg + "Microsoft.DataProtection/backupVaults/{name}",
+	"azurerm_data_protection_backup_policy_blob_storage": "{vault_id}/backupPolicies/{name}",
+	"azurerm_data_protection_backup_policy_disk":         "{vault_id}/backupPolicies/{name}",
+}
+
+// catalog holds the parsed templates, keyed by resource type.
+var catalog = func() map[string]*Template {
+	parsed := make(map[string]*Template, len(templates))
+	for resourceType, template := range templates {
+		parsed[resourceType] = MustParseTemplate(template)
+	}
+	return parsed
+}()
+
+// Lookup returns the resource ID template of a resource type.
+func Lookup(resourceType string) (*Template, bool) {
+	t, ok := catalog[resourceType]
+	return t, ok
+}
+
+// ResourceTypes returns the resource types in the catalog, sorted.
+func ResourceTypes() []string {
+	types := make([]string, 0, len(catalog))
+	for resourceType := range catalog {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,88 @@
+package resourceid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+func TestCatalog_Coverage(t *testing.T) {
+	if got := len(ResourceTypes()); got < 200 {
+		t.Errorf("Expected at least 200 resource types, got %d", got)
+	}
+}
+
+func TestCatalog_RoundTrip(t *testing.T) {
+	for _, resourceType := range ResourceTypes() {
+		tmpl, _ := Lookup(resourceType)
+
+		if !strings.HasPrefix(resourceType, "azurerm_") {
+			t.Errorf("%s: expected an azurerm resource type", resourceType)
+		}
+		if len(tmpl.Attributes()) == 0 {
+			t.Errorf("%s: expected at least one configuration attribute", resourceType)
+		}
+
+		// Fill every placeholder, using a resource ID for scopes
+		values := make(map[string]string)
+		for _, segment := range tmpl.Segments {
+			switch {
+			case segment.Scope:
+				values[segment.Name] = "/subscriptions/000/resourceGroups/rg/providers/Microsoft.Example/parents/parent"
+			case segment.IsPlaceholder():
+				values[segment.Name] = "value-" + segment.Name
+			}
+		}
+
+		id := tmpl.Format(values)
+		if strings.ContainsAny(id, "{}") {
+			t.Errorf("%s: Format left placeholders in %q", resourceType, id)
+			continue
+		}
+		parsed, err := tmpl.Parse(id)
+		if err != nil {
+			t.Errorf("%s: Parse(%q) returned error: %v", resourceType, id, err)
+			continue
+		}
+		for name, want := range values {
+			if parsed[name] != want {
+				t.Errorf("%s: Parse(%q)[%s] = %q, want %q", resourceType, id, name, parsed[name], want)
+			}
+		}
+	}
+}
+
+func TestCatalog_SchemaAttributes(t *testing.T) {
+	s := schema.Load()
+	for _, resourceType := range ResourceTypes() {
+		if !s.HasResource(resourceType) {
+			continue
+		}
+		paths := make(map[string]bool)
+		for _, path := range s.GetAttributePaths(resourceType) {
+			paths[path] = true
+		}
+		tmpl, _ := Lookup(resourceType)
+		for _, attr := range tmpl.Attributes() {
+			if !paths[attr] {
+				t.Errorf("%s: template attribute %q is not in the schema", resourceType, attr)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tmpl, ok := Lookup("azurerm_storage_account")
+	if !ok {
+		t.Fatal("Expected azurerm_storage_account in the catalog")
+	}
+	want := "/subscriptions/{subscription_id}/resourceGroups/{resource_group_name}/providers/Microsoft.Storage/storageAccounts/{name}"
+	if tmpl.String() != want {
+		t.Errorf("String() = %q, want %q", tmpl.String(), want)
+	}
+
+	if _, ok := Lookup("azurerm_key_vault_secret"); ok {
+		t.Error("Expected azurerm_key_vault_secret, whose ID is a URL, not to be in the catalog")
+	}
+}
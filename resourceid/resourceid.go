@@ -0,0 +1,176 @@
+// Package resourceid provides the Azure resource ID shapes of azurerm resource types.
+package resourceid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SubscriptionID is the name of the placeholder for the subscription of a resource ID.
+// The subscription is not part of a resource's configuration.
+const SubscriptionID = "subscription_id"
+
+// Segment is a single path segment of a resource ID template.
+type Segment struct {
+	// Literal is the fixed text of the segment, e.g. "resourceGroups".
+	// Empty for placeholder segments.
+	Literal string
+	// Name is the placeholder name, e.g. "resource_group_name".
+	Name string
+	// Attribute is the configuration attribute that fills the placeholder,
+	// or empty if the value is not part of the configuration.
+	Attribute string
+	// Scope is true for a leading placeholder that holds a complete resource ID,
+	// e.g. "{server_id}" in "{server_id}/databases/{name}".
+	Scope bool
+}
+
+// IsPlaceholder reports whether the segment is filled from a value.
+func (s Segment) IsPlaceholder() bool {
+	return s.Name != ""
+}
+
+// Template is the shape of the resource IDs of a resource type, e.g.
+// "/subscriptions/{subscription_id}/resourceGroups/{resource_group_name}/providers/Microsoft.Storage/storageAccounts/{name}".
+type Template struct {
+	Segments []Segment
+}
+
+// placeholderPattern matches a placeholder segment.
+var placeholderPattern = regexp.MustCompile(`^\{([a-z][a-z0-9_]*)\}$`)
+
+// ParseTemplate parses a resource ID template.
+// Placeholders are written as {attribute}, naming the configuration attribute that fills them.
+// A placeholder following the "subscriptions" literal is the subscription, which is not
+// part of the configuration. A leading placeholder holds a complete resource ID.
+func ParseTemplate(s string) (*Template, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty template")
+	}
+
+	rooted := strings.HasPrefix(s, "/")
+	parts := strings.Split(strings.TrimPrefix(s, "/"), "/")
+	t := &Template{Segments: make([]Segment, 0, len(parts))}
+	for i, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("template %q has an empty segment", s)
+		}
+		if !strings.ContainsAny(part, "{}") {
+			t.Segments = append(t.Segments, Segment{Literal: part})
+			continue
+		}
+
+		match := placeholderPattern.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("template %q has a malformed placeholder %q", s, part)
+		}
+		segment := Segment{Name: match[1], Attribute: match[1]}
+		switch {
+		case i == 0 && !rooted:
+			segment.Scope = true
+		case i == 0:
+			return nil, fmt.Errorf("template %q must not start with /{%s}", s, match[1])
+		case !t.Segments[i-1].IsPlaceholder() && strings.EqualFold(t.Segments[i-1].Literal, "subscriptions"):
+			segment.Attribute = ""
+		case t.Segments[i-1].IsPlaceholder():
+			return nil, fmt.Errorf("template %q has consecutive placeholders", s)
+		}
+		t.Segments = append(t.Segments, segment)
+	}
+
+	if !rooted && !t.Segments[0].Scope {
+		return nil, fmt.Errorf("template %q must start with / or a scope placeholder", s)
+	}
+	return t, nil
+}
+
+// MustParseTemplate is like ParseTemplate but panics if the template is malformed.
+func MustParseTemplate(s string) *Template {
+	t, err := ParseTemplate(s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template in the form accepted by ParseTemplate.
+func (t *Template) String() string {
+	return t.Format(nil)
+}
+
+// Attributes returns the configuration attributes that fill the template, in order.
+func (t *Template) Attributes() []string {
+	var attrs []string
+	for _, segment := range t.Segments {
+		if segment.Attribute != "" {
+			attrs = append(attrs, segment.Attribute)
+		}
+	}
+	return attrs
+}
+
+// Format builds a resource ID from placeholder values keyed by placeholder name.
+// Placeholders without a value are kept as {name}.
+func (t *Template) Format(values map[string]string) string {
+	var sb strings.Builder
+	for i, segment := range t.Segments {
+		if i > 0 || !segment.Scope {
+			sb.WriteString("/")
+		}
+		switch {
+		case !segment.IsPlaceholder():
+			sb.WriteString(segment.Literal)
+		case values[segment.Name] != "":
+			value := values[segment.Name]
+			if segment.Scope {
+				value = strings.TrimSuffix(value, "/")
+			}
+			sb.WriteString(value)
+		default:
+			sb.WriteString("{" + segment.Name + "}")
+		}
+	}
+	return sb.String()
+}
+
+// Parse matches a resource ID against the template and returns the placeholder values
+// keyed by placeholder name. Literal segments are compared case-insensitively,
+// as Azure resource IDs are.
+func (t *Template) Parse(id string) (map[string]string, error) {
+	if !strings.HasPrefix(id, "/") {
+		return nil, fmt.Errorf("resource ID %q must start with /", id)
+	}
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+
+	segments := t.Segments
+	values := make(map[string]string)
+	if len(segments) > 0 && segments[0].Scope {
+		// The scope is the complete resource ID preceding the remaining segments.
+		scopeLen := len(parts) - (len(segments) - 1)
+		if scopeLen < 1 {
+			return nil, fmt.Errorf("resource ID %q does not match %s", id, t)
+		}
+		values[segments[0].Name] = "/" + strings.Join(parts[:scopeLen], "/")
+		parts = parts[scopeLen:]
+		segments = segments[1:]
+	}
+
+	if len(parts) != len(segments) {
+		return nil, fmt.Errorf("resource ID %q does not match %s", id, t)
+	}
+	for i, segment := range segments {
+		part := parts[i]
+		if part == "" {
+			return nil, fmt.Errorf("resource ID %q has an empty segment", id)
+		}
+		if !segment.IsPlaceholder() {
+			if !strings.EqualFold(part, segment.Literal) {
+				return nil, fmt.Errorf("resource ID %q does not match %s: expected %q, got %q", id, t, segment.Literal, part)
+			}
+			continue
+		}
+		values[segment.Name] = part
+	}
+	return values, nil
+}
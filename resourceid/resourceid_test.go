@@ -0,0 +1,145 @@
+package resourceid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("/subscriptions/{subscription_id}/resourceGroups/{resource_group_name}/providers/Microsoft.Storage/storageAccounts/{name}")
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+
+	// The subscription is not part of the configuration
+	if got, want := tmpl.Attributes(), []string{"resource_group_name", "name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Attributes() = %v, want %v", got, want)
+	}
+	if tmpl.Segments[1].Name != SubscriptionID || tmpl.Segments[1].Attribute != "" {
+		t.Errorf("Expected the subscription placeholder without an attribute, got %+v", tmpl.Segments[1])
+	}
+}
+
+func TestParseTemplate_Scope(t *testing.T) {
+	tmpl, err := ParseTemplate("{server_id}/databases/{name}")
+	if err != nil {
+		t.Fatalf("ParseTemplate returned error: %v", err)
+	}
+	if !tmpl.Segments[0].Scope {
+		t.Error("Expected the leading placeholder to be a scope")
+	}
+	if got, want := tmpl.Attributes(), []string{"server_id", "name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Attributes() = %v, want %v", got, want)
+	}
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	for _, template := range []string{
+		"",
+		"subscriptions/{subscription_id}",
+		"/{name}",
+		"/subscriptions//resourceGroups/{name}",
+		"/subscriptions/{subscription_id}/resourceGroups/rg-{name}",
+		"/subscriptions/{subscription_id}/{resource_group_name}",
+		"/subscriptions/{Subscription}",
+	} {
+		if _, err := ParseTemplate(template); err == nil {
+			t.Errorf("ParseTemplate(%q) expected error", template)
+		}
+	}
+}
+
+func TestTemplate_Format(t *testing.T) {
+	tests := []struct {
+		template string
+		values   map[string]string
+		want     string
+	}{
+		{
+			"/subscriptions/{subscription_id}/resourceGroups/{name}",
+			map[string]string{"name": "my-rg"},
+			"/subscriptions/{subscription_id}/resourceGroups/my-rg",
+		},
+		{
+			"{server_id}/databases/{name}",
+			map[string]string{"server_id": "/subscriptions/000/resourceGroups/rg/providers/Microsoft.Sql/servers/sql/", "name": "db"},
+			"/subscriptions/000/resourceGroups/rg/providers/Microsoft.Sql/servers/sql/databases/db",
+		},
+		{
+			"{server_id}/databases/{name}",
+			nil,
+			"{server_id}/databases/{name}",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := MustParseTemplate(tt.template).Format(tt.values); got != tt.want {
+			t.Errorf("Format(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestTemplate_String(t *testing.T) {
+	for _, template := range []string{
+		"/subscriptions/{subscription_id}/resourceGroups/{name}",
+		"{storage_account_id}",
+		"/providers/Microsoft.Management/managementGroups/{name}",
+	} {
+		if got := MustParseTemplate(template).String(); got != template {
+			t.Errorf("String() = %q, want %q", got, template)
+		}
+	}
+}
+
+func TestTemplate_Parse(t *testing.T) {
+	tests := []struct {
+		template string
+		id       string
+		want     map[string]string
+	}{
+		{
+			"/subscriptions/{subscription_id}/resourceGroups/{resource_group_name}/providers/Microsoft.Storage/storageAccounts/{name}",
+			"/subscriptions/000/resourcegroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+			map[string]string{"subscription_id": "000", "resource_group_name": "rg", "name": "sa"},
+		},
+		{
+			"{server_id}/databases/{name}",
+			"/subscriptions/000/resourceGroups/rg/providers/Microsoft.Sql/servers/sql/databases/db",
+			map[string]string{"server_id": "/subscriptions/000/resourceGroups/rg/providers/Microsoft.Sql/servers/sql", "name": "db"},
+		},
+		{
+			"{subnet_id}",
+			"/subscriptions/000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/app/",
+			map[string]string{"subnet_id": "/subscriptions/000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/app"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := MustParseTemplate(tt.template).Parse(tt.id)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.id, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestTemplate_ParseMismatch(t *testing.T) {
+	tmpl := MustParseTemplate("/subscriptions/{subscription_id}/resourceGroups/{resource_group_name}/providers/Microsoft.Storage/storageAccounts/{name}")
+	for _, id := range []string{
+		"subscriptions/000/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/sa",
+		"/subscriptions/000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/sa",
+		"/subscriptions/000/resourceGroups/rg",
+		"/subscriptions/000/resourceGroups//providers/Microsoft.Storage/storageAccounts/sa",
+	} {
+		if _, err := tmpl.Parse(id); err == nil {
+			t.Errorf("Parse(%q) expected error", id)
+		}
+	}
+
+	if _, err := MustParseTemplate("{server_id}/databases/{name}").Parse("/databases/db"); err == nil {
+		t.Error("Expected error for a missing scope")
+	}
+}
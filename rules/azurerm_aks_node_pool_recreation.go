@@ -0,0 +1,216 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// nodePoolCycleAttributes are the node pool attributes that cannot be updated on existing nodes.
+// Changing them either recreates the node pool, or cycles it through a temporary node pool
+// when temporary_name_for_rotation is set. Provider 3.x and 4.x attribute names are both listed.
+var nodePoolCycleAttributes = []string{
+	"enable_host_encryption",
+	"enable_node_public_ip",
+	"fips_enabled",
+	"host_encryption_enabled",
+	"kubelet_disk_type",
+	"max_pods",
+	"node_public_ip_enabled",
+	"os_disk_size_gb",
+	"os_disk_type",
+	"os_sku",
+	"pod_subnet_id",
+	"ultra_ssd_enabled",
+	"vm_size",
+	"vnet_subnet_id",
+	"zones",
+}
+
+// defaultNodePoolCycleAttributes are the cycle attributes of the default node pool.
+// Renaming the default node pool also requires cycling it.
+var defaultNodePoolCycleAttributes = append([]string{"name"}, nodePoolCycleAttributes...)
+
+// AzurermAksNodePoolRecreationRule detects AKS node pool changes that recreate the cluster or
+// node pool, and recognizes when temporary_name_for_rotation turns them into a rotation.
+type AzurermAksNodePoolRecreationRule struct {
+	tflint.DefaultRule
+	schema *schema.Schema
+}
+
+// NewAzurermAksNodePoolRecreationRule creates a new AKS node pool recreation rule.
+func NewAzurermAksNodePoolRecreationRule() *AzurermAksNodePoolRecreationRule {
+	return &AzurermAksNodePoolRecreationRule{
+		schema: schema.Load(),
+	}
+}
+
+// Name returns the rule name.
+func (r *AzurermAksNodePoolRecreationRule) Name() string {
+	return "azurerm_aks_node_pool_recreation"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermAksNodePoolRecreationRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// Recreating a cluster or node pool evicts every workload at once. Rotations through
+// a temporary node pool are reported as warnings.
+func (r *AzurermAksNodePoolRecreationRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermAksNodePoolRecreationRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks for destructive node pool changes on azurerm_kubernetes_cluster and
+// azurerm_kubernetes_cluster_node_pool resources.
+func (r *AzurermAksNodePoolRecreationRule) Check(runner tflint.Runner) error {
+	clusterPaths := make([]string, 0, len(defaultNodePoolCycleAttributes)+1)
+	for _, name := range defaultNodePoolCycleAttributes {
+		clusterPaths = append(clusterPaths, "default_node_pool."+name)
+	}
+	clusterPaths = append(clusterPaths, "default_node_pool.temporary_name_for_rotation")
+	if err := r.checkResource(runner, "azurerm_kubernetes_cluster", "default_node_pool.", defaultNodePoolCycleAttributes, clusterPaths); err != nil {
+		return err
+	}
+
+	poolPaths := append([]string{"temporary_name_for_rotation"}, nodePoolCycleAttributes...)
+	return r.checkResource(runner, "azurerm_kubernetes_cluster_node_pool", "", nodePoolCycleAttributes, poolPaths)
+}
+
+// checkResource compares the cycle attributes of a resource type. prefix is prepended
+// to each attribute name to form its path, and to locate temporary_name_for_rotation.
+func (r *AzurermAksNodePoolRecreationRule) checkResource(runner tflint.Runner, resourceType, prefix string, attrNames, paths []string) error {
+	bodySchema := buildBodySchema(paths)
+
+	oldContent, err := runner.GetOldResourceContent(resourceType, bodySchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old %s: %w", resourceType, err)
+	}
+	if len(oldContent.Blocks) == 0 {
+		return nil
+	}
+	newContent, err := runner.GetNewResourceContent(resourceType, bodySchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new %s: %w", resourceType, err)
+	}
+
+	oldByName := blocksByName(oldContent)
+	for _, newBlock := range newContent.Blocks {
+		if len(newBlock.Labels) < 2 {
+			continue
+		}
+		name := newBlock.Labels[1]
+		oldBlock, exists := oldByName[name]
+		if !exists {
+			continue
+		}
+
+		var changes []string
+		issueRange := hcl.Range{}
+		for _, attrName := range attrNames {
+			path := prefix + attrName
+			oldAttr := getAttributeByPath(oldBlock, path)
+			newAttr := getAttributeByPath(newBlock, path)
+			c := comparisonFor(r.schema.GetAttribute(resourceType, path))
+			changed, oldVal, newVal := nodePoolAttributeChanged(attrName, oldAttr, newAttr, c)
+			if !changed {
+				continue
+			}
+			changes = append(changes, fmt.Sprintf("%q (old: %s, new: %s)", path, oldVal, newVal))
+			if issueRange == (hcl.Range{}) && newAttr != nil {
+				issueRange = newAttr.Range
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		if issueRange == (hcl.Range{}) {
+			issueRange = newBlock.DefRange
+		}
+
+		rotation := ""
+		if val := evalAttr(getAttributeByPath(newBlock, prefix+"temporary_name_for_rotation")); !isUnresolvedValue(val) && val != "" {
+			rotation = val
+		}
+
+		severity, message := nodePoolFinding(resourceType, name, prefix, changes, rotation)
+		if err := runner.EmitIssue(withSeverity(r, severity), message, issueRange); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nodePoolAttributeChanged compares a node pool attribute between old and new configurations.
+// Zones are compared as sets. Other values are compared as the declared type of the comparison,
+// with the provider default in place of an unset attribute, so that setting the default
+// explicitly is not a change. Values that cannot be statically resolved are not compared.
+func nodePoolAttributeChanged(attrName string, oldAttr, newAttr *hclext.Attribute, c comparison) (bool, string, string) {
+	if oldAttr == nil && newAttr == nil {
+		return false, "", ""
+	}
+
+	if attrName == "zones" {
+		oldZones, ok := attrZones(oldAttr)
+		if !ok {
+			return false, "", ""
+		}
+		newZones, ok := attrZones(newAttr)
+		if !ok {
+			return false, "", ""
+		}
+		return !zonesEqual(oldZones, newZones), describeZones(oldZones), describeZones(newZones)
+	}
+
+	oldDefaulted, newDefaulted := withDefault(oldAttr, c.Default), withDefault(newAttr, c.Default)
+	oldVal, newVal := evalTypedAttr(oldDefaulted, c.Type), evalTypedAttr(newDefaulted, c.Type)
+	if (oldDefaulted != nil && isUnresolvedValue(oldVal)) || (newDefaulted != nil && isUnresolvedValue(newVal)) {
+		return false, "", ""
+	}
+	return oldVal != newVal, valueSource(oldVal, oldAttr, c, false), valueSource(newVal, newAttr, c, false)
+}
+
+// nodePoolFinding builds the severity and message for node pool cycle attribute changes.
+// rotation is the temporary node pool name, or empty when no rotation is configured.
+func nodePoolFinding(resourceType, name, prefix string, changes []string, rotation string) (tflint.Severity, string) {
+	address := resourceType + "." + name
+	changed := strings.Join(changes, ", ")
+
+	if rotation != "" {
+		subject := "the node pool"
+		if prefix != "" {
+			subject = "the default node pool"
+		}
+		return tflint.WARNING, fmt.Sprintf(
+			"Changing %s of %s rotates %s through temporary node pool %q: "+
+				"nodes are replaced and workloads are drained and rescheduled.",
+			changed, address, subject, rotation,
+		)
+	}
+
+	if prefix != "" {
+		return tflint.ERROR, fmt.Sprintf(
+			"Changing %s of %s forces recreation of the whole cluster. "+
+				"Set %stemporary_name_for_rotation to rotate the default node pool instead.",
+			changed, address, prefix,
+		)
+	}
+	return tflint.ERROR, fmt.Sprintf(
+		"Changing %s of %s forces recreation of the node pool, evicting all of its workloads at once. "+
+			"Set temporary_name_for_rotation to rotate the node pool, or create a new node pool and drain the old one.",
+		changed, address,
+	)
+}
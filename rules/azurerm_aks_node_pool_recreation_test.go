@@ -0,0 +1,277 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+func TestAksNodePoolRecreation_Metadata(t *testing.T) {
+	rule := NewAzurermAksNodePoolRecreationRule()
+	if rule.Name() != "azurerm_aks_node_pool_recreation" {
+		t.Errorf("Expected rule name to be 'azurerm_aks_node_pool_recreation', got '%s'", rule.Name())
+	}
+	if !rule.Enabled() {
+		t.Error("Expected rule to be enabled by default")
+	}
+	if rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected severity to be ERROR, got %v", rule.Severity())
+	}
+	if !strings.Contains(rule.Link(), "azurerm_aks_node_pool_recreation") {
+		t.Errorf("Expected link to contain rule name, got '%s'", rule.Link())
+	}
+}
+
+func TestAksNodePoolRecreation_ClusterRecreation(t *testing.T) {
+	rule := NewAzurermAksNodePoolRecreationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster" "aks" {
+    name = "aks"
+    default_node_pool {
+        name            = "system"
+        vm_size         = "Standard_D2s_v3"
+        os_disk_size_gb = 128
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster" "aks" {
+    name = "aks"
+    default_node_pool {
+        name            = "system"
+        vm_size         = "Standard_D4s_v3"
+        os_disk_size_gb = 256
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected ERROR severity, got %v", issue.Rule.Severity())
+	}
+	for _, want := range []string{
+		`"default_node_pool.vm_size" (old: Standard_D2s_v3, new: Standard_D4s_v3)`,
+		`"default_node_pool.os_disk_size_gb" (old: 128, new: 256)`,
+		"forces recreation of the whole cluster",
+	} {
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, issue.Message)
+		}
+	}
+}
+
+func TestAksNodePoolRecreation_ClusterRotation(t *testing.T) {
+	rule := NewAzurermAksNodePoolRecreationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster" "aks" {
+    name = "aks"
+    default_node_pool {
+        name    = "system"
+        vm_size = "Standard_D2s_v3"
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster" "aks" {
+    name = "aks"
+    default_node_pool {
+        name                        = "system"
+        vm_size                     = "Standard_D4s_v3"
+        temporary_name_for_rotation = "systemtmp"
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected WARNING severity, got %v", issue.Rule.Severity())
+	}
+	if !strings.Contains(issue.Message, `rotates the default node pool through temporary node pool "systemtmp"`) {
+		t.Errorf("Unexpected message: %q", issue.Message)
+	}
+}
+
+func TestAksNodePoolRecreation_NodePool(t *testing.T) {
+	tests := []struct {
+		name         string
+		rotation     string
+		wantSeverity tflint.Severity
+		wantText     string
+	}{
+		{"recreation", "", tflint.ERROR, "forces recreation of the node pool"},
+		{"rotation", `temporary_name_for_rotation = "usertmp"`, tflint.WARNING, "rotates the node pool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermAksNodePoolRecreationRule()
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name           = "user"
+    vnet_subnet_id = "/subscriptions/000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/a"
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name           = "user"
+    vnet_subnet_id = "/subscriptions/000/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet/subnets/b"
+    ` + tt.rotation + `
+}`,
+				},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			if runner.Issues[0].Rule.Severity() != tt.wantSeverity {
+				t.Errorf("Expected %v severity, got %v", tt.wantSeverity, runner.Issues[0].Rule.Severity())
+			}
+			if !strings.Contains(runner.Issues[0].Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestAksNodePoolRecreation_ProviderDefault(t *testing.T) {
+	tests := []struct {
+		name       string
+		newAttr    string
+		wantIssues int
+		wantText   string
+	}{
+		{"default set explicitly", `ultra_ssd_enabled = false`, 0, ""},
+		{"changed from default", `ultra_ssd_enabled = true`, 1, `"ultra_ssd_enabled" (old: false (default), new: true)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermAksNodePoolRecreationRule()
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name    = "user"
+    vm_size = "Standard_D2s_v3"
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name    = "user"
+    vm_size = "Standard_D2s_v3"
+    ` + tt.newAttr + `
+}`,
+				},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != tt.wantIssues {
+				t.Fatalf("Expected %d issues, got %d: %v", tt.wantIssues, len(runner.Issues), runner.Issues)
+			}
+			if tt.wantIssues > 0 && !strings.Contains(runner.Issues[0].Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestAksNodePoolRecreation_ZonesReordered(t *testing.T) {
+	rule := NewAzurermAksNodePoolRecreationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster" "aks" {
+    name = "aks"
+    default_node_pool {
+        name  = "system"
+        zones = ["1", "2"]
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster" "aks" {
+    name = "aks"
+    default_node_pool {
+        name  = "system"
+        zones = ["2", "1"]
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestAksNodePoolRecreation_NonCycleAttribute(t *testing.T) {
+	rule := NewAzurermAksNodePoolRecreationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name       = "user"
+    vm_size    = "Standard_D4s_v3"
+    node_count = 3
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name       = "user"
+    vm_size    = "Standard_D4s_v3"
+    node_count = 5
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Scaling a node pool is applied in place
+	helper.AssertNoIssues(t, runner.Issues)
+}
@@ -0,0 +1,283 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// zoneChangeBehavior describes how the provider and Azure handle a change to zone pinning.
+type zoneChangeBehavior int
+
+const (
+	// zoneChangeRecreates means the zone attribute is ForceNew and the resource is recreated.
+	zoneChangeRecreates zoneChangeBehavior = iota
+	// zoneChangeRejected means the attribute is updated in place but Azure rejects the change.
+	zoneChangeRejected
+	// zoneChangeAddOnly means zones can be added in place, but removing a zone is rejected.
+	zoneChangeAddOnly
+)
+
+// zoneAttribute describes an attribute that pins a resource to availability zones.
+type zoneAttribute struct {
+	// Path is the attribute path, e.g. "zone", "zones" or "frontend_ip_configuration.zones".
+	Path string
+	// Behavior describes what happens when the zones change.
+	Behavior zoneChangeBehavior
+}
+
+// zonalResources maps resource types to their zone attributes.
+// The attributes come in three shapes: `zone` (a single zone string), `zones` (a list or
+// set of zone strings) and the legacy `availability_zone` ("1", "Zone-Redundant", "No-Zone").
+// AKS node pools are left out, since azurerm_aks_node_pool_recreation reports their zones.
+var zonalResources = map[string][]zoneAttribute{
+	"azurerm_application_gateway":                    {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_firewall":                               {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_lb":                                     {{Path: "frontend_ip_configuration.zones", Behavior: zoneChangeRecreates}},
+	"azurerm_linux_virtual_machine":                  {{Path: "zone", Behavior: zoneChangeRecreates}},
+	"azurerm_linux_virtual_machine_scale_set":        {{Path: "zones", Behavior: zoneChangeAddOnly}},
+	"azurerm_managed_disk":                           {{Path: "zone", Behavior: zoneChangeRecreates}},
+	"azurerm_mysql_flexible_server":                  {{Path: "zone", Behavior: zoneChangeRejected}},
+	"azurerm_nat_gateway":                            {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_orchestrated_virtual_machine_scale_set": {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_postgresql_flexible_server":             {{Path: "zone", Behavior: zoneChangeRejected}},
+	"azurerm_public_ip": {
+		{Path: "zones", Behavior: zoneChangeRecreates},
+		{Path: "availability_zone", Behavior: zoneChangeRecreates},
+	},
+	"azurerm_public_ip_prefix":                  {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_redis_cache":                       {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_virtual_machine":                   {{Path: "zones", Behavior: zoneChangeRecreates}},
+	"azurerm_windows_virtual_machine":           {{Path: "zone", Behavior: zoneChangeRecreates}},
+	"azurerm_windows_virtual_machine_scale_set": {{Path: "zones", Behavior: zoneChangeAddOnly}},
+}
+
+// azureZones are the zones used when a legacy "Zone-Redundant" value is normalized.
+var azureZones = []string{"1", "2", "3"}
+
+// AzurermAvailabilityZoneChangeRule detects changes to availability zone pinning.
+// It compares zones as sets, so reordering is not a change, and explains whether a change
+// recreates the resource, is rejected by Azure, or is applied in place.
+type AzurermAvailabilityZoneChangeRule struct {
+	tflint.DefaultRule
+}
+
+// NewAzurermAvailabilityZoneChangeRule creates a new availability zone change rule.
+func NewAzurermAvailabilityZoneChangeRule() *AzurermAvailabilityZoneChangeRule {
+	return &AzurermAvailabilityZoneChangeRule{}
+}
+
+// Name returns the rule name.
+func (r *AzurermAvailabilityZoneChangeRule) Name() string {
+	return "azurerm_availability_zone_change"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermAvailabilityZoneChangeRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// Zone changes either recreate the resource or fail at apply. Changes that are
+// applied in place are reported as notices.
+func (r *AzurermAvailabilityZoneChangeRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermAvailabilityZoneChangeRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks for availability zone changes between old and new configurations.
+func (r *AzurermAvailabilityZoneChangeRule) Check(runner tflint.Runner) error {
+	resourceTypes := make([]string, 0, len(zonalResources))
+	for resourceType := range zonalResources {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	for _, resourceType := range resourceTypes {
+		attrs := zonalResources[resourceType]
+		paths := make([]string, len(attrs))
+		for i, attr := range attrs {
+			paths[i] = attr.Path
+		}
+		bodySchema := buildBodySchema(paths)
+
+		oldContent, err := runner.GetOldResourceContent(resourceType, bodySchema, nil)
+		if err != nil {
+			return fmt.Errorf("get old %s: %w", resourceType, err)
+		}
+		if len(oldContent.Blocks) == 0 {
+			continue
+		}
+		newContent, err := runner.GetNewResourceContent(resourceType, bodySchema, nil)
+		if err != nil {
+			return fmt.Errorf("get new %s: %w", resourceType, err)
+		}
+
+		oldByName := blocksByName(oldContent)
+		for _, newBlock := range newContent.Blocks {
+			if len(newBlock.Labels) < 2 {
+				continue
+			}
+			name := newBlock.Labels[1]
+			oldBlock, exists := oldByName[name]
+			if !exists {
+				continue
+			}
+
+			for _, attr := range attrs {
+				oldAttr := getAttributeByPath(oldBlock, attr.Path)
+				newAttr := getAttributeByPath(newBlock, attr.Path)
+				oldZones, ok := attrZones(oldAttr)
+				if !ok {
+					continue
+				}
+				newZones, ok := attrZones(newAttr)
+				if !ok {
+					continue
+				}
+				if zonesEqual(oldZones, newZones) {
+					continue
+				}
+
+				severity, outcome := zoneChangeOutcome(attr.Behavior, oldZones, newZones)
+				message := fmt.Sprintf(
+					"Changing %q of %s.%s moves it from %s to %s. %s",
+					attr.Path, resourceType, name,
+					describeZones(oldZones), describeZones(newZones), outcome,
+				)
+				issueRange := newBlock.DefRange
+				if newAttr != nil && newAttr.Range != (hcl.Range{}) {
+					issueRange = newAttr.Range
+				}
+				if err := runner.EmitIssue(withSeverity(r, severity), message, issueRange); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// zoneChangeOutcome returns the severity and explanation for a zone change.
+func zoneChangeOutcome(behavior zoneChangeBehavior, oldZones, newZones []string) (tflint.Severity, string) {
+	switch behavior {
+	case zoneChangeRejected:
+		return tflint.ERROR, "The zone is updated in place, but Azure rejects moving an existing resource to another zone; the apply will fail."
+	case zoneChangeAddOnly:
+		if removed := zonesDifference(oldZones, newZones); len(removed) > 0 {
+			return tflint.ERROR, fmt.Sprintf(
+				"Zones can only be added in place; removing zone %s is rejected by Azure.",
+				strings.Join(removed, ", "),
+			)
+		}
+		return tflint.NOTICE, "Adding zones is applied in place; existing instances stay in their current zones."
+	default:
+		return tflint.ERROR, "Zone pinning cannot be changed in place; this forces recreation of the resource."
+	}
+}
+
+// attrZones returns the zones set by an attribute, normalized to a sorted list.
+// An unset attribute means no zones. Returns false if the value is not statically known.
+//
+// Supported shapes:
+//   - zone = "1"
+//   - zones = ["1", "2"]
+//   - availability_zone = "1" | "Zone-Redundant" | "No-Zone"
+func attrZones(attr *hclext.Attribute) ([]string, bool) {
+	if attr == nil {
+		return nil, true
+	}
+	val, ok := attrValue(attr)
+	if !ok || !val.IsWhollyKnown() {
+		return nil, false
+	}
+	if val.IsNull() {
+		return nil, true
+	}
+
+	var zones []string
+	switch {
+	case val.Type() == cty.String:
+		switch zone := val.AsString(); strings.ToLower(zone) {
+		case "", "no-zone":
+		case "zone-redundant":
+			zones = append(zones, azureZones...)
+		default:
+			zones = append(zones, zone)
+		}
+	case val.Type() == cty.Number:
+		zones = append(zones, formatCtyValue(val))
+	case val.Type().IsListType() || val.Type().IsSetType() || val.Type().IsTupleType():
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			if elem.IsNull() {
+				continue
+			}
+			zones = append(zones, formatCtyValue(elem))
+		}
+	default:
+		return nil, false
+	}
+
+	// Repeated zones are one zone
+	sort.Strings(zones)
+	unique := zones[:0]
+	for i, zone := range zones {
+		if i == 0 || zone != zones[i-1] {
+			unique = append(unique, zone)
+		}
+	}
+	return unique, true
+}
+
+// zonesEqual reports whether two sorted zone lists contain the same zones.
+func zonesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// zonesDifference returns the zones in a that are not in b.
+func zonesDifference(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, z := range b {
+		inB[z] = true
+	}
+	var diff []string
+	for _, z := range a {
+		if !inB[z] {
+			diff = append(diff, z)
+		}
+	}
+	return diff
+}
+
+// describeZones describes a zone placement for display in messages.
+func describeZones(zones []string) string {
+	switch len(zones) {
+	case 0:
+		return "no zone pinning"
+	case 1:
+		return fmt.Sprintf("zonal (zone %s)", zones[0])
+	default:
+		return fmt.Sprintf("zone-redundant (zones %s)", strings.Join(zones, ", "))
+	}
+}
@@ -0,0 +1,266 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestAvailabilityZoneChange_Metadata(t *testing.T) {
+	rule := NewAzurermAvailabilityZoneChangeRule()
+	if rule.Name() != "azurerm_availability_zone_change" {
+		t.Errorf("Expected rule name to be 'azurerm_availability_zone_change', got '%s'", rule.Name())
+	}
+	if !rule.Enabled() {
+		t.Error("Expected rule to be enabled by default")
+	}
+	if rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected severity to be ERROR, got %v", rule.Severity())
+	}
+	if !strings.Contains(rule.Link(), "azurerm_availability_zone_change") {
+		t.Errorf("Expected link to contain rule name, got '%s'", rule.Link())
+	}
+}
+
+func TestAvailabilityZoneChange_ZonalToZoneRedundant(t *testing.T) {
+	rule := NewAzurermAvailabilityZoneChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_public_ip" "lb" {
+    name  = "lb-ip"
+    zones = ["1"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_public_ip" "lb" {
+    name  = "lb-ip"
+    zones = ["1", "2", "3"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected ERROR severity, got %v", issue.Rule.Severity())
+	}
+	for _, want := range []string{"from zonal (zone 1) to zone-redundant (zones 1, 2, 3)", "forces recreation"} {
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, issue.Message)
+		}
+	}
+}
+
+func TestAvailabilityZoneChange_Reordered(t *testing.T) {
+	rule := NewAzurermAvailabilityZoneChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_firewall" "hub" {
+    name  = "fw"
+    zones = ["1", "2", "3"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_firewall" "hub" {
+    name  = "fw"
+    zones = ["3", "1", "2"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Zones are compared as sets
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestAvailabilityZoneChange_NodePool(t *testing.T) {
+	rule := NewAzurermAvailabilityZoneChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name  = "user"
+    zones = ["1"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_kubernetes_cluster_node_pool" "user" {
+    name  = "user"
+    zones = ["1", "2"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Reported by azurerm_aks_node_pool_recreation instead
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestAvailabilityZoneChange_SingleZoneAttribute(t *testing.T) {
+	rule := NewAzurermAvailabilityZoneChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_linux_virtual_machine" "vm" {
+    name = "vm"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_linux_virtual_machine" "vm" {
+    name = "vm"
+    zone = "2"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "from no zone pinning to zonal (zone 2)") {
+		t.Errorf("Unexpected message: %q", runner.Issues[0].Message)
+	}
+}
+
+func TestAvailabilityZoneChange_Rejected(t *testing.T) {
+	rule := NewAzurermAvailabilityZoneChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_postgresql_flexible_server" "db" {
+    name = "db"
+    zone = "1"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_postgresql_flexible_server" "db" {
+    name = "db"
+    zone = "2"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "the apply will fail") {
+		t.Errorf("Expected message to explain the change fails, got %q", runner.Issues[0].Message)
+	}
+}
+
+func TestAvailabilityZoneChange_AddOnly(t *testing.T) {
+	tests := []struct {
+		name         string
+		oldZones     string
+		newZones     string
+		wantSeverity tflint.Severity
+		wantText     string
+	}{
+		{"add zone", `["1"]`, `["1", "2"]`, tflint.NOTICE, "applied in place"},
+		{"remove zone", `["1", "2"]`, `["1"]`, tflint.ERROR, "removing zone 2 is rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermAvailabilityZoneChangeRule()
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_linux_virtual_machine_scale_set" "web" {
+    name  = "web"
+    zones = ` + tt.oldZones + `
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_linux_virtual_machine_scale_set" "web" {
+    name  = "web"
+    zones = ` + tt.newZones + `
+}`,
+				},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			if runner.Issues[0].Rule.Severity() != tt.wantSeverity {
+				t.Errorf("Expected %v severity, got %v", tt.wantSeverity, runner.Issues[0].Rule.Severity())
+			}
+			if !strings.Contains(runner.Issues[0].Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestAttrZones(t *testing.T) {
+	tests := []struct {
+		name  string
+		value cty.Value
+		want  string
+	}{
+		{"single zone", cty.StringVal("2"), "2"},
+		{"zone list", cty.ListVal([]cty.Value{cty.StringVal("3"), cty.StringVal("1")}), "1,3"},
+		{"zone set", cty.SetVal([]cty.Value{cty.StringVal("2"), cty.StringVal("1")}), "1,2"},
+		{"repeated zones", cty.ListVal([]cty.Value{cty.StringVal("1"), cty.StringVal("1"), cty.StringVal("2")}), "1,2"},
+		{"zone redundant", cty.StringVal("Zone-Redundant"), "1,2,3"},
+		{"no zone", cty.StringVal("No-Zone"), ""},
+		{"null", cty.NullVal(cty.String), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zones, ok := attrZones(&hclext.Attribute{Name: "zones", Value: tt.value})
+			if !ok {
+				t.Fatal("expected zones to be resolved")
+			}
+			if got := strings.Join(zones, ","); got != tt.want {
+				t.Errorf("attrZones() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := attrZones(&hclext.Attribute{Name: "zones", Value: cty.UnknownVal(cty.List(cty.String))}); ok {
+		t.Error("expected unknown value to be unresolved")
+	}
+}
@@ -0,0 +1,188 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// AzurermDeprecatedAttributeRule detects newly introduced usage of deprecated
+// attributes and resource types in Azure RM resources.
+// Usage that already existed in the old configuration is not reported.
+type AzurermDeprecatedAttributeRule struct {
+	tflint.DefaultRule
+	schema *schema.Schema
+}
+
+// NewAzurermDeprecatedAttributeRule creates a new deprecated attribute detection rule.
+func NewAzurermDeprecatedAttributeRule() *AzurermDeprecatedAttributeRule {
+	return &AzurermDeprecatedAttributeRule{
+		schema: schema.Load(),
+	}
+}
+
+// Name returns the rule name.
+func (r *AzurermDeprecatedAttributeRule) Name() string {
+	return "azurerm_deprecated_attribute"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermDeprecatedAttributeRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// Deprecated usage still works today, so it is reported as a warning.
+func (r *AzurermDeprecatedAttributeRule) Severity() tflint.Severity {
+	return tflint.WARNING
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermDeprecatedAttributeRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks for deprecated attributes and resource types introduced by the new configuration.
+func (r *AzurermDeprecatedAttributeRule) Check(runner tflint.Runner) error {
+	resourceTypes := r.schema.GetResourceTypes()
+	sort.Strings(resourceTypes)
+
+	for _, resourceType := range resourceTypes {
+		if !strings.HasPrefix(resourceType, "azurerm_") {
+			continue
+		}
+
+		resourceMessage, resourceDeprecated := r.schema.GetResourceDeprecation(resourceType)
+		deprecatedAttrs := r.schema.GetDeprecatedAttributes(resourceType)
+		if !resourceDeprecated && len(deprecatedAttrs) == 0 {
+			continue
+		}
+
+		attrPaths := make([]string, 0, len(deprecatedAttrs))
+		for path := range deprecatedAttrs {
+			attrPaths = append(attrPaths, path)
+		}
+		sort.Strings(attrPaths)
+
+		bodySchema := buildBodySchema(attrPaths)
+
+		oldContent, err := runner.GetOldResourceContent(resourceType, bodySchema, nil)
+		if err != nil {
+			return fmt.Errorf("get old %s: %w", resourceType, err)
+		}
+		newContent, err := runner.GetNewResourceContent(resourceType, bodySchema, nil)
+		if err != nil {
+			return fmt.Errorf("get new %s: %w", resourceType, err)
+		}
+
+		oldByName := make(map[string]*hclext.Block)
+		for _, block := range oldContent.Blocks {
+			if len(block.Labels) >= 2 {
+				oldByName[block.Labels[1]] = block
+			}
+		}
+
+		for _, newBlock := range newContent.Blocks {
+			if len(newBlock.Labels) < 2 {
+				continue
+			}
+			name := newBlock.Labels[1]
+			oldBlock, existed := oldByName[name]
+
+			// A deprecated resource type is only reported when the resource is new
+			if resourceDeprecated && !existed {
+				message := fmt.Sprintf(
+					"%s.%s uses deprecated resource type %s%s",
+					resourceType, name, resourceType, deprecationDetail(resourceMessage),
+				)
+				message += replacementSuffix(resourceMessage, resourceType)
+				if err := runner.EmitIssue(r, message, newBlock.DefRange); err != nil {
+					return err
+				}
+			}
+
+			for _, attrPath := range attrPaths {
+				newAttr := getAttributeByPath(newBlock, attrPath)
+				if newAttr == nil {
+					continue
+				}
+				if existed && getAttributeByPath(oldBlock, attrPath) != nil {
+					continue // Pre-existing usage
+				}
+
+				deprecation := deprecatedAttrs[attrPath]
+				message := fmt.Sprintf(
+					"%q on %s.%s is deprecated%s",
+					attrPath, resourceType, name, deprecationDetail(deprecation),
+				)
+				message += replacementSuffix(deprecation, lastPathSegment(attrPath))
+				issueRange := newAttr.Range
+				if issueRange == (hcl.Range{}) {
+					issueRange = newBlock.DefRange
+				}
+				if err := runner.EmitIssue(r, message, issueRange); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// deprecationDetail formats a deprecation message to end a finding sentence.
+// The schema only has messages extracted from the provider source or curated in the
+// overlay; without one, the sentence ends with a pointer to the provider documentation.
+func deprecationDetail(message string) string {
+	if message == "" {
+		return ". See the provider documentation for the replacement."
+	}
+	return ": " + message
+}
+
+// backtickedName matches identifiers quoted with backticks in provider deprecation messages,
+// e.g. "`enable_https_traffic_only` has been superseded by `https_traffic_only_enabled`".
+var backtickedName = regexp.MustCompile("`([a-z][a-z0-9_.]*)`")
+
+// deprecationReplacements extracts the suggested replacements from a deprecation message.
+// The provider quotes attribute and resource names in backticks; every quoted name other
+// than the deprecated one itself is treated as a replacement.
+func deprecationReplacements(message, deprecated string) []string {
+	var replacements []string
+	seen := map[string]bool{deprecated: true}
+	for _, match := range backtickedName.FindAllStringSubmatch(message, -1) {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		replacements = append(replacements, name)
+	}
+	return replacements
+}
+
+// replacementSuffix formats the suggested replacements for a deprecation message.
+// Returns an empty string when the message does not name a replacement.
+func replacementSuffix(message, deprecated string) string {
+	replacements := deprecationReplacements(message, deprecated)
+	if len(replacements) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" Suggested replacement: %s.", strings.Join(replacements, " or "))
+}
+
+// lastPathSegment returns the final element of a dot-separated attribute path.
+func lastPathSegment(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
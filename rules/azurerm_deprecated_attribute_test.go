@@ -0,0 +1,330 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// deprecatedTestSchema is a minimal schema shaped like the output of tools/extract-forcenew:
+// `deprecated` is the bool of `terraform providers schema -json`, and `deprecation_message`
+// is extracted from the provider source. legacy_mode has no message, as when the source
+// builds it dynamically.
+const deprecatedTestSchema = `{
+	"resource_schemas": {
+		"azurerm_storage_account": {
+			"block": {
+				"attributes": {
+					"name": {"type": "string", "required": true, "force_new": true},
+					"enable_https_traffic_only": {
+						"type": "bool",
+						"optional": true,
+						"deprecated": true,
+						"deprecation_message": "` + "`enable_https_traffic_only`" + ` has been superseded by ` + "`https_traffic_only_enabled`" + ` and will be removed in v4.0 of the AzureRM Provider."
+					},
+					"https_traffic_only_enabled": {"type": "bool", "optional": true}
+				},
+				"block_types": {
+					"network_rules": {
+						"nesting_mode": "list",
+						"block": {
+							"attributes": {
+								"legacy_mode": {"type": "string", "optional": true, "deprecated": true}
+							}
+						}
+					}
+				}
+			}
+		},
+		"azurerm_app_service": {
+			"block": {
+				"deprecated": true,
+				"deprecation_message": "The ` + "`azurerm_app_service`" + ` resource has been superseded by the ` + "`azurerm_linux_web_app`" + ` and ` + "`azurerm_windows_web_app`" + ` resources.",
+				"attributes": {
+					"name": {"type": "string", "required": true, "force_new": true}
+				}
+			}
+		}
+	}
+}`
+
+func newTestDeprecatedAttributeRule(t *testing.T) *AzurermDeprecatedAttributeRule {
+	t.Helper()
+	s, err := schema.LoadFromJSON([]byte(deprecatedTestSchema))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+	return &AzurermDeprecatedAttributeRule{schema: s}
+}
+
+func TestDeprecatedAttribute_Metadata(t *testing.T) {
+	rule := NewAzurermDeprecatedAttributeRule()
+	if rule.Name() != "azurerm_deprecated_attribute" {
+		t.Errorf("Expected rule name to be 'azurerm_deprecated_attribute', got '%s'", rule.Name())
+	}
+	if !rule.Enabled() {
+		t.Error("Expected rule to be enabled by default")
+	}
+	if rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected severity to be WARNING, got %v", rule.Severity())
+	}
+	if !strings.Contains(rule.Link(), "azurerm_deprecated_attribute") {
+		t.Errorf("Expected link to contain rule name, got '%s'", rule.Link())
+	}
+}
+
+func TestDeprecatedAttribute_NewUsage(t *testing.T) {
+	rule := newTestDeprecatedAttributeRule(t)
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_storage_account" "example" {
+    name = "mystorage"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_storage_account" "example" {
+    name                      = "mystorage"
+    enable_https_traffic_only = true
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	msg := runner.Issues[0].Message
+	if !strings.Contains(msg, "enable_https_traffic_only") {
+		t.Errorf("Expected message to mention the attribute, got %q", msg)
+	}
+	if !strings.Contains(msg, "Suggested replacement: https_traffic_only_enabled.") {
+		t.Errorf("Expected message to suggest the replacement, got %q", msg)
+	}
+	if runner.Issues[0].Range.Start.Line != 4 {
+		t.Errorf("Expected issue on line 4, got %d", runner.Issues[0].Range.Start.Line)
+	}
+}
+
+func TestDeprecatedAttribute_PreExistingUsage(t *testing.T) {
+	rule := newTestDeprecatedAttributeRule(t)
+
+	config := `
+resource "azurerm_storage_account" "example" {
+    name                      = "mystorage"
+    enable_https_traffic_only = true
+}`
+	runner := helper.TestRunner(t,
+		map[string]string{"main.tf": config},
+		map[string]string{"main.tf": config},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Usage that already existed should not be reported
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestDeprecatedAttribute_NestedUsage(t *testing.T) {
+	rule := newTestDeprecatedAttributeRule(t)
+
+	runner := helper.TestRunner(t,
+		map[string]string{"main.tf": ``},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_storage_account" "example" {
+    name = "mystorage"
+    network_rules {
+        legacy_mode = "on"
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	msg := runner.Issues[0].Message
+	if !strings.Contains(msg, `"network_rules.legacy_mode"`) {
+		t.Errorf("Expected message to mention the nested attribute path, got %q", msg)
+	}
+	if strings.Contains(msg, "Suggested replacement") {
+		t.Errorf("Did not expect a replacement suggestion, got %q", msg)
+	}
+	if !strings.Contains(msg, "is deprecated. See the provider documentation") {
+		t.Errorf("Expected a pointer to the provider documentation without a message, got %q", msg)
+	}
+}
+
+// TestDeprecatedAttribute_TerraformSchema checks the rule against a schema in the shape of
+// `terraform providers schema -json`, which has deprecated bools but no messages.
+func TestDeprecatedAttribute_TerraformSchema(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"format_version": "1.0",
+		"resource_schemas": {
+			"azurerm_storage_account": {
+				"version": 4,
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "description_kind": "plain", "required": true},
+						"enable_https_traffic_only": {"type": "bool", "description_kind": "plain", "optional": true, "computed": true, "deprecated": true}
+					},
+					"description_kind": "plain"
+				}
+			},
+			"azurerm_app_service": {
+				"version": 0,
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "description_kind": "plain", "required": true}
+					},
+					"description_kind": "plain",
+					"deprecated": true
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+	rule := &AzurermDeprecatedAttributeRule{schema: s}
+
+	runner := helper.TestRunner(t,
+		map[string]string{"main.tf": ``},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_storage_account" "example" {
+    name                      = "mystorage"
+    enable_https_traffic_only = true
+}
+
+resource "azurerm_app_service" "example" {
+    name = "my-app"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	for _, want := range []string{
+		"azurerm_app_service.example uses deprecated resource type azurerm_app_service. See the provider documentation",
+		`"enable_https_traffic_only" on azurerm_storage_account.example is deprecated. See the provider documentation`,
+	} {
+		found := false
+		for _, issue := range runner.Issues {
+			found = found || strings.Contains(issue.Message, want)
+		}
+		if !found {
+			t.Errorf("Expected an issue containing %q, got %v", want, runner.Issues)
+		}
+	}
+}
+
+func TestDeprecatedAttribute_NewDeprecatedResource(t *testing.T) {
+	rule := newTestDeprecatedAttributeRule(t)
+
+	runner := helper.TestRunner(t,
+		map[string]string{"main.tf": ``},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "example" {
+    name = "my-app"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	msg := runner.Issues[0].Message
+	if !strings.Contains(msg, "deprecated resource type azurerm_app_service") {
+		t.Errorf("Expected message to mention the deprecated resource type, got %q", msg)
+	}
+	if !strings.Contains(msg, "Suggested replacement: azurerm_linux_web_app or azurerm_windows_web_app.") {
+		t.Errorf("Expected message to suggest replacements, got %q", msg)
+	}
+}
+
+func TestDeprecatedAttribute_ExistingDeprecatedResource(t *testing.T) {
+	rule := newTestDeprecatedAttributeRule(t)
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "example" {
+    name = "my-app"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "example" {
+    name = "my-app"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestDeprecationReplacements(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		deprecated string
+		want       []string
+	}{
+		{
+			name:       "single replacement",
+			message:    "`enable_https_traffic_only` has been superseded by `https_traffic_only_enabled`",
+			deprecated: "enable_https_traffic_only",
+			want:       []string{"https_traffic_only_enabled"},
+		},
+		{
+			name:       "multiple replacements",
+			message:    "The `azurerm_app_service` resource has been superseded by the `azurerm_linux_web_app` and `azurerm_windows_web_app` resources",
+			deprecated: "azurerm_app_service",
+			want:       []string{"azurerm_linux_web_app", "azurerm_windows_web_app"},
+		},
+		{
+			name:       "no replacement",
+			message:    "This property is no longer supported.",
+			deprecated: "legacy_mode",
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deprecationReplacements(tt.message, tt.deprecated)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("deprecationReplacements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
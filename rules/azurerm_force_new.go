@@ -3,8 +3,12 @@ package rules
 
 import (
 	"fmt"
+	"math/big"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
@@ -12,6 +16,7 @@ import (
 	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
 	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 )
 
 // AzurermForceNewRule detects changes to ForceNew attributes in Azure RM resources.
@@ -19,12 +24,22 @@ import (
 type AzurermForceNewRule struct {
 	tflint.DefaultRule
 	schema *schema.Schema
+	// now returns the current time, used to expire suppressions.
+	now func() time.Time
+	// readModule reads the Terraform files of a local module directory, by file name.
+	// Module calls are not followed when it is nil.
+	readModule func(dir string) (map[string][]byte, error)
+	// readFile reads the configuration file and its state_file.
+	readFile func(name string) ([]byte, error)
 }
 
 // NewAzurermForceNewRule creates a new ForceNew detection rule.
 func NewAzurermForceNewRule() *AzurermForceNewRule {
 	return &AzurermForceNewRule{
-		schema: schema.Load(),
+		schema:     schema.Load(),
+		now:        time.Now,
+		readModule: readModuleFiles,
+		readFile:   os.ReadFile,
 	}
 }
 
@@ -51,31 +66,76 @@ func (r *AzurermForceNewRule) Link() string {
 
 // Check checks for ForceNew attribute changes between old and new configurations.
 func (r *AzurermForceNewRule) Check(runner tflint.Runner) error {
+	policy, err := r.loadConfig(runner)
+	if err != nil {
+		return err
+	}
+
+	replaced, err := r.checkResources(runner, policy)
+	if err != nil {
+		return err
+	}
+	if err := r.checkReplaceTriggeredBy(runner, policy, replaced); err != nil {
+		return err
+	}
+	if err := r.checkModuleCalls(runner, policy); err != nil {
+		return err
+	}
+
+	for _, notice := range policy.suppressionReport() {
+		if err := runner.EmitIssue(withSeverity(r, tflint.NOTICE), notice.Message, notice.Range); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkResources checks the azurerm resources of the module read by runner for ForceNew
+// and one-way attribute changes. It returns the module-relative addresses of the resources
+// that are recreated, for replace_triggered_by.
+func (r *AzurermForceNewRule) checkResources(runner tflint.Runner, policy *forceNewPolicy) (map[string]bool, error) {
 	// Get list of azurerm resource types from schema
 	resourceTypes := r.schema.GetResourceTypes()
 
+	// Addresses of resources that are recreated, for replace_triggered_by
+	replaced := make(map[string]bool)
+
 	for _, resourceType := range resourceTypes {
-		if !strings.HasPrefix(resourceType, "azurerm_") {
+		if !strings.HasPrefix(resourceType, "azurerm_") || !policy.checksResourceType(resourceType) {
 			continue
 		}
 
 		forceNewAttrs := r.schema.GetForceNewAttributes(resourceType)
-		if len(forceNewAttrs) == 0 {
+		// One-way attributes are checked for in-place changes that cannot be reverted
+		checkedAttrs := append([]string(nil), forceNewAttrs...)
+		for _, attrPath := range r.schema.GetOneWayAttributes(resourceType) {
+			if !r.schema.IsForceNew(resourceType, attrPath) {
+				checkedAttrs = append(checkedAttrs, attrPath)
+			}
+		}
+		if len(checkedAttrs) == 0 {
 			continue
 		}
 
-		// Build schema for the ForceNew attributes, including nested blocks
-		bodySchema := buildBodySchema(forceNewAttrs)
+		// Build schema for the ForceNew attributes, including nested blocks,
+		// and the lifecycle meta-arguments that decide whether changes are applied.
+		// tags and name are read to determine the resource's environment,
+		// and the ID template attributes to compute import IDs for remediation.
+		attrPaths := append([]string{"tags", "name"}, idTemplateAttributes(resourceType)...)
+		bodySchema := buildBodySchema(append(attrPaths, checkedAttrs...))
+		bodySchema.Blocks = append(bodySchema.Blocks, lifecycleBlockSchema)
 
 		// Get old and new content for this resource type
 		oldContent, err := runner.GetOldResourceContent(resourceType, bodySchema, nil)
 		if err != nil {
-			return fmt.Errorf("get old %s: %w", resourceType, err)
+			return nil, fmt.Errorf("get old %s: %w", resourceType, err)
 		}
 		newContent, err := runner.GetNewResourceContent(resourceType, bodySchema, nil)
 		if err != nil {
-			return fmt.Errorf("get new %s: %w", resourceType, err)
+			return nil, fmt.Errorf("get new %s: %w", resourceType, err)
 		}
+		expandDynamicBlocks(oldContent)
+		expandDynamicBlocks(newContent)
 
 		// Build map of old resources by name
 		oldByName := make(map[string]*hclext.Block)
@@ -96,36 +156,326 @@ func (r *AzurermForceNewRule) Check(runner tflint.Runner) error {
 				continue // New resource, not a ForceNew change
 			}
 
+			// Terraform does not act on changes listed in ignore_changes
+			lifecycle := readLifecycle(newBlock)
+			address := resourceAddress(runner, resourceType, name)
+			environment := policy.environment(address, newBlock)
+
 			// Check each ForceNew attribute
-			for _, attrPath := range forceNewAttrs {
-				oldAttr := getAttributeByPath(oldBlock, attrPath)
+			for _, attrPath := range checkedAttrs {
+				if lifecycle.Ignores(attrPath) || !policy.checksAttribute(resourceType, attrPath) {
+					continue
+				}
+				// Old values that depend on variables or data sources are taken from state
+				oldAttr, fromState := policy.state.oldAttribute(address, attrPath, getAttributeByPath(oldBlock, attrPath))
 				newAttr := getAttributeByPath(newBlock, attrPath)
+				attrSchema := r.schema.GetAttribute(resourceType, attrPath)
 
-				changed, oldVal, newVal := r.attributeChanged(oldAttr, newAttr)
+				c := comparisonFor(attrSchema)
+				changed, oldVal, newVal := r.attributeChanged(oldAttr, newAttr, c)
+				oneWay := !changed && oldVal != newVal && attrSchema != nil && attrSchema.OneWay
+				if !changed && !oneWay {
+					continue
+				}
+				// Keep secrets out of CI logs
+				if r.schema.IsSensitive(resourceType, attrPath) {
+					oldVal, newVal = policy.maskValue(oldVal), policy.maskValue(newVal)
+				}
+				oldVal, newVal = valueSource(oldVal, oldAttr, c, fromState), valueSource(newVal, newAttr, c, false)
 				if changed {
-					// Include remediation in message per CR-0002
+					replaced[resourceType+"."+name] = true
+				}
+				if policy.suppressed(address, attrPath) {
+					continue
+				}
+
+				var explanation string
+				var dataLoss bool
+				if attrSchema != nil {
+					explanation, dataLoss = attrSchema.Explanation, attrSchema.DataLoss
+				}
+				// ignore_changes that cannot be read may list the attribute
+				var ignoreNote string
+				if !lifecycle.IgnoreKnown {
+					ignoreNote = "The change may be ignored by ignore_changes, which cannot be read. "
+				}
+				issueRange := hcl.Range{}
+				if newAttr != nil {
+					issueRange = newAttr.Range
+				} else if newBlock.DefRange != (hcl.Range{}) {
+					issueRange = newBlock.DefRange
+				}
+
+				if oneWay {
+					remediation := oneWayRemediation()
 					message := fmt.Sprintf(
-						"Changing %q forces recreation of %s.%s (old: %s, new: %s). "+
-							"Consider using a moved block or creating a new resource with a different name.",
-						attrPath, resourceType, name, formatValue(oldVal), formatValue(newVal),
+						"Changing %q of %s (old: %s, new: %s) is applied in place, but cannot be reverted without recreating the resource. %s%s%s",
+						attrPath, address, formatValue(oldVal), formatValue(newVal),
+						ignoreNote, sentence(explanation), remediation,
 					)
-					issueRange := hcl.Range{}
-					if newAttr != nil {
-						issueRange = newAttr.Range
-					} else if newBlock.DefRange != (hcl.Range{}) {
-						issueRange = newBlock.DefRange
-					}
-					if err := runner.EmitIssue(r, message, issueRange); err != nil {
-						return err
+					message = withFinding(message, finding{
+						Category:     findingOneWay,
+						ResourceType: resourceType,
+						Address:      address,
+						Attribute:    attrPath,
+						OldValue:     formatValue(oldVal),
+						NewValue:     formatValue(newVal),
+						Explanation:  explanation,
+						Remediation:  newFindingRemediation(remediation),
+					})
+					if err := runner.EmitIssue(withSeverity(r, tflint.WARNING), message, issueRange); err != nil {
+						return nil, err
 					}
+					continue
+				}
+
+				// Include impact and remediation in message per CR-0002
+				criticality := policy.criticality(resourceType)
+				impact := impactSentence(criticality)
+				if dataLoss && criticality != criticalityStateful {
+					impact = impactSentence(criticalityStateful)
+				}
+				remediation := forceNewRemediation(resourceType, address, newBlock, attrPath)
+				message := fmt.Sprintf(
+					"Changing %q forces recreation of %s (old: %s, new: %s). %s%s%s%s",
+					attrPath, address, formatValue(oldVal), formatValue(newVal),
+					ignoreNote, sentence(explanation), impact, remediation,
+				)
+				message = withFinding(message, finding{
+					Category:     findingForceNew,
+					ResourceType: resourceType,
+					Address:      address,
+					Attribute:    attrPath,
+					OldValue:     formatValue(oldVal),
+					NewValue:     formatValue(newVal),
+					Criticality:  criticality,
+					DataLoss:     dataLoss,
+					Explanation:  explanation,
+					Remediation:  newFindingRemediation(remediation),
+				})
+				severity := policy.severity(resourceType, attrPath, environment)
+				if ignoreNote != "" && severity == tflint.ERROR {
+					severity = tflint.WARNING
+				}
+				if err := runner.EmitIssue(withSeverity(r, severity), message, issueRange); err != nil {
+					return nil, err
 				}
 			}
 		}
 	}
+	return replaced, nil
+}
+
+// impactSentence returns the impact of recreating a resource of a criticality tier,
+// followed by a space, or an empty string if the tier has no notable impact.
+func impactSentence(tier resourceCriticality) string {
+	if impact := criticalityImpacts[tier]; impact != "" {
+		return impact + " "
+	}
+	return ""
+}
+
+// sentence returns text followed by a space, or an empty string if text is empty.
+func sentence(text string) string {
+	if text == "" {
+		return ""
+	}
+	return text + " "
+}
+
+// triggerResourcesSchema retrieves the lifecycle block of every resource in the module,
+// and the tags and name that determine its environment.
+var triggerResourcesSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type:       "resource",
+			LabelNames: []string{"type", "name"},
+			Body: &hclext.BodySchema{
+				Attributes: []hclext.AttributeSchema{{Name: "tags"}, {Name: "name"}},
+				Blocks:     []hclext.BlockSchema{lifecycleBlockSchema},
+			},
+		},
+	},
+}
+
+// checkReplaceTriggeredBy reports azurerm resources that are recreated because a
+// replace_triggered_by reference changes. replaced holds the addresses of resources that
+// are already known to be recreated; it is extended as triggered replacements are found,
+// so replacements that cascade through several resources are reported as well.
+func (r *AzurermForceNewRule) checkReplaceTriggeredBy(runner tflint.Runner, policy *forceNewPolicy, replaced map[string]bool) error {
+	oldContent, err := runner.GetOldModuleContent(lifecycleResourcesSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old resources: %w", err)
+	}
+	newContent, err := runner.GetNewModuleContent(triggerResourcesSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new resources: %w", err)
+	}
+
+	existed := make(map[string]bool)
+	for _, block := range oldContent.Blocks {
+		if len(block.Labels) >= 2 {
+			existed[block.Labels[0]+"."+block.Labels[1]] = true
+		}
+	}
+
+	type trigger struct {
+		resourceType string
+		// address is relative to the module, absAddress includes the module path
+		address     string
+		absAddress  string
+		lifecycle   lifecycleSettings
+		environment *environmentState
+	}
+	var pending []trigger
+	for _, block := range newContent.Blocks {
+		if len(block.Labels) < 2 || !strings.HasPrefix(block.Labels[0], "azurerm_") || !policy.checksResourceType(block.Labels[0]) {
+			continue
+		}
+		address := block.Labels[0] + "." + block.Labels[1]
+		lifecycle := readLifecycle(block)
+		if !existed[address] || len(lifecycle.ReplaceTriggeredBy) == 0 {
+			continue
+		}
+		absAddress := resourceAddress(runner, block.Labels[0], block.Labels[1])
+		pending = append(pending, trigger{
+			resourceType: block.Labels[0],
+			address:      address,
+			absAddress:   absAddress,
+			lifecycle:    lifecycle,
+			environment:  policy.environment(absAddress, block),
+		})
+	}
+
+	changes := make(map[string]map[string][2]string)
+	for found := true; found; {
+		found = false
+		remaining := pending[:0]
+		for _, t := range pending {
+			if replaced[t.address] {
+				continue
+			}
+			reference, reason, err := r.triggeredReplacement(runner, policy, t.lifecycle.ReplaceTriggeredBy, replaced, changes)
+			if err != nil {
+				return err
+			}
+			if reference == "" {
+				remaining = append(remaining, t)
+				continue
+			}
+
+			replaced[t.address] = true
+			found = true
+			if policy.suppressed(t.absAddress, "") {
+				continue
+			}
+			remediation := triggerRemediation(t.absAddress)
+			message := fmt.Sprintf(
+				"%s is recreated because its lifecycle.replace_triggered_by references %s, which %s. %s",
+				t.absAddress, reference, reason, remediation,
+			)
+			message = withFinding(message, finding{
+				Category:     findingReplaceTriggered,
+				ResourceType: t.resourceType,
+				Address:      t.absAddress,
+				Trigger:      reference,
+				Criticality:  policy.criticality(t.resourceType),
+				Remediation:  newFindingRemediation(remediation),
+			})
+			attr := t.lifecycle.Block.Body.Attributes["replace_triggered_by"]
+			if err := runner.EmitIssue(withSeverity(r, policy.severity(t.resourceType, "", t.environment)), message, attr.Range); err != nil {
+				return err
+			}
+		}
+		pending = remaining
+	}
 
 	return nil
 }
 
+// triggeredReplacement returns the first replace_triggered_by reference that changes, and why.
+// A reference to a resource (or its id) changes when the resource is recreated; a reference
+// to another attribute also changes when the attribute value changes. changes caches attribute
+// comparisons by resource type and attribute path. Returns an empty reference if none changes.
+func (r *AzurermForceNewRule) triggeredReplacement(runner tflint.Runner, policy *forceNewPolicy, references []hcl.Traversal, replaced map[string]bool, changes map[string]map[string][2]string) (string, string, error) {
+	for _, traversal := range references {
+		parts := strings.Split(attributePath(traversal), ".")
+		if len(parts) < 2 {
+			continue
+		}
+		resourceType, name, attrPath := parts[0], parts[1], strings.Join(parts[2:], ".")
+		reference := traversalString(traversal)
+
+		if replaced[resourceType+"."+name] {
+			return reference, "is recreated", nil
+		}
+		if attrPath == "" || attrPath == "id" {
+			continue
+		}
+
+		key := resourceType + "." + attrPath
+		byName, ok := changes[key]
+		if !ok {
+			var err error
+			byName, err = r.attributeChanges(runner, policy, resourceType, attrPath)
+			if err != nil {
+				return "", "", err
+			}
+			changes[key] = byName
+		}
+		if change, ok := byName[name]; ok {
+			return reference, fmt.Sprintf("changes (old: %s, new: %s)", formatValue(change[0]), formatValue(change[1])), nil
+		}
+	}
+	return "", "", nil
+}
+
+// attributeChanges compares an attribute of every resource of a type, and returns the
+// old and new values of the resources where it changed, by resource name.
+// Values that cannot be statically resolved are not compared, and Sensitive values are masked.
+func (r *AzurermForceNewRule) attributeChanges(runner tflint.Runner, policy *forceNewPolicy, resourceType, attrPath string) (map[string][2]string, error) {
+	bodySchema := buildBodySchema([]string{attrPath})
+
+	oldContent, err := runner.GetOldResourceContent(resourceType, bodySchema, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get old %s: %w", resourceType, err)
+	}
+	newContent, err := runner.GetNewResourceContent(resourceType, bodySchema, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get new %s: %w", resourceType, err)
+	}
+	expandDynamicBlocks(oldContent)
+	expandDynamicBlocks(newContent)
+
+	// Any change of the referenced attribute triggers, regardless of ForceNew conditions
+	c := comparisonFor(r.schema.GetAttribute(resourceType, attrPath))
+	c.Conditions = nil
+
+	oldByName := blocksByName(oldContent)
+	changes := make(map[string][2]string)
+	for _, newBlock := range newContent.Blocks {
+		if len(newBlock.Labels) < 2 {
+			continue
+		}
+		oldBlock, exists := oldByName[newBlock.Labels[1]]
+		if !exists {
+			continue
+		}
+		oldAttr, _ := policy.state.oldAttribute(resourceAddress(runner, resourceType, newBlock.Labels[1]), attrPath, getAttributeByPath(oldBlock, attrPath))
+		newAttr := getAttributeByPath(newBlock, attrPath)
+		changed, oldVal, newVal := r.attributeChanged(oldAttr, newAttr, c)
+		if !changed || oldVal == "<dynamic>" || newVal == "<dynamic>" || oldVal == "<unknown>" || newVal == "<unknown>" {
+			continue
+		}
+		if r.schema.IsSensitive(resourceType, attrPath) {
+			oldVal, newVal = policy.maskValue(oldVal), policy.maskValue(newVal)
+		}
+		oldVal, newVal = valueSource(oldVal, oldAttr, c, false), valueSource(newVal, newAttr, c, false)
+		changes[newBlock.Labels[1]] = [2]string{oldVal, newVal}
+	}
+	return changes, nil
+}
+
 // buildBodySchema creates a BodySchema that can retrieve both top-level attributes
 // and nested block attributes from ForceNew attribute paths.
 // Paths like "location" become top-level attributes.
@@ -156,12 +506,21 @@ func buildBodySchema(attrPaths []string) *hclext.BodySchema {
 	}
 
 	// Add nested blocks recursively
+	var dynamicPaths []string
 	for blockName, subPaths := range nestedBlocks {
 		blockSchema := hclext.BlockSchema{
 			Type: blockName,
 			Body: buildBodySchema(subPaths),
 		}
 		schema.Blocks = append(schema.Blocks, blockSchema)
+		dynamicPaths = append(dynamicPaths, subPaths...)
+	}
+
+	// Nested blocks may also be generated by dynamic blocks, whose content
+	// holds the attributes of any of them (see expandDynamicBlocks)
+	if len(nestedBlocks) > 0 {
+		sort.Strings(dynamicPaths)
+		schema.Blocks = append(schema.Blocks, dynamicBlockSchema(dynamicPaths))
 	}
 
 	// Sort for deterministic ordering
@@ -202,52 +561,179 @@ func getAttributeByPath(block *hclext.Block, path string) *hclext.Attribute {
 	return nil
 }
 
+// comparison describes how the values of an attribute are compared.
+type comparison struct {
+	// Type is the declared type that values are converted to before they are compared.
+	Type cty.Type
+	// Default is the provider default that an unset attribute takes, or cty.NilVal if unknown.
+	Default cty.Value
+	// Conditions restrict which changes force a new resource; nil compares values only.
+	Conditions []schema.ForceNewCondition
+}
+
+// comparisonFor returns the comparison of an attribute from its schema, which may be nil.
+func comparisonFor(attr *schema.AttributeSchema) comparison {
+	c := comparison{Type: attr.CtyType()}
+	if def, ok := attr.DefaultValue(); ok {
+		c.Default = def
+	}
+	if attr != nil {
+		c.Conditions = attr.ForceNewIf
+	}
+	return c
+}
+
 // attributeChanged checks if an attribute value has changed.
-// Returns whether changed, old value string, new value string.
-func (r *AzurermForceNewRule) attributeChanged(oldAttr, newAttr *hclext.Attribute) (bool, string, string) {
+// Values are converted to the attribute's declared type before they are compared,
+// so that e.g. reordering the elements of a set is not a change, and an unset attribute
+// takes the provider default, so that setting the default explicitly is not a change.
+// For attributes that are only ForceNew under conditions, a change only counts
+// if one of the conditions holds.
+// Returns whether changed, old value string, new value string. The values are raw, so
+// that they can be masked before valueSource marks where they came from.
+func (r *AzurermForceNewRule) attributeChanged(oldAttr, newAttr *hclext.Attribute, c comparison) (bool, string, string) {
 	// Both nil = no change
 	if oldAttr == nil && newAttr == nil {
 		return false, "", ""
 	}
-	// One nil, one not = change
-	if oldAttr == nil {
-		newVal := evalAttr(newAttr)
-		return true, "<not set>", newVal
+
+	oldAttr = withDefault(oldAttr, c.Default)
+	newAttr = withDefault(newAttr, c.Default)
+	oldVal := evalTypedAttr(oldAttr, c.Type)
+	newVal := evalTypedAttr(newAttr, c.Type)
+	if oldVal == newVal {
+		return false, oldVal, newVal
 	}
-	if newAttr == nil {
-		oldVal := evalAttr(oldAttr)
-		return true, oldVal, "<not set>"
+	if len(c.Conditions) == 0 {
+		return true, oldVal, newVal
 	}
+	for _, condition := range c.Conditions {
+		if forceNewConditionHolds(condition, oldAttr, newAttr) {
+			return true, oldVal, newVal
+		}
+	}
+	return false, oldVal, newVal
+}
 
-	// Both present - compare evaluated values
-	oldVal := evalAttr(oldAttr)
-	newVal := evalAttr(newAttr)
-	return oldVal != newVal, oldVal, newVal
+// valueSource marks a displayed value that does not come from the configuration: the
+// provider default of an unset attribute, or the value in state.
+func valueSource(val string, attr *hclext.Attribute, c comparison, fromState bool) string {
+	if fromState {
+		return val + " (state)"
+	}
+	if attr == nil && c.Default != cty.NilVal {
+		return val + " (default)"
+	}
+	return val
+}
+
+// withDefault returns an attribute holding the provider default in place of an unset attribute.
+func withDefault(attr *hclext.Attribute, def cty.Value) *hclext.Attribute {
+	if attr != nil || def == cty.NilVal {
+		return attr
+	}
+	return &hclext.Attribute{Value: def}
+}
+
+// forceNewConditionHolds checks if a change from oldAttr to newAttr meets a ForceNew condition.
+// Values that cannot be statically resolved, including unset values whose provider default
+// is unknown, are assumed to meet it, so that possible recreations are still reported.
+func forceNewConditionHolds(condition schema.ForceNewCondition, oldAttr, newAttr *hclext.Attribute) bool {
+	switch condition.Change {
+	case schema.ForceNewDecrease, schema.ForceNewIncrease:
+		oldNum, oldOK := numberValue(oldAttr)
+		newNum, newOK := numberValue(newAttr)
+		if !oldOK || !newOK {
+			return true
+		}
+		if condition.Change == schema.ForceNewDecrease {
+			return newNum.Cmp(oldNum) < 0
+		}
+		return newNum.Cmp(oldNum) > 0
+	case schema.ForceNewTransition:
+		return matchesValueSet(evalAttr(oldAttr), condition.From) && matchesValueSet(evalAttr(newAttr), condition.To)
+	default:
+		// Unknown conditions from a newer schema format are treated as always holding
+		return true
+	}
+}
+
+// numberValue returns the static numeric value of an attribute.
+func numberValue(attr *hclext.Attribute) (*big.Float, bool) {
+	val, ok := attrValue(attr)
+	if !ok || val.IsNull() || !val.IsKnown() {
+		return nil, false
+	}
+	num, err := convert.Convert(val, cty.Number)
+	if err != nil {
+		return nil, false
+	}
+	return num.AsBigFloat(), true
+}
+
+// matchesValueSet checks if a value is in a set of values, case-insensitively.
+// An empty set and unresolved values match.
+func matchesValueSet(value string, set []string) bool {
+	if len(set) == 0 || isUnresolvedValue(value) {
+		return true
+	}
+	for _, v := range set {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
 }
 
 // evalAttr evaluates an HCL attribute to a string representation.
 // Supports both direct expression evaluation (local runner) and pre-evaluated Value (gRPC).
 func evalAttr(attr *hclext.Attribute) string {
+	return evalTypedAttr(attr, cty.DynamicPseudoType)
+}
+
+// evalTypedAttr evaluates an HCL attribute to a string representation of its value
+// converted to the declared type ty. Values that cannot be converted are formatted as is.
+func evalTypedAttr(attr *hclext.Attribute, ty cty.Type) string {
 	if attr == nil {
 		return "<not set>"
 	}
 
+	val, ok := attrValue(attr)
+	if !ok {
+		return "<dynamic>"
+	}
+	if ty != cty.DynamicPseudoType && ty != cty.NilType {
+		if converted, err := convert.Convert(val, ty); err == nil {
+			val = converted
+		}
+	}
+	return formatCtyValue(val)
+}
+
+// attrValue returns the static value of an HCL attribute.
+// Supports both direct expression evaluation (local runner) and pre-evaluated Value (gRPC).
+// Returns false when the value cannot be determined without an evaluation context.
+func attrValue(attr *hclext.Attribute) (cty.Value, bool) {
+	if attr == nil {
+		return cty.NilVal, false
+	}
+
 	// First check if we have a pre-evaluated Value (from gRPC serialization).
 	// We accept any non-NilVal, including null and unknown values which will
 	// be formatted appropriately by formatCtyValue.
 	if attr.Value != cty.NilVal {
-		return formatCtyValue(attr.Value)
+		return attr.Value, true
 	}
 
 	// Fall back to expression evaluation (direct runner, not gRPC)
 	if attr.Expr != nil {
 		val, diags := attr.Expr.Value(nil)
 		if !diags.HasErrors() {
-			return formatCtyValue(val)
+			return val, true
 		}
 	}
 
-	return "<dynamic>"
+	return cty.NilVal, false
 }
 
 // formatCtyValue formats a cty.Value for display.
@@ -270,11 +756,42 @@ func formatCtyValue(val cty.Value) string {
 			return "true"
 		}
 		return "false"
+	}
+
+	ty := val.Type()
+	switch {
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		// Sets iterate in a canonical order, so equal sets format the same
+		var elems []string
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			elems = append(elems, formatNestedCtyValue(elem))
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case ty.IsMapType() || ty.IsObjectType():
+		// Map keys and object attributes iterate in lexical order
+		var elems []string
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			elems = append(elems, key.AsString()+" = "+formatNestedCtyValue(elem))
+		}
+		return "{" + strings.Join(elems, ", ") + "}"
 	default:
 		return fmt.Sprintf("%#v", val)
 	}
 }
 
+// formatNestedCtyValue formats an element of a collection, quoting strings.
+func formatNestedCtyValue(val cty.Value) string {
+	if val.IsKnown() && !val.IsNull() && val.Type() == cty.String {
+		return strconv.Quote(val.AsString())
+	}
+	if val.IsKnown() && val.IsNull() {
+		return "null"
+	}
+	return formatCtyValue(val)
+}
+
 // formatValue formats a value for display in messages.
 func formatValue(v string) string {
 	if v == "" {
@@ -0,0 +1,610 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// forceNewConfig is the rule-specific configuration of azurerm_force_new:
+//
+//	rule "azurerm_force_new" {
+//	  exclude_resource_types = ["azurerm_resource_group"]
+//	  exclude_attributes     = ["azurerm_storage_account.account_kind", "*.location"]
+//	  severity_overrides     = { "azurerm_network_security_rule" = "WARNING" }
+//	  criticality_severities = { stateless = "NOTICE" }
+//	  criticality_overrides  = { "azurerm_app_configuration" = "stateful" }
+//	  environment_tag        = "environment"
+//	  hash_sensitive_values  = true
+//	  state_file             = "terraform.tfstate"
+//
+//	  environment {
+//	    name       = "dev"
+//	    tag_values = ["dev", "test"]
+//	    addresses  = ["module.dev.*"]
+//	    severity   = "WARNING"
+//	  }
+//
+//	  suppression {
+//	    address       = "module.legacy.*"
+//	    attributes    = ["location"]
+//	    expires       = "2026-12-31"
+//	    justification = "Region move tracked in the migration plan"
+//	  }
+//	}
+//
+// Resource types are matched with glob patterns; attributes are matched with
+// "<resource type>.<attribute path>" glob patterns. The gRPC runner decodes the
+// configuration from JSON, so every field carries both hcl and json tags.
+type forceNewConfig struct {
+	// ExcludeResourceTypes lists resource types that are not checked.
+	ExcludeResourceTypes []string `hcl:"exclude_resource_types,optional" json:"exclude_resource_types"`
+	// IncludeResourceTypes limits checking to these resource types. Empty means all.
+	IncludeResourceTypes []string `hcl:"include_resource_types,optional" json:"include_resource_types"`
+	// ExcludeAttributes lists attributes that are not checked, e.g. "azurerm_storage_account.account_kind".
+	ExcludeAttributes []string `hcl:"exclude_attributes,optional" json:"exclude_attributes"`
+	// SeverityOverrides maps resource types to the severity reported for them.
+	SeverityOverrides map[string]string `hcl:"severity_overrides,optional" json:"severity_overrides"`
+	// WarnOnlyAttributes lists attributes that are reported as warnings.
+	WarnOnlyAttributes []string `hcl:"warn_only_attributes,optional" json:"warn_only_attributes"`
+	// CriticalitySeverities maps criticality tiers to the severity reported for them.
+	CriticalitySeverities map[string]string `hcl:"criticality_severities,optional" json:"criticality_severities"`
+	// CriticalityOverrides maps resource types to a criticality tier, replacing the built-in classification.
+	CriticalityOverrides map[string]string `hcl:"criticality_overrides,optional" json:"criticality_overrides"`
+	// EnvironmentTag is the tag that holds a resource's environment. Defaults to "environment".
+	EnvironmentTag string `hcl:"environment_tag,optional" json:"environment_tag"`
+	// Environments map resources to a severity by environment, in order of precedence.
+	Environments []forceNewEnvironment `hcl:"environment,block" json:"environment"`
+	// Suppressions silence findings for matching resource addresses.
+	Suppressions []forceNewSuppression `hcl:"suppression,block" json:"suppression"`
+	// HashSensitiveValues shows a short hash of Sensitive attribute values instead of
+	// redacting them, so reviewers can tell whether values differ.
+	HashSensitiveValues bool `hcl:"hash_sensitive_values,optional" json:"hash_sensitive_values"`
+	// StateFile is a Terraform state file, or the output of `terraform show -json`, whose
+	// values replace old values that cannot be statically resolved.
+	StateFile string `hcl:"state_file,optional" json:"state_file"`
+}
+
+// forceNewEnvironment reports findings for the resources of an environment with a fixed severity.
+// A resource belongs to the environment if any of the patterns matches.
+type forceNewEnvironment struct {
+	// Name identifies the environment in messages.
+	Name string `hcl:"name,optional" json:"name"`
+	// TagValues are glob patterns matched against the resource's environment tag.
+	TagValues []string `hcl:"tag_values,optional" json:"tag_values"`
+	// Addresses are glob patterns matched against the resource address, including the module path.
+	Addresses []string `hcl:"addresses,optional" json:"addresses"`
+	// ResourceNames are glob patterns matched against the resource's name attribute.
+	ResourceNames []string `hcl:"resource_names,optional" json:"resource_names"`
+	// Severity is the severity reported for the environment's resources.
+	Severity string `hcl:"severity" json:"severity"`
+}
+
+// environmentState is a validated environment.
+type environmentState struct {
+	forceNewEnvironment
+	severity tflint.Severity
+}
+
+// defaultEnvironmentTag is the tag read when environment_tag is not set.
+const defaultEnvironmentTag = "environment"
+
+// forceNewSuppression silences findings for resources whose address matches a glob pattern,
+// such as "azurerm_resource_group.sandbox_*" or "module.legacy.*".
+type forceNewSuppression struct {
+	// Address is a resource address pattern.
+	Address string `hcl:"address" json:"address"`
+	// Attributes limits the suppression to matching attribute paths. Empty means all attributes.
+	Attributes []string `hcl:"attributes,optional" json:"attributes"`
+	// Expires is the last day (YYYY-MM-DD) the suppression applies. Empty means it never expires.
+	Expires string `hcl:"expires,optional" json:"expires"`
+	// Justification explains why the findings are accepted. It is required.
+	Justification string `hcl:"justification,optional" json:"justification"`
+}
+
+// suppressionState tracks whether a suppression applies and whether it matched a finding.
+type suppressionState struct {
+	forceNewSuppression
+	expired bool
+	matched bool
+	// Range is the suppression block in the configuration file, where it is reported.
+	Range hcl.Range
+}
+
+// suppressionNotice reports a suppression that expired or did not match any finding.
+type suppressionNotice struct {
+	Message string
+	Range   hcl.Range
+}
+
+// configFile is the tfbreak configuration file, read to locate suppressions.
+const configFile = ".tfbreak.hcl"
+
+// suppressionDateLayout is the layout of suppression expiry dates.
+const suppressionDateLayout = "2006-01-02"
+
+// forceNewPolicy is the validated form of forceNewConfig.
+type forceNewPolicy struct {
+	config                forceNewConfig
+	severityOverrides     map[string]tflint.Severity
+	criticalitySeverities map[resourceCriticality]tflint.Severity
+	criticalityOverrides  map[string]resourceCriticality
+	environmentTag        string
+	environments          []*environmentState
+	suppressions          []*suppressionState
+	// state holds the prior values of resources, or is nil without state_file.
+	state *priorState
+}
+
+// loadConfig decodes and validates the rule configuration.
+// Without configuration, every resource type and attribute is checked.
+func (r *AzurermForceNewRule) loadConfig(runner tflint.Runner) (*forceNewPolicy, error) {
+	var config forceNewConfig
+	if err := runner.DecodeRuleConfig(r.Name(), &config); err != nil {
+		return nil, fmt.Errorf("decode %s config: %w", r.Name(), err)
+	}
+	policy, err := newForceNewPolicy(config, r.schema, r.now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s config: %w", r.Name(), err)
+	}
+	if len(policy.suppressions) > 0 {
+		ruleRange, suppressionRanges := r.suppressionRanges()
+		for i, suppression := range policy.suppressions {
+			suppression.Range = ruleRange
+			if i < len(suppressionRanges) {
+				suppression.Range = suppressionRanges[i]
+			}
+		}
+	}
+	if config.StateFile != "" {
+		data, err := r.readFile(config.StateFile)
+		if err != nil {
+			return nil, fmt.Errorf("read %s state_file: %w", r.Name(), err)
+		}
+		if policy.state, err = parsePriorState(data); err != nil {
+			return nil, fmt.Errorf("parse %s state_file %s: %w", r.Name(), config.StateFile, err)
+		}
+	}
+	return policy, nil
+}
+
+// suppressionRanges returns the range of the rule block in the configuration file and the
+// ranges of its suppression blocks, in order. The gRPC runner passes the configuration as
+// JSON without source ranges, so the file is parsed again. When the rule block cannot be
+// found, the start of the file is returned.
+func (r *AzurermForceNewRule) suppressionRanges() (hcl.Range, []hcl.Range) {
+	fileRange := hcl.Range{Filename: configFile, Start: hcl.InitialPos, End: hcl.InitialPos}
+	data, err := r.readFile(configFile)
+	if err != nil {
+		return fileRange, nil
+	}
+	file, diags := hclsyntax.ParseConfig(data, configFile, hcl.InitialPos)
+	if diags.HasErrors() {
+		return fileRange, nil
+	}
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return fileRange, nil
+	}
+	for _, block := range body.Blocks {
+		if block.Type != "rule" || len(block.Labels) != 1 || block.Labels[0] != r.Name() {
+			continue
+		}
+		var ranges []hcl.Range
+		for _, nested := range block.Body.Blocks {
+			if nested.Type == "suppression" {
+				ranges = append(ranges, nested.DefRange())
+			}
+		}
+		return block.DefRange(), ranges
+	}
+	return fileRange, nil
+}
+
+// newForceNewPolicy validates a configuration against the schema.
+// Every pattern must match at least one resource type, or attribute path, in the schema,
+// so typos are reported instead of silently matching nothing. Suppressions that expired
+// before now are kept, but no longer apply.
+func newForceNewPolicy(config forceNewConfig, s *schema.Schema, now time.Time) (*forceNewPolicy, error) {
+	policy := &forceNewPolicy{
+		config:                config,
+		severityOverrides:     make(map[string]tflint.Severity),
+		criticalitySeverities: make(map[resourceCriticality]tflint.Severity, len(defaultCriticalitySeverities)),
+		criticalityOverrides:  make(map[string]resourceCriticality),
+		environmentTag:        config.EnvironmentTag,
+	}
+	if policy.environmentTag == "" {
+		policy.environmentTag = defaultEnvironmentTag
+	}
+	for tier, severity := range defaultCriticalitySeverities {
+		policy.criticalitySeverities[tier] = severity
+	}
+	resourceTypes := s.GetResourceTypes()
+	sort.Strings(resourceTypes)
+
+	var errs []error
+	for _, field := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"exclude_resource_types", config.ExcludeResourceTypes},
+		{"include_resource_types", config.IncludeResourceTypes},
+	} {
+		for _, pattern := range field.patterns {
+			if err := validateResourceTypePattern(pattern, resourceTypes); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", field.name, err))
+			}
+		}
+	}
+
+	for _, field := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"exclude_attributes", config.ExcludeAttributes},
+		{"warn_only_attributes", config.WarnOnlyAttributes},
+	} {
+		for _, pattern := range field.patterns {
+			if err := validateAttributePattern(pattern, resourceTypes, s); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", field.name, err))
+			}
+		}
+	}
+
+	for _, pattern := range sortedKeys(config.SeverityOverrides) {
+		if err := validateResourceTypePattern(pattern, resourceTypes); err != nil {
+			errs = append(errs, fmt.Errorf("severity_overrides: %w", err))
+			continue
+		}
+		severity, err := parseSeverity(config.SeverityOverrides[pattern])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("severity_overrides[%q]: %w", pattern, err))
+			continue
+		}
+		policy.severityOverrides[pattern] = severity
+	}
+
+	for _, name := range sortedKeys(config.CriticalitySeverities) {
+		tier, err := parseCriticality(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("criticality_severities: %w", err))
+			continue
+		}
+		severity, err := parseSeverity(config.CriticalitySeverities[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("criticality_severities[%q]: %w", name, err))
+			continue
+		}
+		policy.criticalitySeverities[tier] = severity
+	}
+
+	for _, resourceType := range sortedKeys(config.CriticalityOverrides) {
+		if err := validateResourceTypePattern(resourceType, resourceTypes); err != nil {
+			errs = append(errs, fmt.Errorf("criticality_overrides: %w", err))
+			continue
+		}
+		tier, err := parseCriticality(config.CriticalityOverrides[resourceType])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("criticality_overrides[%q]: %w", resourceType, err))
+			continue
+		}
+		policy.criticalityOverrides[resourceType] = tier
+	}
+
+	for i, environment := range config.Environments {
+		state, err := newEnvironmentState(environment)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("environment[%d]: %w", i, err))
+			continue
+		}
+		policy.environments = append(policy.environments, state)
+	}
+
+	for i, suppression := range config.Suppressions {
+		state, err := newSuppressionState(suppression, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("suppression[%d]: %w", i, err))
+			continue
+		}
+		policy.suppressions = append(policy.suppressions, state)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// newEnvironmentState validates an environment.
+func newEnvironmentState(environment forceNewEnvironment) (*environmentState, error) {
+	if len(environment.TagValues)+len(environment.Addresses)+len(environment.ResourceNames) == 0 {
+		return nil, fmt.Errorf("environment %q requires tag_values, addresses or resource_names", environment.Name)
+	}
+	for _, patterns := range [][]string{environment.TagValues, environment.ResourceNames} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("malformed pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	for _, pattern := range environment.Addresses {
+		if _, err := path.Match(addressPattern(pattern), ""); err != nil {
+			return nil, fmt.Errorf("malformed pattern %q: %w", pattern, err)
+		}
+	}
+	severity, err := parseSeverity(environment.Severity)
+	if err != nil {
+		return nil, err
+	}
+	return &environmentState{forceNewEnvironment: environment, severity: severity}, nil
+}
+
+// newSuppressionState validates a suppression and determines whether it has expired.
+// A suppression applies through the end of its expiry date (UTC).
+func newSuppressionState(suppression forceNewSuppression, now time.Time) (*suppressionState, error) {
+	if _, err := path.Match(addressPattern(suppression.Address), ""); err != nil {
+		return nil, fmt.Errorf("malformed address pattern %q: %w", suppression.Address, err)
+	}
+	for _, pattern := range suppression.Attributes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("malformed attribute pattern %q: %w", pattern, err)
+		}
+	}
+	if strings.TrimSpace(suppression.Justification) == "" {
+		return nil, fmt.Errorf("suppression for %q requires a justification", suppression.Address)
+	}
+
+	state := &suppressionState{forceNewSuppression: suppression}
+	if suppression.Expires != "" {
+		expires, err := time.Parse(suppressionDateLayout, suppression.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("expires %q is not a date in the form YYYY-MM-DD", suppression.Expires)
+		}
+		state.expired = !now.UTC().Before(expires.AddDate(0, 0, 1))
+	}
+	return state, nil
+}
+
+// validateResourceTypePattern checks that a glob pattern matches at least one resource type.
+func validateResourceTypePattern(pattern string, resourceTypes []string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("malformed pattern %q: %w", pattern, err)
+	}
+	for _, resourceType := range resourceTypes {
+		if matched, _ := path.Match(pattern, resourceType); matched {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown resource type %q", pattern)
+}
+
+// validateAttributePattern checks that a "<resource type>.<attribute path>" glob pattern
+// matches at least one attribute path in the schema.
+func validateAttributePattern(pattern string, resourceTypes []string, s *schema.Schema) error {
+	typePattern, attrPattern, ok := strings.Cut(pattern, ".")
+	if !ok || attrPattern == "" {
+		return fmt.Errorf("pattern %q must be in the form <resource type>.<attribute path>", pattern)
+	}
+	if err := validateResourceTypePattern(typePattern, resourceTypes); err != nil {
+		return err
+	}
+	if _, err := path.Match(attrPattern, ""); err != nil {
+		return fmt.Errorf("malformed pattern %q: %w", pattern, err)
+	}
+	for _, resourceType := range resourceTypes {
+		if matched, _ := path.Match(typePattern, resourceType); !matched {
+			continue
+		}
+		for _, attrPath := range s.GetAttributePaths(resourceType) {
+			if matched, _ := path.Match(attrPattern, attrPath); matched {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unknown attribute path %q", pattern)
+}
+
+// checksResourceType reports whether a resource type is checked.
+func (p *forceNewPolicy) checksResourceType(resourceType string) bool {
+	if len(p.config.IncludeResourceTypes) > 0 && !matchesAny(p.config.IncludeResourceTypes, resourceType) {
+		return false
+	}
+	return !matchesAny(p.config.ExcludeResourceTypes, resourceType)
+}
+
+// checksAttribute reports whether an attribute of a resource type is checked.
+func (p *forceNewPolicy) checksAttribute(resourceType, attrPath string) bool {
+	return !matchesAny(p.config.ExcludeAttributes, resourceType+"."+attrPath)
+}
+
+// severity returns the severity reported for a change to an attribute of a resource in
+// an environment, which may be nil. warn_only_attributes take precedence over
+// severity_overrides, then the environment, then the criticality tier of the resource type.
+// Unclassified resource types are reported as errors.
+func (p *forceNewPolicy) severity(resourceType, attrPath string, environment *environmentState) tflint.Severity {
+	if attrPath != "" && matchesAny(p.config.WarnOnlyAttributes, resourceType+"."+attrPath) {
+		return tflint.WARNING
+	}
+	if pattern, ok := bestPatternMatch(p.severityOverrides, resourceType); ok {
+		return p.severityOverrides[pattern]
+	}
+	if environment != nil {
+		return environment.severity
+	}
+	if tier := p.criticality(resourceType); tier != "" {
+		return p.criticalitySeverities[tier]
+	}
+	return tflint.ERROR
+}
+
+// environment returns the first environment a resource belongs to, or nil if it belongs to none.
+// The environment tag and name attribute are read from block, when they are statically known.
+func (p *forceNewPolicy) environment(address string, block *hclext.Block) *environmentState {
+	if len(p.environments) == 0 {
+		return nil
+	}
+
+	tagValue, hasTag := "", false
+	if tags, ok := attrValue(getAttributeByPath(block, "tags")); ok && tags.IsWhollyKnown() && !tags.IsNull() &&
+		(tags.Type().IsMapType() || tags.Type().IsObjectType()) {
+		for it := tags.ElementIterator(); it.Next(); {
+			key, value := it.Element()
+			if key.AsString() == p.environmentTag && value.Type() == cty.String && !value.IsNull() {
+				tagValue, hasTag = value.AsString(), true
+			}
+		}
+	}
+	name := evalAttr(getAttributeByPath(block, "name"))
+	hasName := !isUnresolvedValue(name)
+
+	for _, environment := range p.environments {
+		if hasTag && matchesAny(environment.TagValues, tagValue) ||
+			matchesAnyAddress(environment.Addresses, address) ||
+			hasName && matchesAny(environment.ResourceNames, name) {
+			return environment
+		}
+	}
+	return nil
+}
+
+// criticality returns the criticality tier of a resource type, or an empty tier if it is unclassified.
+// criticality_overrides take precedence over the built-in classification.
+func (p *forceNewPolicy) criticality(resourceType string) resourceCriticality {
+	if pattern, ok := bestPatternMatch(p.criticalityOverrides, resourceType); ok {
+		return p.criticalityOverrides[pattern]
+	}
+	return resourceCriticalities[resourceType]
+}
+
+// maskValue hides a displayed value of a Sensitive attribute. Placeholders such as
+// "<not set>" do not reveal the value and are kept.
+func (p *forceNewPolicy) maskValue(value string) string {
+	if value == "" || isUnresolvedValue(value) {
+		return value
+	}
+	if !p.config.HashSensitiveValues {
+		return "<sensitive>"
+	}
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("<sensitive:%x>", sum[:4])
+}
+
+// bestPatternMatch returns the most specific pattern in m that matches name: an exact match
+// takes precedence over patterns, and longer patterns over shorter ones.
+func bestPatternMatch[V any](m map[string]V, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	best, found := "", false
+	for pattern := range m {
+		if matched, _ := path.Match(pattern, name); !matched {
+			continue
+		}
+		if !found || len(pattern) > len(best) || (len(pattern) == len(best) && pattern < best) {
+			best, found = pattern, true
+		}
+	}
+	return best, found
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// suppressed reports whether a finding for an attribute of a resource is suppressed,
+// and marks the matching suppressions as used. An empty attrPath means the finding concerns
+// the resource as a whole, which only suppressions without attributes match.
+func (p *forceNewPolicy) suppressed(address, attrPath string) bool {
+	suppressed := false
+	for _, suppression := range p.suppressions {
+		if suppression.expired {
+			continue
+		}
+		if !matchesAnyAddress([]string{suppression.Address}, address) {
+			continue
+		}
+		if len(suppression.Attributes) > 0 && (attrPath == "" || !matchesAny(suppression.Attributes, attrPath)) {
+			continue
+		}
+		suppression.matched = true
+		suppressed = true
+	}
+	return suppressed
+}
+
+// suppressionReport describes suppressions that expired or did not match any finding,
+// so they can be cleaned up from the configuration.
+func (p *forceNewPolicy) suppressionReport() []suppressionNotice {
+	var report []suppressionNotice
+	for _, suppression := range p.suppressions {
+		target := fmt.Sprintf("%q", suppression.Address)
+		if len(suppression.Attributes) > 0 {
+			target += fmt.Sprintf(" (attributes: %s)", strings.Join(suppression.Attributes, ", "))
+		}
+		switch {
+		case suppression.expired:
+			report = append(report, suppressionNotice{
+				Message: fmt.Sprintf(
+					"Suppression for %s expired on %s; its findings are reported again. Remove or renew it.",
+					target, suppression.Expires,
+				),
+				Range: suppression.Range,
+			})
+		case !suppression.matched:
+			report = append(report, suppressionNotice{
+				Message: fmt.Sprintf(
+					"Suppression for %s did not match any finding. Remove it if it is no longer needed.",
+					target,
+				),
+				Range: suppression.Range,
+			})
+		}
+	}
+	return report
+}
+
+// matchesAnyAddress reports whether a resource address matches any of the glob patterns.
+// Brackets in the patterns are instance keys, not character classes.
+func matchesAnyAddress(patterns []string, address string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(addressPattern(pattern), address); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// addressPattern escapes the brackets of instance keys in an address pattern, so
+// `module.x["a"].*` matches the instances of module.x["a"] instead of reading ["a"]
+// as a character class.
+func addressPattern(pattern string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(pattern)
+}
+
+// matchesAny reports whether a name matches any of the glob patterns.
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
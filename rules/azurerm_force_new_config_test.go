@@ -0,0 +1,575 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// configRunner is a test runner that decodes rule configuration from HCL.
+// helper.Runner does not support rule configuration.
+type configRunner struct {
+	*helper.Runner
+	config string
+}
+
+// DecodeRuleConfig decodes the runner's configuration into target.
+func (r *configRunner) DecodeRuleConfig(_ string, target any) error {
+	file, diags := hclsyntax.ParseConfig([]byte(r.config), ".tfbreak.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return diags
+	}
+	if diags := gohcl.DecodeBody(file.Body, nil, target); diags.HasErrors() {
+		return diags
+	}
+	return nil
+}
+
+// forceNewConfigFixture changes ForceNew attributes of a resource group and a storage account.
+func forceNewConfigFixture(t *testing.T, config string) *configRunner {
+	t.Helper()
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "westeurope"
+}
+
+resource "azurerm_storage_account" "example" {
+    name         = "mystorage"
+    location     = "westeurope"
+    account_kind = "StorageV2"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "eastus"
+}
+
+resource "azurerm_storage_account" "example" {
+    name         = "mystorage"
+    location     = "westeurope"
+    account_kind = "BlockBlobStorage"
+}`,
+		},
+	)
+	return &configRunner{Runner: runner, config: config}
+}
+
+func TestForceNewConfig_Filters(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"no config", "", []string{`"location"`, `"account_kind"`}},
+		{"exclude resource types", `exclude_resource_types = ["azurerm_storage_*"]`, []string{`"location"`}},
+		{"include resource types", `include_resource_types = ["azurerm_storage_account"]`, []string{`"account_kind"`}},
+		{"exclude attributes", `exclude_attributes = ["*.location"]`, []string{`"account_kind"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			runner := forceNewConfigFixture(t, tt.config)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			if len(runner.Issues) != len(tt.want) {
+				t.Fatalf("Expected %d issues, got %d: %v", len(tt.want), len(runner.Issues), runner.Issues)
+			}
+			for _, want := range tt.want {
+				found := false
+				for _, issue := range runner.Issues {
+					if strings.Contains(issue.Message, want) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Expected an issue for %s, got %v", want, runner.Issues)
+				}
+			}
+		})
+	}
+}
+
+func TestForceNewConfig_Severity(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+	runner := forceNewConfigFixture(t, `
+severity_overrides   = { "azurerm_resource_group" = "notice" }
+warn_only_attributes = ["azurerm_storage_account.account_kind"]
+`)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	for _, issue := range runner.Issues {
+		want := tflint.WARNING
+		if strings.Contains(issue.Message, "azurerm_resource_group") {
+			want = tflint.NOTICE
+		}
+		if issue.Rule.Severity() != want {
+			t.Errorf("Expected %v severity for %q, got %v", want, issue.Message, issue.Rule.Severity())
+		}
+	}
+}
+
+func TestForceNewConfig_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"unknown resource type", `exclude_resource_types = ["azurerm_resource_grup"]`, `unknown resource type "azurerm_resource_grup"`},
+		{"unknown attribute path", `exclude_attributes = ["azurerm_resource_group.locaton"]`, `unknown attribute path "azurerm_resource_group.locaton"`},
+		{"attribute without type", `warn_only_attributes = ["location"]`, "must be in the form"},
+		{"unknown severity", `severity_overrides = { "azurerm_resource_group" = "critical" }`, `unknown severity "critical"`},
+		{"malformed pattern", `include_resource_types = ["azurerm_["]`, "malformed pattern"},
+		{"unknown tier", `criticality_severities = { critical = "ERROR" }`, `unknown criticality "critical"`},
+		{"unknown tier override", `criticality_overrides = { "azurerm_resource_group" = "cheap" }`, `unknown criticality "cheap"`},
+		{"environment without patterns", `
+environment {
+    name     = "dev"
+    severity = "WARNING"
+}`, `environment "dev" requires tag_values, addresses or resource_names`},
+		{"environment severity", `
+environment {
+    tag_values = ["dev"]
+    severity   = "low"
+}`, `unknown severity "low"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			runner := forceNewConfigFixture(t, tt.config)
+
+			err := rule.Check(runner)
+			if err == nil {
+				t.Fatal("Expected Check to return a validation error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error to contain %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestForceNewConfig_Criticality(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		wantRG      tflint.Severity
+		wantStorage tflint.Severity
+	}{
+		{"built-in classification", "", tflint.ERROR, tflint.ERROR},
+		{"tier severity", `criticality_severities = { stateful = "warning" }`, tflint.ERROR, tflint.WARNING},
+		{"reclassified resource type", `criticality_overrides = { "azurerm_resource_group" = "stateless" }`, tflint.WARNING, tflint.ERROR},
+		{"severity override wins", `
+criticality_severities = { stateful = "notice" }
+severity_overrides     = { "azurerm_storage_account" = "error" }
+`, tflint.ERROR, tflint.ERROR},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			runner := forceNewConfigFixture(t, tt.config)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			if len(runner.Issues) != 2 {
+				t.Fatalf("Expected 2 issues, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			for _, issue := range runner.Issues {
+				want := tt.wantRG
+				if strings.Contains(issue.Message, "azurerm_storage_account") {
+					want = tt.wantStorage
+					if !strings.Contains(issue.Message, "Data stored in the resource is lost") {
+						t.Errorf("Expected the stateful impact in the message, got %q", issue.Message)
+					}
+				}
+				if issue.Rule.Severity() != want {
+					t.Errorf("Expected %v severity for %q, got %v", want, issue.Message, issue.Rule.Severity())
+				}
+			}
+		})
+	}
+}
+
+func TestForceNewPolicy_SeverityPrecedence(t *testing.T) {
+	policy, err := newForceNewPolicy(forceNewConfig{
+		SeverityOverrides: map[string]string{
+			"azurerm_*":               "NOTICE",
+			"azurerm_storage_*":       "WARNING",
+			"azurerm_storage_account": "ERROR",
+		},
+	}, NewAzurermForceNewRule().schema, time.Now())
+	if err != nil {
+		t.Fatalf("newForceNewPolicy returned error: %v", err)
+	}
+
+	for resourceType, want := range map[string]tflint.Severity{
+		"azurerm_storage_account": tflint.ERROR,
+		"azurerm_storage_blob":    tflint.WARNING,
+		"azurerm_resource_group":  tflint.NOTICE,
+	} {
+		if got := policy.severity(resourceType, "name", nil); got != want {
+			t.Errorf("severity(%q) = %v, want %v", resourceType, got, want)
+		}
+	}
+}
+
+func TestForceNewConfig_Suppressions(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+	rule.now = func() time.Time { return time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC) }
+	runner := forceNewConfigFixture(t, `
+suppression {
+    address       = "azurerm_resource_group.*"
+    expires       = "2026-06-30"
+    justification = "Region move approved"
+}
+
+suppression {
+    address       = "azurerm_storage_account.example"
+    attributes    = ["location"]
+    justification = "Only the region is accepted"
+}
+
+suppression {
+    address       = "module.legacy.*"
+    justification = "Vendored module"
+}
+
+suppression {
+    address       = "azurerm_storage_account.*"
+    expires       = "2026-06-29"
+    justification = "Expired"
+}
+`)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// The resource group finding is suppressed until the end of its expiry date, and the
+	// storage account suppression does not cover account_kind.
+	want := []string{
+		`Changing "account_kind" forces recreation of azurerm_storage_account.example`,
+		`Suppression for "azurerm_storage_account.example" (attributes: location) did not match any finding`,
+		`Suppression for "module.legacy.*" did not match any finding`,
+		`Suppression for "azurerm_storage_account.*" expired on 2026-06-29`,
+	}
+	if len(runner.Issues) != len(want) {
+		t.Fatalf("Expected %d issues, got %d: %v", len(want), len(runner.Issues), runner.Issues)
+	}
+	for i, issue := range runner.Issues {
+		if !strings.Contains(issue.Message, want[i]) {
+			t.Errorf("Expected issue %d to contain %q, got %q", i, want[i], issue.Message)
+		}
+		if i > 0 && issue.Rule.Severity() != tflint.NOTICE {
+			t.Errorf("Expected suppression report to be a NOTICE, got %v", issue.Rule.Severity())
+		}
+	}
+}
+
+func TestForceNewConfig_SuppressionRange(t *testing.T) {
+	config := `
+suppression {
+    address       = "module.legacy.*"
+    justification = "Vendored module"
+}
+`
+	rule := NewAzurermForceNewRule()
+	rule.readFile = func(name string) ([]byte, error) {
+		if name != ".tfbreak.hcl" {
+			t.Fatalf("unexpected file %q", name)
+		}
+		return []byte("rule \"azurerm_force_new\" {\n    enabled = true\n" + config + "}\n"), nil
+	}
+	runner := forceNewConfigFixture(t, config)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// The unused suppression is reported at its block in the configuration file
+	issue := runner.Issues[len(runner.Issues)-1]
+	if !strings.Contains(issue.Message, `Suppression for "module.legacy.*"`) {
+		t.Fatalf("Expected the last issue to report the suppression, got %q", issue.Message)
+	}
+	if issue.Range.Filename != ".tfbreak.hcl" || issue.Range.Start.Line != 4 {
+		t.Errorf("Expected the issue at .tfbreak.hcl:4, got %s", issue.Range)
+	}
+}
+
+func TestForceNewPolicy_AddressInstanceKeys(t *testing.T) {
+	policy, err := newForceNewPolicy(forceNewConfig{
+		Suppressions: []forceNewSuppression{
+			{Address: `module.network["hub"].*`, Justification: "Hub network is rebuilt"},
+		},
+		Environments: []forceNewEnvironment{
+			{Name: "dev", Addresses: []string{`module.app[*].*`}, Severity: "WARNING"},
+		},
+	}, NewAzurermForceNewRule().schema, time.Now())
+	if err != nil {
+		t.Fatalf("newForceNewPolicy returned error: %v", err)
+	}
+
+	// Brackets are instance keys, not character classes
+	for address, want := range map[string]bool{
+		`module.network["hub"].azurerm_virtual_network.this`:   true,
+		`module.network["spoke"].azurerm_virtual_network.this`: false,
+		`module.networkh.azurerm_virtual_network.this`:         false,
+	} {
+		if got := policy.suppressed(address, "location"); got != want {
+			t.Errorf("suppressed(%q) = %v, want %v", address, got, want)
+		}
+	}
+	if policy.environment(`module.app[0].azurerm_linux_web_app.this`, nil) == nil {
+		t.Error("Expected module.app[*].* to match an instance of module.app")
+	}
+}
+
+func TestForceNewConfig_SuppressionValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"missing justification", `
+suppression {
+    address = "azurerm_resource_group.*"
+}`, "requires a justification"},
+		{"invalid expiry", `
+suppression {
+    address       = "azurerm_resource_group.*"
+    expires       = "31/12/2026"
+    justification = "Region move"
+}`, "not a date in the form YYYY-MM-DD"},
+		{"malformed address", `
+suppression {
+    address       = "azurerm_\\"
+    justification = "Region move"
+}`, "malformed address pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			runner := forceNewConfigFixture(t, tt.config)
+
+			err := rule.Check(runner)
+			if err == nil {
+				t.Fatal("Expected Check to return a validation error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error to contain %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+}
+
+func TestForceNewConfig_Environments(t *testing.T) {
+	config := `
+environment {
+    name       = "dev"
+    tag_values = ["dev", "test*"]
+    severity   = "warning"
+}
+
+environment {
+    name           = "sandbox"
+    addresses      = ["azurerm_resource_group.sandbox_*"]
+    resource_names = ["*-sbx"]
+    severity       = "notice"
+}
+`
+	resources := func(location string) string {
+		return `
+resource "azurerm_resource_group" "dev" {
+    name     = "app-dev"
+    location = "` + location + `"
+    tags     = { environment = "dev" }
+}
+
+resource "azurerm_resource_group" "prod" {
+    name     = "app-prod"
+    location = "` + location + `"
+    tags     = { environment = "prod" }
+}
+
+resource "azurerm_resource_group" "sandbox_1" {
+    name     = "app-1"
+    location = "` + location + `"
+}
+
+resource "azurerm_resource_group" "named" {
+    name     = "app-sbx"
+    location = "` + location + `"
+}
+
+resource "azurerm_resource_group" "tagged" {
+    name     = "app-test"
+    location = "` + location + `"
+    tags     = { environment = "testing", team = "platform" }
+}`
+	}
+
+	rule := NewAzurermForceNewRule()
+	runner := &configRunner{
+		Runner: helper.TestRunner(t,
+			map[string]string{"main.tf": resources("westeurope")},
+			map[string]string{"main.tf": resources("eastus")},
+		),
+		config: config,
+	}
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	want := map[string]tflint.Severity{
+		"azurerm_resource_group.dev":       tflint.WARNING,
+		"azurerm_resource_group.prod":      tflint.ERROR,
+		"azurerm_resource_group.sandbox_1": tflint.NOTICE,
+		"azurerm_resource_group.named":     tflint.NOTICE,
+		"azurerm_resource_group.tagged":    tflint.WARNING,
+	}
+	if len(runner.Issues) != len(want) {
+		t.Fatalf("Expected %d issues, got %d: %v", len(want), len(runner.Issues), runner.Issues)
+	}
+	for _, issue := range runner.Issues {
+		for address, severity := range want {
+			if strings.Contains(issue.Message, address+" ") && issue.Rule.Severity() != severity {
+				t.Errorf("Expected %v severity for %s, got %v", severity, address, issue.Rule.Severity())
+			}
+		}
+	}
+}
+
+func TestForceNewConfig_EnvironmentTag(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+	runner := &configRunner{
+		Runner: helper.TestRunner(t,
+			map[string]string{"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "westeurope"
+    tags     = { stage = "dev" }
+}`},
+			map[string]string{"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "eastus"
+    tags     = { stage = "dev" }
+}`},
+		),
+		config: `
+environment_tag = "stage"
+
+environment {
+    tag_values = ["dev"]
+    severity   = "warning"
+}
+`,
+	}
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if runner.Issues[0].Rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected WARNING severity, got %v", runner.Issues[0].Rule.Severity())
+	}
+}
+
+func TestForceNewConfig_SensitiveValues(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_mssql_server": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true},
+						"administrator_login_password": {"type": "string", "optional": true, "force_new": true, "sensitive": true}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		wantOld string
+		wantNew string
+	}{
+		{"redacted", "", "<sensitive>", "<sensitive>"},
+		{"hashed", "hash_sensitive_values = true", "<sensitive:5d865dea>", "<sensitive:fc97bb52>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &AzurermForceNewRule{schema: s, now: time.Now}
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_mssql_server" "example" {
+    name                         = "sql"
+    administrator_login_password = "old-secret"
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_mssql_server" "example" {
+    name                         = "sql"
+    administrator_login_password = "new-secret"
+}`,
+				},
+			)
+
+			if err := rule.Check(&configRunner{Runner: runner, config: tt.config}); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+
+			message := runner.Issues[0].Message
+			if strings.Contains(message, "secret") {
+				t.Errorf("Expected sensitive values to be masked, got %q", message)
+			}
+			if want := "(old: " + tt.wantOld + ", new: " + tt.wantNew + ")"; !strings.Contains(message, want) {
+				t.Errorf("Expected message to contain %q, got %q", want, message)
+			}
+			if f := parseFinding(t, message); f.OldValue != tt.wantOld || f.NewValue != tt.wantNew {
+				t.Errorf("Expected masked values in the payload, got %q and %q", f.OldValue, f.NewValue)
+			}
+		})
+	}
+}
@@ -3,10 +3,12 @@ package rules
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jokarl/tfbreak-plugin-sdk/helper"
 	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
 	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -253,12 +255,232 @@ resource "azurerm_resource_group" "example" {
 		t.Fatalf("Check returned error: %v", err)
 	}
 
-	// Adding a ForceNew attribute should be detected
+	// Adding a ForceNew attribute without a provider default should be detected
 	if len(runner.Issues) == 0 {
 		t.Error("Expected issue to be emitted when ForceNew attribute is added")
 	}
 }
 
+func TestForceNew_IgnoreChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		ignore string
+	}{
+		{"attribute", "[location]"},
+		{"all", "all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "westeurope"
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "eastus"
+    lifecycle {
+        ignore_changes = ` + tt.ignore + `
+    }
+}`,
+				},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			// Terraform does not recreate the resource for ignored attributes
+			helper.AssertNoIssues(t, runner.Issues)
+		})
+	}
+}
+
+func TestForceNew_IgnoreChangesUnreadable(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+
+	// Over gRPC, the references in ignore_changes cannot be read
+	runner := &grpcRunner{helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "westeurope"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "eastus"
+    lifecycle {
+        ignore_changes = [location]
+    }
+}`,
+		},
+	)}
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected WARNING severity, got %v", issue.Rule.Severity())
+	}
+	if !strings.Contains(issue.Message, "may be ignored by ignore_changes") {
+		t.Errorf("Expected message to mention ignore_changes, got %q", issue.Message)
+	}
+}
+
+func TestForceNew_ReplaceTriggeredBy(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldLocation string
+		newLocation string
+		oldInput    string
+		newInput    string
+		wantIssues  int
+		wantText    string
+	}{
+		{"referenced resource recreated", "westeurope", "eastus", "v1", "v1", 3, "references azurerm_resource_group.example, which is recreated"},
+		{"referenced attribute changed", "westeurope", "westeurope", "v1", "v2", 2, "references terraform_data.revision.input, which changes (old: v1, new: v2)"},
+		{"nothing changed", "westeurope", "westeurope", "v1", "v1", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+
+			config := func(location, input string) string {
+				return `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "` + location + `"
+}
+
+resource "terraform_data" "revision" {
+    input = "` + input + `"
+}
+
+resource "azurerm_user_assigned_identity" "example" {
+    name = "id"
+    lifecycle {
+        replace_triggered_by = [azurerm_resource_group.example, terraform_data.revision.input]
+    }
+}
+
+resource "azurerm_role_assignment" "example" {
+    scope = "/subscriptions/000"
+    lifecycle {
+        replace_triggered_by = [azurerm_user_assigned_identity.example.principal_id]
+    }
+}`
+			}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": config(tt.oldLocation, tt.oldInput)},
+				map[string]string{"main.tf": config(tt.newLocation, tt.newInput)},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			// A triggered replacement cascades to resources that reference the recreated one
+			if len(runner.Issues) != tt.wantIssues {
+				t.Fatalf("Expected %d issues, got %d: %v", tt.wantIssues, len(runner.Issues), runner.Issues)
+			}
+			if tt.wantText == "" {
+				return
+			}
+			found := false
+			for _, issue := range runner.Issues {
+				if strings.Contains(issue.Message, tt.wantText) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected an issue containing %q, got %v", tt.wantText, runner.Issues)
+			}
+		})
+	}
+}
+
+func TestForceNew_Remediation(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldName  string
+		newName  string
+		oldKind  string
+		newKind  string
+		wantText []string
+	}{
+		{
+			"rename", "oldstorage", "newstorage", "StorageV2", "StorageV2",
+			[]string{
+				"rename the resource block to azurerm_storage_account.newstorage",
+				"from = azurerm_storage_account.example",
+				"to = azurerm_storage_account.newstorage",
+				`id = "/subscriptions/{subscription_id}/resourceGroups/my-rg/providers/Microsoft.Storage/storageAccounts/newstorage"`,
+			},
+		},
+		{
+			"other attribute", "oldstorage", "oldstorage", "StorageV2", "BlobStorage",
+			[]string{"Revert the change, or add account_kind to lifecycle.ignore_changes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+
+			config := func(name, kind string) string {
+				return `
+resource "azurerm_storage_account" "example" {
+    name                = "` + name + `"
+    resource_group_name = "my-rg"
+    location            = "westeurope"
+    account_tier        = "Standard"
+    account_kind        = "` + kind + `"
+}`
+			}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": config(tt.oldName, tt.oldKind)},
+				map[string]string{"main.tf": config(tt.newName, tt.newKind)},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			for _, text := range tt.wantText {
+				if !strings.Contains(runner.Issues[0].Message, text) {
+					t.Errorf("Expected message to contain %q, got %q", text, runner.Issues[0].Message)
+				}
+			}
+			if strings.Contains(runner.Issues[0].Message, "moved block or creating") {
+				t.Errorf("Expected tailored remediation instead of the generic one, got %q", runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // Unit tests for buildBodySchema and getAttributeByPath
 // =============================================================================
@@ -458,6 +680,11 @@ func TestFormatCtyValue(t *testing.T) {
 		{"bool false", cty.BoolVal(false), "false"},
 		{"null", cty.NullVal(cty.String), "<null>"},
 		{"unknown", cty.UnknownVal(cty.String), "<unknown>"},
+		{"list", cty.ListVal([]cty.Value{cty.StringVal("b"), cty.StringVal("a")}), `["b", "a"]`},
+		{"set", cty.SetVal([]cty.Value{cty.NumberIntVal(443), cty.NumberIntVal(80)}), "[80, 443]"},
+		{"empty list", cty.ListValEmpty(cty.String), "[]"},
+		{"map", cty.MapVal(map[string]cty.Value{"env": cty.StringVal("prod"), "app": cty.StringVal("web")}), `{app = "web", env = "prod"}`},
+		{"object", cty.ObjectVal(map[string]cty.Value{"name": cty.StringVal("rule"), "priority": cty.NumberIntVal(100), "note": cty.NullVal(cty.String)}), `{name = "rule", note = null, priority = 100}`},
 	}
 
 	for _, tt := range tests {
@@ -522,3 +749,402 @@ func TestEvalAttr(t *testing.T) {
 		}
 	})
 }
+
+func TestForceNew_ConditionalForceNew(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_managed_disk": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true},
+						"disk_size_gb": {"type": "number", "optional": true, "force_new_if": [{"change": "decrease"}]},
+						"account_kind": {"type": "string", "optional": true, "force_new_if": [
+							{"change": "transition", "from": ["Storage"], "to": ["BlobStorage", "FileStorage"]},
+							{"change": "transition", "from": ["StorageV2", "BlobStorage", "FileStorage"]}
+						]}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		oldAttr    string
+		newAttr    string
+		wantIssues int
+	}{
+		{"size decrease", "disk_size_gb = 128", "disk_size_gb = 64", 1},
+		{"size increase", "disk_size_gb = 64", "disk_size_gb = 128", 0},
+		{"size unchanged", "disk_size_gb = 64", "disk_size_gb = 64", 0},
+		{"size set", "", "disk_size_gb = 64", 1},
+		{"size from variable", "disk_size_gb = 128", "disk_size_gb = var.size", 1},
+		{"kind upgrade", `account_kind = "Storage"`, `account_kind = "StorageV2"`, 0},
+		{"kind upgrade case-insensitive", `account_kind = "storage"`, `account_kind = "storagev2"`, 0},
+		{"kind to blob", `account_kind = "Storage"`, `account_kind = "BlobStorage"`, 1},
+		{"kind from v2", `account_kind = "StorageV2"`, `account_kind = "Storage"`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &AzurermForceNewRule{schema: s, now: time.Now}
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_managed_disk" "example" {
+    name = "disk"
+    ` + tt.oldAttr + `
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_managed_disk" "example" {
+    name = "disk"
+    ` + tt.newAttr + `
+}`,
+				},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != tt.wantIssues {
+				t.Errorf("Expected %d issues, got %d: %v", tt.wantIssues, len(runner.Issues), runner.Issues)
+			}
+		})
+	}
+}
+
+func TestForceNew_ManagedDiskSizeEmbeddedSchema(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldSize    string
+		newSize    string
+		wantIssues int
+	}{
+		{"decrease", "128", "64", 1},
+		{"increase", "64", "128", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The condition comes from the embedded overlay, not from a fixture
+			rule := NewAzurermForceNewRule()
+
+			config := func(size string) string {
+				return `
+resource "azurerm_managed_disk" "example" {
+  name                 = "disk"
+  resource_group_name  = "rg"
+  location             = "westeurope"
+  storage_account_type = "Premium_LRS"
+  create_option        = "Empty"
+  disk_size_gb         = ` + size + `
+}`
+			}
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": config(tt.oldSize)},
+				map[string]string{"main.tf": config(tt.newSize)},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != tt.wantIssues {
+				t.Fatalf("Expected %d issues, got %d: %v", tt.wantIssues, len(runner.Issues), runner.Issues)
+			}
+			if tt.wantIssues > 0 {
+				if f := parseFinding(t, runner.Issues[0].Message); f.Category != findingForceNew || f.Attribute != "disk_size_gb" {
+					t.Errorf("Expected a ForceNew finding for disk_size_gb, got %+v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestForceNewConditionHolds(t *testing.T) {
+	number := func(v int64) *hclext.Attribute {
+		return &hclext.Attribute{Name: "test", Value: cty.NumberIntVal(v)}
+	}
+	str := func(v string) *hclext.Attribute {
+		return &hclext.Attribute{Name: "test", Value: cty.StringVal(v)}
+	}
+	decrease := schema.ForceNewCondition{Change: schema.ForceNewDecrease}
+	increase := schema.ForceNewCondition{Change: schema.ForceNewIncrease}
+	upgrade := schema.ForceNewCondition{Change: schema.ForceNewTransition, From: []string{"Basic"}, To: []string{"Standard"}}
+
+	tests := []struct {
+		name      string
+		condition schema.ForceNewCondition
+		oldAttr   *hclext.Attribute
+		newAttr   *hclext.Attribute
+		want      bool
+	}{
+		{"decrease holds", decrease, number(10), number(5), true},
+		{"decrease does not hold", decrease, number(5), number(10), false},
+		{"decrease of numeric string", decrease, str("10"), str("5"), true},
+		{"increase holds", increase, number(5), number(10), true},
+		{"increase does not hold", increase, number(10), number(5), false},
+		{"increase from unknown", increase, &hclext.Attribute{Name: "test", Value: cty.UnknownVal(cty.Number)}, number(5), true},
+		{"decrease to unset", decrease, number(10), nil, true},
+		{"decrease of non-number", decrease, str("large"), str("small"), true},
+		{"transition holds", upgrade, str("Basic"), str("Standard"), true},
+		{"transition from other value", upgrade, str("Premium"), str("Standard"), false},
+		{"transition to other value", upgrade, str("Basic"), str("Premium"), false},
+		{"transition from unset", upgrade, nil, str("Standard"), true},
+		{"transition to any value", schema.ForceNewCondition{Change: schema.ForceNewTransition, From: []string{"Basic"}}, str("Basic"), str("Premium"), true},
+		{"unknown change", schema.ForceNewCondition{Change: "resize"}, number(5), number(10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forceNewConditionHolds(tt.condition, tt.oldAttr, tt.newAttr); got != tt.want {
+				t.Errorf("forceNewConditionHolds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForceNew_OneWay(t *testing.T) {
+	tests := []struct {
+		name         string
+		oldKind      string
+		newKind      string
+		wantSeverity tflint.Severity
+		wantCategory findingCategory
+		wantText     string
+	}{
+		{"in-place upgrade", "Storage", "StorageV2", tflint.WARNING, findingOneWay, "is applied in place, but cannot be reverted"},
+		{"downgrade", "StorageV2", "Storage", tflint.ERROR, findingForceNew, "forces recreation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+
+			config := func(kind string) string {
+				return `
+resource "azurerm_storage_account" "example" {
+    name                = "storage"
+    resource_group_name = "my-rg"
+    location            = "westeurope"
+    account_tier        = "Standard"
+    account_kind        = "` + kind + `"
+}`
+			}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": config(tt.oldKind)},
+				map[string]string{"main.tf": config(tt.newKind)},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+
+			issue := runner.Issues[0]
+			if issue.Rule.Severity() != tt.wantSeverity {
+				t.Errorf("Expected severity %v, got %v", tt.wantSeverity, issue.Rule.Severity())
+			}
+			if !strings.Contains(issue.Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, issue.Message)
+			}
+			f := parseFinding(t, issue.Message)
+			if f.Category != tt.wantCategory || f.Attribute != "account_kind" {
+				t.Errorf("Expected a %s finding for account_kind, got %+v", tt.wantCategory, f)
+			}
+			if f.Explanation == "" || !strings.Contains(issue.Message, f.Explanation) {
+				t.Errorf("Expected the overlay explanation in the message and payload, got %q", issue.Message)
+			}
+		})
+	}
+}
+
+func TestForceNew_DataLoss(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_example": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true},
+						"size_gb": {"type": "number", "optional": true, "force_new": true, "data_loss": true}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+	rule := &AzurermForceNewRule{schema: s, now: time.Now}
+
+	runner := helper.TestRunner(t,
+		map[string]string{"main.tf": `
+resource "azurerm_example" "example" {
+    name    = "old"
+    size_gb = 64
+}`},
+		map[string]string{"main.tf": `
+resource "azurerm_example" "example" {
+    name    = "new"
+    size_gb = 32
+}`},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+
+	dataLossImpact := criticalityImpacts[criticalityStateful]
+	for _, issue := range runner.Issues {
+		f := parseFinding(t, issue.Message)
+		wantDataLoss := f.Attribute == "size_gb"
+		if f.DataLoss != wantDataLoss {
+			t.Errorf("Expected data_loss %v for %s, got %v", wantDataLoss, f.Attribute, f.DataLoss)
+		}
+		if strings.Contains(issue.Message, dataLossImpact) != wantDataLoss {
+			t.Errorf("Expected the data loss impact only for size_gb, got %q", issue.Message)
+		}
+	}
+}
+
+func TestEvalTypedAttr(t *testing.T) {
+	tuple := cty.TupleVal([]cty.Value{cty.StringVal("2"), cty.StringVal("1"), cty.StringVal("1")})
+
+	tests := []struct {
+		name     string
+		attr     *hclext.Attribute
+		ty       cty.Type
+		expected string
+	}{
+		{"nil attribute", nil, cty.String, "<not set>"},
+		{"untyped tuple", &hclext.Attribute{Name: "zones", Value: tuple}, cty.DynamicPseudoType, `["2", "1", "1"]`},
+		{"list", &hclext.Attribute{Name: "zones", Value: tuple}, cty.List(cty.String), `["2", "1", "1"]`},
+		{"set", &hclext.Attribute{Name: "zones", Value: tuple}, cty.Set(cty.String), `["1", "2"]`},
+		{"set of numbers", &hclext.Attribute{Name: "ports", Value: tuple}, cty.Set(cty.Number), "[1, 2]"},
+		{"number from string", &hclext.Attribute{Name: "size", Value: cty.StringVal("64")}, cty.Number, "64"},
+		{"bool from string", &hclext.Attribute{Name: "enabled", Value: cty.StringVal("true")}, cty.Bool, "true"},
+		{"inconvertible", &hclext.Attribute{Name: "size", Value: cty.StringVal("large")}, cty.Number, "large"},
+		{"dynamic", &hclext.Attribute{Name: "size"}, cty.Number, "<dynamic>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evalTypedAttr(tt.attr, tt.ty); got != tt.expected {
+				t.Errorf("evalTypedAttr() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestForceNew_DeclaredType(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_example": {
+				"block": {
+					"attributes": {
+						"zones": {"type": ["set", "string"], "optional": true, "force_new": true},
+						"dns_servers": {"type": ["list", "string"], "optional": true, "force_new": true},
+						"size_gb": {"type": "number", "optional": true, "force_new": true}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		oldAttr    string
+		newAttr    string
+		wantIssues []string
+	}{
+		{"set reordered", `zones = ["1", "2"]`, `zones = ["2", "1"]`, nil},
+		{"set with duplicate", `zones = ["1", "2"]`, `zones = ["2", "1", "2"]`, nil},
+		{"set changed", `zones = ["1", "2"]`, `zones = ["1", "3"]`, []string{`(old: ["1", "2"], new: ["1", "3"])`}},
+		{"list reordered", `dns_servers = ["10.0.0.4", "10.0.0.5"]`, `dns_servers = ["10.0.0.5", "10.0.0.4"]`, []string{`(old: ["10.0.0.4", "10.0.0.5"], new: ["10.0.0.5", "10.0.0.4"])`}},
+		{"number as string", `size_gb = 64`, `size_gb = "64"`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &AzurermForceNewRule{schema: s, now: time.Now}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": "resource \"azurerm_example\" \"example\" {\n  " + tt.oldAttr + "\n}"},
+				map[string]string{"main.tf": "resource \"azurerm_example\" \"example\" {\n  " + tt.newAttr + "\n}"},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != len(tt.wantIssues) {
+				t.Fatalf("Expected %d issues, got %d: %v", len(tt.wantIssues), len(runner.Issues), runner.Issues)
+			}
+			for i, want := range tt.wantIssues {
+				if !strings.Contains(runner.Issues[i].Message, want) {
+					t.Errorf("Expected message to contain %q, got %q", want, runner.Issues[i].Message)
+				}
+			}
+		})
+	}
+}
+
+func TestForceNew_ProviderDefault(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldKind    string
+		newKind    string
+		wantIssues int
+		wantText   string
+	}{
+		{"default made explicit", "", `account_kind = "StorageV2"`, 0, ""},
+		{"explicit default removed", `account_kind = "StorageV2"`, "", 0, ""},
+		{"changed from default", "", `account_kind = "BlobStorage"`, 1, "(old: StorageV2 (default), new: BlobStorage)"},
+		{"changed to default", `account_kind = "BlobStorage"`, "", 1, "(old: BlobStorage, new: StorageV2 (default))"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+
+			config := func(kind string) string {
+				return `
+resource "azurerm_storage_account" "example" {
+    name                = "storage"
+    resource_group_name = "my-rg"
+    location            = "westeurope"
+    account_tier        = "Standard"
+    ` + kind + `
+}`
+			}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": config(tt.oldKind)},
+				map[string]string{"main.tf": config(tt.newKind)},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != tt.wantIssues {
+				t.Fatalf("Expected %d issues, got %d: %v", tt.wantIssues, len(runner.Issues), runner.Issues)
+			}
+			if tt.wantIssues > 0 && !strings.Contains(runner.Issues[0].Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, runner.Issues[0].Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// AzurermLifecycleProtectionRemovedRule detects weakened lifecycle protections on azurerm resources:
+// prevent_destroy being removed, and attributes dropped from ignore_changes.
+type AzurermLifecycleProtectionRemovedRule struct {
+	tflint.DefaultRule
+	schema *schema.Schema
+}
+
+// NewAzurermLifecycleProtectionRemovedRule creates a new lifecycle protection rule.
+func NewAzurermLifecycleProtectionRemovedRule() *AzurermLifecycleProtectionRemovedRule {
+	return &AzurermLifecycleProtectionRemovedRule{
+		schema: schema.Load(),
+	}
+}
+
+// Name returns the rule name.
+func (r *AzurermLifecycleProtectionRemovedRule) Name() string {
+	return "azurerm_lifecycle_protection_removed"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermLifecycleProtectionRemovedRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// Weakened protections are warnings on their own. When the same change also modifies
+// ForceNew attributes of the resource, the finding is reported as an error.
+func (r *AzurermLifecycleProtectionRemovedRule) Severity() tflint.Severity {
+	return tflint.WARNING
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermLifecycleProtectionRemovedRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks for weakened lifecycle protections between old and new configurations.
+func (r *AzurermLifecycleProtectionRemovedRule) Check(runner tflint.Runner) error {
+	oldContent, err := runner.GetOldModuleContent(lifecycleResourcesSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old resources: %w", err)
+	}
+	newContent, err := runner.GetNewModuleContent(lifecycleResourcesSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new resources: %w", err)
+	}
+
+	oldByAddress := make(map[string]*hclext.Block)
+	for _, block := range oldContent.Blocks {
+		if len(block.Labels) >= 2 {
+			oldByAddress[block.Labels[0]+"."+block.Labels[1]] = block
+		}
+	}
+
+	// ForceNew changes are only looked up for resource types with weakened protections
+	forceNewChanges := make(map[string]map[string][]string)
+
+	for _, newBlock := range newContent.Blocks {
+		if len(newBlock.Labels) < 2 || !strings.HasPrefix(newBlock.Labels[0], "azurerm_") {
+			continue
+		}
+		resourceType, name := newBlock.Labels[0], newBlock.Labels[1]
+		oldBlock, exists := oldByAddress[resourceType+"."+name]
+		if !exists {
+			continue
+		}
+
+		oldLifecycle := readLifecycle(oldBlock)
+		newLifecycle := readLifecycle(newBlock)
+		weakened := r.weakenedProtections(resourceType, oldLifecycle, newLifecycle)
+		if len(weakened) == 0 {
+			continue
+		}
+
+		changes, ok := forceNewChanges[resourceType]
+		if !ok {
+			changes, err = r.forceNewChanges(runner, resourceType)
+			if err != nil {
+				return err
+			}
+			forceNewChanges[resourceType] = changes
+		}
+		var changed []string
+		for _, path := range changes[name] {
+			if !newLifecycle.Ignores(path) {
+				changed = append(changed, path)
+			}
+		}
+
+		severity, message := lifecycleFinding(resourceType+"."+name, weakened, changed)
+		issueRange := newBlock.DefRange
+		if newLifecycle.Block != nil {
+			issueRange = newLifecycle.Block.DefRange
+		}
+		if err := runner.EmitIssue(withSeverity(r, severity), message, issueRange); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// weakenedProtections describes each lifecycle protection that the new configuration drops.
+// ignore_changes is only compared when both configurations list static references.
+func (r *AzurermLifecycleProtectionRemovedRule) weakenedProtections(resourceType string, oldLifecycle, newLifecycle lifecycleSettings) []string {
+	var weakened []string
+
+	if oldLifecycle.PreventDestroy && !newLifecycle.PreventDestroy {
+		var newAttr *hclext.Attribute
+		if newLifecycle.Block != nil && newLifecycle.Block.Body != nil {
+			newAttr = newLifecycle.Block.Body.Attributes["prevent_destroy"]
+		}
+		if newAttr == nil || !isUnresolvedValue(evalAttr(newAttr)) {
+			weakened = append(weakened, "prevent_destroy was removed")
+		}
+	}
+
+	if !oldLifecycle.IgnoreKnown || !newLifecycle.IgnoreKnown {
+		return weakened
+	}
+	if oldLifecycle.IgnoreAll && !newLifecycle.IgnoreAll {
+		weakened = append(weakened, "ignore_changes = all was removed")
+		return weakened
+	}
+	for _, path := range oldLifecycle.ignoredPaths() {
+		if newLifecycle.Ignores(path) {
+			continue
+		}
+		if r.schema.IsForceNew(resourceType, path) {
+			weakened = append(weakened, fmt.Sprintf("ForceNew attribute %q is no longer in ignore_changes", path))
+		} else {
+			weakened = append(weakened, fmt.Sprintf("%q is no longer in ignore_changes", path))
+		}
+	}
+
+	return weakened
+}
+
+// forceNewChanges returns the changed ForceNew attribute paths of a resource type, by resource name.
+func (r *AzurermLifecycleProtectionRemovedRule) forceNewChanges(runner tflint.Runner, resourceType string) (map[string][]string, error) {
+	forceNewAttrs := r.schema.GetForceNewAttributes(resourceType)
+	if len(forceNewAttrs) == 0 {
+		return nil, nil
+	}
+	bodySchema := buildBodySchema(forceNewAttrs)
+
+	oldContent, err := runner.GetOldResourceContent(resourceType, bodySchema, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get old %s: %w", resourceType, err)
+	}
+	newContent, err := runner.GetNewResourceContent(resourceType, bodySchema, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get new %s: %w", resourceType, err)
+	}
+	expandDynamicBlocks(oldContent)
+	expandDynamicBlocks(newContent)
+
+	forceNew := &AzurermForceNewRule{schema: r.schema}
+	oldByName := blocksByName(oldContent)
+	changes := make(map[string][]string)
+	for _, newBlock := range newContent.Blocks {
+		if len(newBlock.Labels) < 2 {
+			continue
+		}
+		name := newBlock.Labels[1]
+		oldBlock, exists := oldByName[name]
+		if !exists {
+			continue
+		}
+		for _, path := range forceNewAttrs {
+			if changed, _, _ := forceNew.attributeChanged(getAttributeByPath(oldBlock, path), getAttributeByPath(newBlock, path), comparisonFor(r.schema.GetAttribute(resourceType, path))); changed {
+				changes[name] = append(changes[name], path)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// lifecycleFinding builds the severity and message for weakened lifecycle protections.
+// forceNewChanged lists the ForceNew attributes changed by the same configuration change.
+func lifecycleFinding(address string, weakened, forceNewChanged []string) (tflint.Severity, string) {
+	message := fmt.Sprintf("Lifecycle protection of %s is weakened: %s.", address, strings.Join(weakened, "; "))
+
+	if len(forceNewChanged) == 0 {
+		return tflint.WARNING, message + " Make sure the resource is no longer expected to be protected from destruction or drift."
+	}
+
+	quoted := make([]string, len(forceNewChanged))
+	for i, path := range forceNewChanged {
+		quoted[i] = fmt.Sprintf("%q", path)
+	}
+	return tflint.ERROR, message + fmt.Sprintf(
+		" The same change modifies ForceNew attribute %s, so the resource will be destroyed and recreated without the protection in place.",
+		strings.Join(quoted, ", "),
+	)
+}
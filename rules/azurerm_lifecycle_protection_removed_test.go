@@ -0,0 +1,202 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+func TestLifecycleProtectionRemoved_Metadata(t *testing.T) {
+	rule := NewAzurermLifecycleProtectionRemovedRule()
+	if rule.Name() != "azurerm_lifecycle_protection_removed" {
+		t.Errorf("Expected rule name to be 'azurerm_lifecycle_protection_removed', got '%s'", rule.Name())
+	}
+	if !rule.Enabled() {
+		t.Error("Expected rule to be enabled by default")
+	}
+	if rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected severity to be WARNING, got %v", rule.Severity())
+	}
+	if !strings.Contains(rule.Link(), "azurerm_lifecycle_protection_removed") {
+		t.Errorf("Expected link to contain rule name, got '%s'", rule.Link())
+	}
+}
+
+func TestLifecycleProtectionRemoved_PreventDestroy(t *testing.T) {
+	tests := []struct {
+		name         string
+		newLocation  string
+		wantSeverity tflint.Severity
+		wantText     string
+	}{
+		{"protection only", "westeurope", tflint.WARNING, "prevent_destroy was removed"},
+		{"with ForceNew change", "eastus", tflint.ERROR, `modifies ForceNew attribute "location"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermLifecycleProtectionRemovedRule()
+
+			runner := helper.TestRunner(t,
+				map[string]string{
+					"main.tf": `
+resource "azurerm_resource_group" "main" {
+    name     = "rg"
+    location = "westeurope"
+    lifecycle {
+        prevent_destroy = true
+    }
+}`,
+				},
+				map[string]string{
+					"main.tf": `
+resource "azurerm_resource_group" "main" {
+    name     = "rg"
+    location = "` + tt.newLocation + `"
+}`,
+				},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			if runner.Issues[0].Rule.Severity() != tt.wantSeverity {
+				t.Errorf("Expected %v severity, got %v", tt.wantSeverity, runner.Issues[0].Rule.Severity())
+			}
+			if !strings.Contains(runner.Issues[0].Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestLifecycleProtectionRemoved_IgnoreChanges(t *testing.T) {
+	rule := NewAzurermLifecycleProtectionRemovedRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "main" {
+    name     = "rg"
+    location = "westeurope"
+    tags     = {}
+    lifecycle {
+        ignore_changes = [location, tags]
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "main" {
+    name     = "rg"
+    location = "westeurope"
+    tags     = {}
+    lifecycle {
+        ignore_changes = [tags]
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if runner.Issues[0].Rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected WARNING severity, got %v", runner.Issues[0].Rule.Severity())
+	}
+	if !strings.Contains(runner.Issues[0].Message, `ForceNew attribute "location" is no longer in ignore_changes`) {
+		t.Errorf("Unexpected message: %q", runner.Issues[0].Message)
+	}
+}
+
+func TestLifecycleProtectionRemoved_IgnoreAllNarrowed(t *testing.T) {
+	rule := NewAzurermLifecycleProtectionRemovedRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_storage_account" "main" {
+    name = "st"
+    lifecycle {
+        ignore_changes = all
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_storage_account" "main" {
+    name = "st"
+    lifecycle {
+        ignore_changes = [tags]
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "ignore_changes = all was removed") {
+		t.Errorf("Unexpected message: %q", runner.Issues[0].Message)
+	}
+}
+
+func TestLifecycleProtectionRemoved_NoWeakening(t *testing.T) {
+	rule := NewAzurermLifecycleProtectionRemovedRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "main" {
+    name     = "rg"
+    location = "westeurope"
+    lifecycle {
+        ignore_changes = [tags]
+    }
+}
+
+resource "random_string" "suffix" {
+    length = 4
+    lifecycle {
+        prevent_destroy = true
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "main" {
+    name     = "rg"
+    location = "westeurope"
+    lifecycle {
+        prevent_destroy = true
+        ignore_changes  = [tags, location]
+    }
+}
+
+resource "random_string" "suffix" {
+    length = 4
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Strengthened protections and non-azurerm resources are not reported
+	helper.AssertNoIssues(t, runner.Issues)
+}
@@ -0,0 +1,420 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+)
+
+// moduleVersionsSchema retrieves the source and version of the module calls of a module.
+var moduleVersionsSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type:       "module",
+			LabelNames: []string{"name"},
+			Body: &hclext.BodySchema{
+				Attributes: []hclext.AttributeSchema{{Name: "source"}, {Name: "version"}},
+			},
+		},
+	},
+}
+
+// movedBlocksSchema retrieves the moved blocks of a module.
+var movedBlocksSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type: "moved",
+			Body: &hclext.BodySchema{
+				Attributes: []hclext.AttributeSchema{{Name: "from"}, {Name: "to"}},
+			},
+		},
+	},
+}
+
+// moduleInventorySchema retrieves the declarations of a module that make up its resource addresses.
+var moduleInventorySchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "moved"},
+	},
+}
+
+// inventoryBlockSchema retrieves the attributes of module inventory blocks:
+// the repetition of resources, the source of module calls and the addresses of moved blocks.
+var inventoryBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "count"}, {Name: "for_each"}, {Name: "source"}, {Name: "from"}, {Name: "to"},
+	},
+}
+
+// resourceRepetition is how a resource declares multiple instances, which decides the
+// shape of its instance keys: none, count (integer indexes) or for_each (string keys).
+type resourceRepetition string
+
+const (
+	repetitionNone    resourceRepetition = ""
+	repetitionCount   resourceRepetition = "count"
+	repetitionForEach resourceRepetition = "for_each"
+)
+
+// moduleResource is an azurerm resource declared by a module, or one of its local submodules.
+type moduleResource struct {
+	// Address is relative to the module, e.g. "azurerm_subnet.this" or "module.subnet.azurerm_subnet.this".
+	Address      string
+	ResourceType string
+	Repetition   resourceRepetition
+}
+
+// movedBlock is a moved block, with addresses relative to the module that is upgraded.
+type movedBlock struct {
+	From string
+	To   string
+}
+
+// moduleInventory is the azurerm resources and moved blocks of one version of a module.
+type moduleInventory struct {
+	Resources map[string]moduleResource
+	Moved     []movedBlock
+}
+
+// AzurermModuleSourceChangeRule detects module source and version changes that remove
+// azurerm resources from the module or change their instance keys. Without moved blocks,
+// Terraform destroys the resources at their old addresses and creates them at the new ones.
+type AzurermModuleSourceChangeRule struct {
+	tflint.DefaultRule
+	// readModule reads the Terraform files of a module directory, by file name.
+	readModule func(dir string) (map[string][]byte, error)
+	// readFile reads the module manifest of a root module.
+	readFile func(name string) ([]byte, error)
+}
+
+// NewAzurermModuleSourceChangeRule creates a new module source change rule.
+func NewAzurermModuleSourceChangeRule() *AzurermModuleSourceChangeRule {
+	return &AzurermModuleSourceChangeRule{
+		readModule: readModuleFiles,
+		readFile:   os.ReadFile,
+	}
+}
+
+// Name returns the rule name.
+func (r *AzurermModuleSourceChangeRule) Name() string {
+	return "azurerm_module_source_change"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermModuleSourceChangeRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// Re-addressed resources are destroyed and recreated.
+func (r *AzurermModuleSourceChangeRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermModuleSourceChangeRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks the module calls whose source or version changed for azurerm resources
+// that the new module version no longer declares at the same address.
+func (r *AzurermModuleSourceChangeRule) Check(runner tflint.Runner) error {
+	oldCalls, err := runner.GetOldModuleContent(moduleVersionsSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old module calls: %w", err)
+	}
+	newCalls, err := runner.GetNewModuleContent(moduleVersionsSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new module calls: %w", err)
+	}
+	rootMoved, err := runner.GetNewModuleContent(movedBlocksSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new moved blocks: %w", err)
+	}
+
+	oldByName := make(map[string]*hclext.Block)
+	for _, call := range oldCalls.Blocks {
+		if len(call.Labels) == 1 {
+			oldByName[call.Labels[0]] = call
+		}
+	}
+
+	for _, newCall := range newCalls.Blocks {
+		if len(newCall.Labels) != 1 {
+			continue
+		}
+		name := newCall.Labels[0]
+		oldCall, ok := oldByName[name]
+		if !ok {
+			continue
+		}
+		change, changeRange := describeModuleChange(name, oldCall, newCall)
+		if change == "" {
+			continue
+		}
+
+		oldInventory, err := r.inventory(oldCall)
+		var newInventory *moduleInventory
+		if err == nil {
+			newInventory, err = r.inventory(newCall)
+		}
+		// A stale installation would compare the wrong module version
+		var notInitialized *moduleNotInitializedError
+		if errors.As(err, &notInitialized) {
+			message := fmt.Sprintf(
+				"Changing %s cannot be checked for re-addressed resources: %s. Run terraform init to install it.",
+				change, notInitialized,
+			)
+			if err := runner.EmitIssue(withSeverity(r, tflint.WARNING), message, changeRange); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// Without both versions installed there is nothing to compare
+		if oldInventory == nil || newInventory == nil {
+			continue
+		}
+
+		// moved blocks in the calling module refer to the module's resources by their full address
+		moved := append([]movedBlock(nil), newInventory.Moved...)
+		prefix := "module." + name + "."
+		for _, block := range rootMoved.Blocks {
+			from, to := attrAddress(block.Body.Attributes["from"]), attrAddress(block.Body.Attributes["to"])
+			if strings.HasPrefix(from, prefix) && strings.HasPrefix(to, prefix) {
+				moved = append(moved, movedBlock{From: strings.TrimPrefix(from, prefix), To: strings.TrimPrefix(to, prefix)})
+			}
+		}
+
+		for _, readdressed := range readdressedResources(name, change, oldInventory, newInventory, moved) {
+			if err := runner.EmitIssue(withSeverity(r, readdressed.Severity), readdressed.Message, changeRange); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// describeModuleChange describes a change of the source or version of a module call,
+// e.g. `the version of module.network (old: 0.7.1, new: 0.8.0)`, and returns the range of
+// the changed argument. Returns an empty description if neither changed.
+func describeModuleChange(name string, oldCall, newCall *hclext.Block) (string, hcl.Range) {
+	for _, argument := range []string{"source", "version"} {
+		oldAttr, newAttr := oldCall.Body.Attributes[argument], newCall.Body.Attributes[argument]
+		oldVal, newVal := evalAttr(oldAttr), evalAttr(newAttr)
+		if oldVal == newVal {
+			continue
+		}
+		changeRange := newCall.DefRange
+		if newAttr != nil {
+			changeRange = newAttr.Range
+		}
+		return fmt.Sprintf("the %s of module.%s (old: %s, new: %s)", argument, name, oldVal, newVal), changeRange
+	}
+	return "", hcl.Range{}
+}
+
+// inventory returns the inventory of the module version used by a module call, from its
+// local source or from the modules installed by terraform init. Returns nil if the module
+// is not available.
+func (r *AzurermModuleSourceChangeRule) inventory(call *hclext.Block) (*moduleInventory, error) {
+	dir, ok := localModuleDir(call)
+	if !ok {
+		var err error
+		dir, ok, err = installedModuleDir(r.readFile, call)
+		if err != nil || !ok {
+			return nil, err
+		}
+	}
+	inventory := &moduleInventory{Resources: make(map[string]moduleResource)}
+	if err := r.collectInventory(inventory, dir, "", 0); err != nil {
+		return nil, err
+	}
+	if len(inventory.Resources) == 0 && len(inventory.Moved) == 0 {
+		return nil, nil
+	}
+	return inventory, nil
+}
+
+// collectInventory adds the azurerm resources and moved blocks of a module directory to an
+// inventory, following module calls with local sources. prefix is the module path of the
+// directory relative to the upgraded module, e.g. "module.subnet.".
+func (r *AzurermModuleSourceChangeRule) collectInventory(inventory *moduleInventory, dir, prefix string, depth int) error {
+	if depth > maxModuleDepth {
+		return nil
+	}
+	files, err := loadModuleFiles(r.readModule, dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(moduleInventorySchema)
+		for _, block := range content.Blocks {
+			attrs, _, _ := block.Body.PartialContent(inventoryBlockSchema)
+			switch block.Type {
+			case "resource":
+				if !strings.HasPrefix(block.Labels[0], "azurerm_") {
+					continue
+				}
+				resource := moduleResource{
+					Address:      prefix + block.Labels[0] + "." + block.Labels[1],
+					ResourceType: block.Labels[0],
+				}
+				if _, ok := attrs.Attributes["count"]; ok {
+					resource.Repetition = repetitionCount
+				}
+				if _, ok := attrs.Attributes["for_each"]; ok {
+					resource.Repetition = repetitionForEach
+				}
+				inventory.Resources[resource.Address] = resource
+			case "moved":
+				from := attrAddress(hclext.FromHCLAttribute(attrs.Attributes["from"]))
+				to := attrAddress(hclext.FromHCLAttribute(attrs.Attributes["to"]))
+				if from != "" && to != "" {
+					inventory.Moved = append(inventory.Moved, movedBlock{From: prefix + from, To: prefix + to})
+				}
+			case "module":
+				source := evalAttr(hclext.FromHCLAttribute(attrs.Attributes["source"]))
+				if !isLocalModuleSource(source) {
+					continue
+				}
+				subDir := filepath.Join(dir, source)
+				if err := r.collectInventory(inventory, subDir, prefix+"module."+block.Labels[0]+".", depth+1); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// readdressedResource is a finding for a resource that a module version change re-addresses.
+type readdressedResource struct {
+	Severity tflint.Severity
+	Message  string
+}
+
+// readdressedResources returns a finding for each azurerm resource of the old module
+// version that the new version no longer declares, declares with instance keys of another
+// shape, or declares without count, and that no moved block covers.
+func readdressedResources(name, change string, oldInventory, newInventory *moduleInventory, moved []movedBlock) []readdressedResource {
+	addresses := make([]string, 0, len(oldInventory.Resources))
+	for address := range oldInventory.Resources {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	prefix := "module." + name + "."
+	var findings []readdressedResource
+	for _, address := range addresses {
+		oldResource := oldInventory.Resources[address]
+		newResource, exists := newInventory.Resources[address]
+		if movedFrom(address, moved) {
+			continue
+		}
+
+		if !exists {
+			message := fmt.Sprintf(
+				"Changing %s removes %s from the module; Terraform will destroy %s%s.",
+				change, address, prefix, address,
+			)
+			to := "<new address>"
+			if candidates := addedResources(oldResource.ResourceType, oldInventory, newInventory); len(candidates) == 1 {
+				to = candidates[0]
+				message += fmt.Sprintf(" The new version declares %s; if it manages the same resource, add this block to keep it:", to)
+			} else {
+				message += " If the new version manages the resource at another address, add this block to keep it:"
+			}
+			findings = append(findings, readdressedResource{tflint.ERROR, message + "\n\n" + movedSnippet(prefix+address, prefix+to)})
+			continue
+		}
+
+		// Terraform moves index 0 to the single instance, and destroys the other instances
+		if oldResource.Repetition == repetitionCount && newResource.Repetition == repetitionNone {
+			findings = append(findings, readdressedResource{tflint.WARNING, fmt.Sprintf(
+				"Changing %s removes count from %s; Terraform will keep %s%s[0] as the single instance and destroy any other instances.",
+				change, address, prefix, address,
+			)})
+			continue
+		}
+
+		if !changesInstanceKeys(oldResource.Repetition, newResource.Repetition) {
+			continue
+		}
+		findings = append(findings, readdressedResource{tflint.ERROR, fmt.Sprintf(
+			"Changing %s switches %s from %s to %s; Terraform will destroy the instances of %s%s and create them at new instance keys. Add a moved block for each instance:\n\n%s",
+			change, address, repetitionName(oldResource.Repetition), repetitionName(newResource.Repetition), prefix, address,
+			movedSnippet(prefix+address+instanceKey(oldResource.Repetition), prefix+address+instanceKey(newResource.Repetition)),
+		)})
+	}
+	return findings
+}
+
+// movedFrom reports whether a moved block moves a resource address, one of its
+// instances, or a module that contains it.
+func movedFrom(address string, moved []movedBlock) bool {
+	for _, block := range moved {
+		if block.From == address || strings.HasPrefix(block.From, address+"[") || strings.HasPrefix(address, block.From+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// addedResources returns the addresses of the resources of a type that the new module
+// version declares and the old version does not, in order.
+func addedResources(resourceType string, oldInventory, newInventory *moduleInventory) []string {
+	var added []string
+	for address, resource := range newInventory.Resources {
+		if _, existed := oldInventory.Resources[address]; !existed && resource.ResourceType == resourceType {
+			added = append(added, address)
+		}
+	}
+	sort.Strings(added)
+	return added
+}
+
+// changesInstanceKeys reports whether a change of repetition changes the instance keys of a
+// resource. Terraform moves a single instance to index 0 and back when count is added or
+// removed, but for_each keys are not derived automatically. Removing count still destroys
+// the instances other than index 0, which readdressedResources reports separately.
+func changesInstanceKeys(oldRepetition, newRepetition resourceRepetition) bool {
+	return oldRepetition != newRepetition && (oldRepetition == repetitionForEach || newRepetition == repetitionForEach)
+}
+
+// repetitionName describes a repetition for messages.
+func repetitionName(repetition resourceRepetition) string {
+	if repetition == repetitionNone {
+		return "a single instance"
+	}
+	return string(repetition)
+}
+
+// instanceKey returns a placeholder instance key for a repetition.
+func instanceKey(repetition resourceRepetition) string {
+	switch repetition {
+	case repetitionCount:
+		return "[0]"
+	case repetitionForEach:
+		return `["<key>"]`
+	}
+	return ""
+}
+
+// movedSnippet returns a moved block, to add to the calling module.
+func movedSnippet(from, to string) string {
+	return fmt.Sprintf("moved {\n  from = %s\n  to   = %s\n}", from, to)
+}
@@ -0,0 +1,339 @@
+package rules
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+func TestModuleSourceChange_Name(t *testing.T) {
+	rule := NewAzurermModuleSourceChangeRule()
+	if rule.Name() != "azurerm_module_source_change" {
+		t.Errorf("Expected rule name to be 'azurerm_module_source_change', got '%s'", rule.Name())
+	}
+}
+
+// testManifest returns a readFile function that serves module manifests by root directory.
+func testManifest(manifests map[string]string) func(string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		for root, manifest := range manifests {
+			if name == filepath.Join(root, ".terraform", "modules", "modules.json") {
+				return []byte(manifest), nil
+			}
+		}
+		return nil, fs.ErrNotExist
+	}
+}
+
+const vnetManifest = `{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"vnet","Source":"registry.terraform.io/Azure/avm-res-network-virtualnetwork/azurerm","Version":"%s","Dir":".terraform/modules/vnet"}]}`
+
+func vnetCall(version string) string {
+	return `module "vnet" {
+  source  = "Azure/avm-res-network-virtualnetwork/azurerm"
+  version = "` + version + `"
+}
+`
+}
+
+const vnetModuleV1 = `
+resource "azurerm_virtual_network" "this" {
+  name = var.name
+}
+
+resource "azurerm_subnet" "this" {
+  count = length(var.subnets)
+  name  = var.subnets[count.index]
+}
+`
+
+func TestModuleSourceChange(t *testing.T) {
+	tests := []struct {
+		name         string
+		newModule    string
+		newRoot      string
+		wantIssues   []string
+		wantSeverity tflint.Severity
+	}{
+		{
+			name:      "unchanged resources",
+			newModule: vnetModuleV1,
+		},
+		{
+			name:      "resource renamed",
+			newModule: strings.Replace(vnetModuleV1, `"azurerm_virtual_network" "this"`, `"azurerm_virtual_network" "vnet"`, 1),
+			wantIssues: []string{
+				`Changing the version of module.vnet (old: 0.7.1, new: 0.8.0) removes azurerm_virtual_network.this from the module; Terraform will destroy module.vnet.azurerm_virtual_network.this. The new version declares azurerm_virtual_network.vnet`,
+			},
+		},
+		{
+			name: "resource renamed with moved block in the module",
+			newModule: strings.Replace(vnetModuleV1, `"azurerm_virtual_network" "this"`, `"azurerm_virtual_network" "vnet"`, 1) + `
+moved {
+  from = azurerm_virtual_network.this
+  to   = azurerm_virtual_network.vnet
+}
+`,
+		},
+		{
+			name:      "resource renamed with moved block in the calling module",
+			newModule: strings.Replace(vnetModuleV1, `"azurerm_virtual_network" "this"`, `"azurerm_virtual_network" "vnet"`, 1),
+			newRoot: `
+moved {
+  from = module.vnet.azurerm_virtual_network.this
+  to   = module.vnet.azurerm_virtual_network.vnet
+}
+`,
+		},
+		{
+			name:      "count switched to for_each",
+			newModule: strings.Replace(vnetModuleV1, `count = length(var.subnets)`, `for_each = var.subnets`, 1),
+			wantIssues: []string{
+				"switches azurerm_subnet.this from count to for_each",
+				"from = module.vnet.azurerm_subnet.this[0]\n  to   = module.vnet.azurerm_subnet.this[\"<key>\"]",
+			},
+		},
+		{
+			name:      "count removed",
+			newModule: strings.Replace(vnetModuleV1, `count = length(var.subnets)`, ``, 1),
+			wantIssues: []string{
+				"removes count from azurerm_subnet.this; Terraform will keep module.vnet.azurerm_subnet.this[0] as the single instance and destroy any other instances",
+			},
+			wantSeverity: tflint.WARNING,
+		},
+		{
+			name:      "count added",
+			newModule: strings.Replace(vnetModuleV1, "resource \"azurerm_virtual_network\" \"this\" {\n", "resource \"azurerm_virtual_network\" \"this\" {\n  count = 1\n", 1),
+		},
+		{
+			name:      "resource removed without replacement",
+			newModule: strings.Replace(vnetModuleV1, `resource "azurerm_subnet"`, `resource "azapi_resource"`, 1),
+			wantIssues: []string{
+				"removes azurerm_subnet.this from the module",
+				"to   = module.vnet.<new address>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermModuleSourceChangeRule()
+			rule.readFile = testManifest(map[string]string{
+				"old": strings.Replace(vnetManifest, "%s", "0.7.1", 1),
+				"new": strings.Replace(vnetManifest, "%s", "0.8.0", 1),
+			})
+			rule.readModule = testModules(map[string]map[string]string{
+				filepath.Join("old", ".terraform", "modules", "vnet"): {"main.tf": vnetModuleV1},
+				filepath.Join("new", ".terraform", "modules", "vnet"): {"main.tf": tt.newModule},
+			}, nil)
+
+			runner := helper.TestRunner(t,
+				map[string]string{"old/main.tf": vnetCall("0.7.1")},
+				map[string]string{"new/main.tf": vnetCall("0.8.0") + tt.newRoot},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(tt.wantIssues) == 0 {
+				if len(runner.Issues) != 0 {
+					t.Fatalf("Expected no issues, got %d: %v", len(runner.Issues), runner.Issues)
+				}
+				return
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			for _, want := range tt.wantIssues {
+				if !strings.Contains(runner.Issues[0].Message, want) {
+					t.Errorf("Expected message to contain %q, got %q", want, runner.Issues[0].Message)
+				}
+			}
+			wantSeverity := tt.wantSeverity
+			if wantSeverity == 0 {
+				wantSeverity = tflint.ERROR
+			}
+			if runner.Issues[0].Rule.Severity() != wantSeverity {
+				t.Errorf("Expected %v severity, got %v", wantSeverity, runner.Issues[0].Rule.Severity())
+			}
+			if runner.Issues[0].Range.Start.Line != 3 {
+				t.Errorf("Expected the issue at the version argument, got %s", runner.Issues[0].Range)
+			}
+		})
+	}
+}
+
+func TestModuleSourceChange_VendoredSubmodules(t *testing.T) {
+	rule := NewAzurermModuleSourceChangeRule()
+	rule.readFile = testManifest(nil)
+
+	module := func(subnetName string) string {
+		return `
+module "subnet" {
+  source = "./modules/subnet"
+}
+` + subnetName
+	}
+	rule.readModule = testModules(map[string]map[string]string{
+		filepath.Join("old", "vendor", "vnet-1"):                      {"main.tf": module("")},
+		filepath.Join("old", "vendor", "vnet-1", "modules", "subnet"): {"main.tf": `resource "azurerm_subnet" "this" {}`},
+		filepath.Join("new", "vendor", "vnet-2"):                      {"main.tf": module("")},
+		filepath.Join("new", "vendor", "vnet-2", "modules", "subnet"): {"main.tf": `resource "azurerm_subnet" "subnet" {}`},
+	}, nil)
+
+	call := func(dir string) string {
+		return `module "vnet" {
+  source = "./vendor/` + dir + `"
+}
+`
+	}
+	runner := helper.TestRunner(t,
+		map[string]string{"old/main.tf": call("vnet-1")},
+		map[string]string{"new/main.tf": call("vnet-2")},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	want := `Changing the source of module.vnet (old: ./vendor/vnet-1, new: ./vendor/vnet-2) removes module.subnet.azurerm_subnet.this`
+	if !strings.Contains(runner.Issues[0].Message, want) {
+		t.Errorf("Expected message to contain %q, got %q", want, runner.Issues[0].Message)
+	}
+}
+
+func TestModuleSourceChange_NotInstalled(t *testing.T) {
+	rule := NewAzurermModuleSourceChangeRule()
+	rule.readFile = testManifest(nil)
+	var read []string
+	rule.readModule = testModules(nil, &read)
+
+	runner := helper.TestRunner(t,
+		map[string]string{"old/main.tf": vnetCall("0.7.1")},
+		map[string]string{"new/main.tf": vnetCall("0.8.0")},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 0 || len(read) != 0 {
+		t.Errorf("Expected modules that are not installed to be skipped, got issues %v, read %v", runner.Issues, read)
+	}
+}
+
+func TestModuleSourceChange_VersionUnchanged(t *testing.T) {
+	rule := NewAzurermModuleSourceChangeRule()
+	var read []string
+	rule.readModule = testModules(nil, &read)
+	rule.readFile = testManifest(map[string]string{"old": strings.Replace(vnetManifest, "%s", "0.7.1", 1)})
+
+	runner := helper.TestRunner(t,
+		map[string]string{"old/main.tf": vnetCall("0.7.1")},
+		map[string]string{"new/main.tf": vnetCall("0.7.1")},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(read) != 0 {
+		t.Errorf("Expected unchanged module calls not to be read, read %v", read)
+	}
+}
+
+func TestModuleSourceChange_NotInitialized(t *testing.T) {
+	tests := []struct {
+		name        string
+		newManifest string
+		wantIssue   string
+	}{
+		{"stale version", strings.Replace(vnetManifest, "%s", "0.7.1", 1), "module.vnet in new is not initialized for this version (installed: 0.7.1, required: ~> 0.8.0)"},
+		{"stale source", strings.Replace(strings.Replace(vnetManifest, "%s", "0.8.0", 1), "avm-res-network-virtualnetwork", "vnet", 1), "installed: registry.terraform.io/Azure/vnet/azurerm, required: Azure/avm-res-network-virtualnetwork/azurerm"},
+		{"constraint satisfied", strings.Replace(vnetManifest, "%s", "0.8.3", 1), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermModuleSourceChangeRule()
+			rule.readFile = testManifest(map[string]string{
+				"old": strings.Replace(vnetManifest, "%s", "0.7.1", 1),
+				"new": tt.newManifest,
+			})
+			rule.readModule = testModules(map[string]map[string]string{
+				filepath.Join("old", ".terraform", "modules", "vnet"): {"main.tf": vnetModuleV1},
+				filepath.Join("new", ".terraform", "modules", "vnet"): {"main.tf": vnetModuleV1},
+			}, nil)
+
+			runner := helper.TestRunner(t,
+				map[string]string{"old/main.tf": vnetCall("0.7.1")},
+				map[string]string{"new/main.tf": vnetCall("~> 0.8.0")},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if tt.wantIssue == "" {
+				helper.AssertNoIssues(t, runner.Issues)
+				return
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			if runner.Issues[0].Rule.Severity() != tflint.WARNING {
+				t.Errorf("Expected WARNING severity, got %v", runner.Issues[0].Rule.Severity())
+			}
+			if !strings.Contains(runner.Issues[0].Message, tt.wantIssue) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantIssue, runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		want                bool
+	}{
+		{"0.8.0", "0.8.0", true},
+		{"= 0.8.0", "0.8.1", false},
+		{"~> 0.8", "0.9.2", true},
+		{"~> 0.8", "1.0.0", false},
+		{"~> 0.8.0", "0.8.5", true},
+		{"~> 0.8.0", "0.9.0", false},
+		{">= 0.7, < 1.0", "0.9.9", true},
+		{">= 0.7, < 1.0", "1.0.0", false},
+		{"!= 0.8.0", "0.8.0", false},
+		{"0.8.0", "0.8.0-beta", false},
+		{"latest", "0.8.0", true},
+	}
+
+	for _, tt := range tests {
+		if got := versionSatisfies(tt.constraint, tt.version); got != tt.want {
+			t.Errorf("versionSatisfies(%q, %q) = %v, want %v", tt.constraint, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestChangesInstanceKeys(t *testing.T) {
+	tests := []struct {
+		old, new resourceRepetition
+		want     bool
+	}{
+		{repetitionNone, repetitionNone, false},
+		{repetitionNone, repetitionCount, false},
+		{repetitionCount, repetitionNone, false},
+		{repetitionNone, repetitionForEach, true},
+		{repetitionForEach, repetitionNone, true},
+		{repetitionCount, repetitionForEach, true},
+		{repetitionForEach, repetitionCount, true},
+	}
+	for _, tt := range tests {
+		if got := changesInstanceKeys(tt.old, tt.new); got != tt.want {
+			t.Errorf("changesInstanceKeys(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
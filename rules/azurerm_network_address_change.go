@@ -0,0 +1,425 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// subnetConsumers are the resource types whose attributes place workloads in a subnet.
+// Each entry maps a resource type to the attribute paths that reference the subnet.
+var subnetConsumers = map[string][]string{
+	"azurerm_network_interface": {"ip_configuration.subnet_id"},
+	"azurerm_private_endpoint":  {"subnet_id"},
+}
+
+// AzurermNetworkAddressChangeRule detects address changes on subnets and virtual networks
+// that fail at apply or disrupt traffic, even though they are not ForceNew.
+type AzurermNetworkAddressChangeRule struct {
+	tflint.DefaultRule
+}
+
+// NewAzurermNetworkAddressChangeRule creates a new network address change rule.
+func NewAzurermNetworkAddressChangeRule() *AzurermNetworkAddressChangeRule {
+	return &AzurermNetworkAddressChangeRule{}
+}
+
+// Name returns the rule name.
+func (r *AzurermNetworkAddressChangeRule) Name() string {
+	return "azurerm_network_address_change"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermNetworkAddressChangeRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// Shrinking an address range that is in use fails at apply. Changes to subnets
+// without known consumers are reported as warnings.
+func (r *AzurermNetworkAddressChangeRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermNetworkAddressChangeRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks for subnet and virtual network address changes between old and new configurations.
+func (r *AzurermNetworkAddressChangeRule) Check(runner tflint.Runner) error {
+	if err := r.checkSubnets(runner); err != nil {
+		return err
+	}
+	return r.checkVirtualNetworks(runner)
+}
+
+// checkSubnets reports address_prefixes changes on azurerm_subnet resources that are not
+// additive expansions. Subnets with delegations, NICs or private endpoints are reported as errors.
+func (r *AzurermNetworkAddressChangeRule) checkSubnets(runner tflint.Runner) error {
+	bodySchema := &hclext.BodySchema{
+		Attributes: []hclext.AttributeSchema{{Name: "address_prefixes"}},
+		Blocks: []hclext.BlockSchema{
+			{Type: "delegation", Body: &hclext.BodySchema{Attributes: []hclext.AttributeSchema{{Name: "name"}}}},
+		},
+	}
+
+	oldContent, err := runner.GetOldResourceContent("azurerm_subnet", bodySchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old azurerm_subnet: %w", err)
+	}
+	newContent, err := runner.GetNewResourceContent("azurerm_subnet", bodySchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new azurerm_subnet: %w", err)
+	}
+
+	var consumers map[string][]string
+	oldByName := blocksByName(oldContent)
+	for _, newBlock := range newContent.Blocks {
+		if len(newBlock.Labels) < 2 {
+			continue
+		}
+		name := newBlock.Labels[1]
+		oldBlock, exists := oldByName[name]
+		if !exists {
+			continue
+		}
+
+		oldAttr := getAttributeByPath(oldBlock, "address_prefixes")
+		newAttr := getAttributeByPath(newBlock, "address_prefixes")
+		oldPrefixes, ok := attrPrefixes(oldAttr)
+		if !ok {
+			continue
+		}
+		newPrefixes, ok := attrPrefixes(newAttr)
+		if !ok {
+			continue
+		}
+		removed := uncoveredPrefixes(oldPrefixes, newPrefixes)
+		if len(removed) == 0 {
+			continue // Unchanged or additive expansion
+		}
+
+		if consumers == nil {
+			consumers, err = subnetConsumersByName(runner)
+			if err != nil {
+				return err
+			}
+		}
+
+		var inUse []string
+		for _, block := range nestedBlocks(newBlock) {
+			if block.Type == "delegation" {
+				inUse = append(inUse, "a delegation")
+				break
+			}
+		}
+		inUse = append(inUse, consumers[name]...)
+
+		message := fmt.Sprintf(
+			"Changing \"address_prefixes\" of azurerm_subnet.%s removes %s (old: %s, new: %s).",
+			name, formatPrefixes(removed), formatPrefixes(oldPrefixes), formatPrefixes(newPrefixes),
+		)
+		severity := tflint.WARNING
+		if len(inUse) > 0 {
+			severity = tflint.ERROR
+			message += fmt.Sprintf(
+				" The subnet is in use by %s; Azure rejects address changes that exclude allocated addresses.",
+				strings.Join(inUse, ", "),
+			)
+		} else {
+			message += " Any workload deployed outside this configuration in the removed range will block the change."
+		}
+		if err := runner.EmitIssue(withSeverity(r, severity), message, newAttr.Range); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkVirtualNetworks reports shrinking address_space on azurerm_virtual_network resources,
+// and expansions that overlap the address space of a virtual network peered in the same configuration.
+func (r *AzurermNetworkAddressChangeRule) checkVirtualNetworks(runner tflint.Runner) error {
+	bodySchema := &hclext.BodySchema{
+		Attributes: []hclext.AttributeSchema{{Name: "address_space"}},
+	}
+
+	oldContent, err := runner.GetOldResourceContent("azurerm_virtual_network", bodySchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old azurerm_virtual_network: %w", err)
+	}
+	newContent, err := runner.GetNewResourceContent("azurerm_virtual_network", bodySchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new azurerm_virtual_network: %w", err)
+	}
+
+	var peers map[string][]string
+	oldByName := blocksByName(oldContent)
+	newByName := blocksByName(newContent)
+	for _, newBlock := range newContent.Blocks {
+		if len(newBlock.Labels) < 2 {
+			continue
+		}
+		name := newBlock.Labels[1]
+		oldBlock, exists := oldByName[name]
+		if !exists {
+			continue
+		}
+
+		newAttr := getAttributeByPath(newBlock, "address_space")
+		oldSpace, ok := attrPrefixes(getAttributeByPath(oldBlock, "address_space"))
+		if !ok {
+			continue
+		}
+		newSpace, ok := attrPrefixes(newAttr)
+		if !ok {
+			continue
+		}
+
+		if removed := uncoveredPrefixes(oldSpace, newSpace); len(removed) > 0 {
+			message := fmt.Sprintf(
+				"Shrinking \"address_space\" of azurerm_virtual_network.%s removes %s (old: %s, new: %s). "+
+					"Subnets and peerings using the removed range will fail or lose connectivity.",
+				name, formatPrefixes(removed), formatPrefixes(oldSpace), formatPrefixes(newSpace),
+			)
+			if err := runner.EmitIssue(r, message, newAttr.Range); err != nil {
+				return err
+			}
+		}
+
+		added := uncoveredPrefixes(newSpace, oldSpace)
+		if len(added) == 0 {
+			continue
+		}
+		if peers == nil {
+			peers, err = virtualNetworkPeers(runner)
+			if err != nil {
+				return err
+			}
+		}
+		for _, peer := range peers[name] {
+			peerBlock, ok := newByName[peer]
+			if !ok {
+				continue
+			}
+			peerSpace, ok := attrPrefixes(getAttributeByPath(peerBlock, "address_space"))
+			if !ok {
+				continue
+			}
+			if overlaps := overlappingPrefixes(added, peerSpace); len(overlaps) > 0 {
+				message := fmt.Sprintf(
+					"Expanding \"address_space\" of azurerm_virtual_network.%s adds %s, which overlaps peered azurerm_virtual_network.%s (%s). "+
+						"Peered virtual networks cannot have overlapping address spaces.",
+					name, formatPrefixes(overlaps), peer, formatPrefixes(peerSpace),
+				)
+				if err := runner.EmitIssue(r, message, newAttr.Range); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// subnetConsumersByName returns the resources in the new configuration that reference
+// each azurerm_subnet, keyed by subnet resource name.
+func subnetConsumersByName(runner tflint.Runner) (map[string][]string, error) {
+	consumers := make(map[string][]string)
+
+	resourceTypes := make([]string, 0, len(subnetConsumers))
+	for resourceType := range subnetConsumers {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	for _, resourceType := range resourceTypes {
+		paths := subnetConsumers[resourceType]
+		content, err := runner.GetNewResourceContent(resourceType, buildBodySchema(paths), nil)
+		if err != nil {
+			return nil, fmt.Errorf("get new %s: %w", resourceType, err)
+		}
+		for _, block := range content.Blocks {
+			if len(block.Labels) < 2 {
+				continue
+			}
+			for _, path := range paths {
+				for _, subnet := range referencedResourceNames(getAttributeByPath(block, path), "azurerm_subnet") {
+					consumers[subnet] = append(consumers[subnet], resourceType+"."+block.Labels[1])
+				}
+			}
+		}
+	}
+
+	return consumers, nil
+}
+
+// virtualNetworkPeers returns the virtual networks peered with each azurerm_virtual_network
+// in the new configuration, keyed by virtual network resource name. Peerings are symmetric.
+func virtualNetworkPeers(runner tflint.Runner) (map[string][]string, error) {
+	content, err := runner.GetNewResourceContent("azurerm_virtual_network_peering", &hclext.BodySchema{
+		Attributes: []hclext.AttributeSchema{
+			{Name: "virtual_network_name"},
+			{Name: "remote_virtual_network_id"},
+		},
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get new azurerm_virtual_network_peering: %w", err)
+	}
+
+	peers := make(map[string][]string)
+	for _, block := range content.Blocks {
+		if block.Body == nil {
+			continue
+		}
+		locals := referencedResourceNames(block.Body.Attributes["virtual_network_name"], "azurerm_virtual_network")
+		remotes := referencedResourceNames(block.Body.Attributes["remote_virtual_network_id"], "azurerm_virtual_network")
+		for _, local := range locals {
+			for _, remote := range remotes {
+				peers[local] = append(peers[local], remote)
+				peers[remote] = append(peers[remote], local)
+			}
+		}
+	}
+
+	return peers, nil
+}
+
+// referencedResourceNames returns the names of resources of the given type referenced
+// by an attribute, e.g. "example" for `subnet_id = azurerm_subnet.example.id`.
+func referencedResourceNames(attr *hclext.Attribute, resourceType string) []string {
+	if attr == nil || attr.Expr == nil {
+		return nil
+	}
+
+	var names []string
+	for _, traversal := range attr.Expr.Variables() {
+		if len(traversal) < 2 || traversal.RootName() != resourceType {
+			continue
+		}
+		if step, ok := traversal[1].(hcl.TraverseAttr); ok {
+			names = append(names, step.Name)
+		}
+	}
+	return names
+}
+
+// blocksByName indexes resource blocks by their name label.
+func blocksByName(content *hclext.BodyContent) map[string]*hclext.Block {
+	byName := make(map[string]*hclext.Block)
+	if content == nil {
+		return byName
+	}
+	for _, block := range content.Blocks {
+		if len(block.Labels) >= 2 {
+			byName[block.Labels[1]] = block
+		}
+	}
+	return byName
+}
+
+// attrPrefixes parses an attribute holding a list of CIDR strings.
+// Returns false if the value is not statically known or contains an invalid CIDR.
+func attrPrefixes(attr *hclext.Attribute) ([]netip.Prefix, bool) {
+	val, ok := attrValue(attr)
+	if !ok || val.IsNull() || !val.IsWhollyKnown() {
+		return nil, false
+	}
+	if !val.Type().IsListType() && !val.Type().IsSetType() && !val.Type().IsTupleType() {
+		return nil, false
+	}
+
+	var prefixes []netip.Prefix
+	for it := val.ElementIterator(); it.Next(); {
+		_, elem := it.Element()
+		if elem.IsNull() || elem.Type() != cty.String {
+			return nil, false
+		}
+		prefix, err := netip.ParsePrefix(elem.AsString())
+		if err != nil {
+			return nil, false
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, true
+}
+
+// uncoveredPrefixes returns the prefixes in from that are not covered by the prefixes in to
+// together, so that splitting 10.0.0.0/16 into two /17s is not reported as a removal.
+// An empty result for old→new means the change is unchanged or an additive expansion.
+func uncoveredPrefixes(from, to []netip.Prefix) []netip.Prefix {
+	var uncovered []netip.Prefix
+	for _, p := range from {
+		if !prefixCovered(p.Masked(), to) {
+			uncovered = append(uncovered, p)
+		}
+	}
+	return uncovered
+}
+
+// prefixCovered reports whether the union of the prefixes in to contains all of p.
+// A prefix that is only partly contained by one of them is split into its two halves,
+// until each half is either contained by one of them or overlaps none of them.
+func prefixCovered(p netip.Prefix, to []netip.Prefix) bool {
+	overlaps := false
+	for _, q := range to {
+		if q.Bits() <= p.Bits() && q.Contains(p.Addr()) {
+			return true
+		}
+		overlaps = overlaps || q.Overlaps(p)
+	}
+	if !overlaps || p.Bits() == p.Addr().BitLen() {
+		return false
+	}
+	low, high := prefixHalves(p)
+	return prefixCovered(low, to) && prefixCovered(high, to)
+}
+
+// prefixHalves splits a masked prefix into the two prefixes one bit longer.
+func prefixHalves(p netip.Prefix) (netip.Prefix, netip.Prefix) {
+	bits := p.Bits()
+	addr := p.Addr().AsSlice()
+	addr[bits/8] |= 0x80 >> (bits % 8)
+	highAddr, _ := netip.AddrFromSlice(addr)
+	return netip.PrefixFrom(p.Addr(), bits+1), netip.PrefixFrom(highAddr, bits+1)
+}
+
+// overlappingPrefixes returns the prefixes in a that overlap any prefix in b.
+func overlappingPrefixes(a, b []netip.Prefix) []netip.Prefix {
+	var overlaps []netip.Prefix
+	for _, p := range a {
+		for _, q := range b {
+			if p.Overlaps(q) {
+				overlaps = append(overlaps, p)
+				break
+			}
+		}
+	}
+	return overlaps
+}
+
+// formatPrefixes formats a list of prefixes for display in messages.
+func formatPrefixes(prefixes []netip.Prefix) string {
+	parts := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		parts[i] = p.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// nestedBlocks returns the nested blocks of a block, or nil if its body was not retrieved.
+func nestedBlocks(block *hclext.Block) []*hclext.Block {
+	if block == nil || block.Body == nil {
+		return nil
+	}
+	return block.Body.Blocks
+}
@@ -0,0 +1,351 @@
+package rules
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+func TestNetworkAddressChange_Metadata(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+	if rule.Name() != "azurerm_network_address_change" {
+		t.Errorf("Expected rule name to be 'azurerm_network_address_change', got '%s'", rule.Name())
+	}
+	if !rule.Enabled() {
+		t.Error("Expected rule to be enabled by default")
+	}
+	if rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected severity to be ERROR, got %v", rule.Severity())
+	}
+	if !strings.Contains(rule.Link(), "azurerm_network_address_change") {
+		t.Errorf("Expected link to contain rule name, got '%s'", rule.Link())
+	}
+}
+
+func TestNetworkAddressChange_SubnetExpansion(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.1.0/25"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.1.0/24", "10.0.2.0/24"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Expanding a subnet keeps every existing address
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestNetworkAddressChange_VirtualNetworkSplit(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_network" "main" {
+    name          = "vnet"
+    address_space = ["10.0.0.0/16"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_network" "main" {
+    name          = "vnet"
+    address_space = ["10.0.0.0/17", "10.0.128.0/17"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// The two halves together keep every existing address
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestNetworkAddressChange_SubnetShrinkUnused(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.1.0/24"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.1.0/25"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if runner.Issues[0].Rule.Severity() != tflint.WARNING {
+		t.Errorf("Expected WARNING severity, got %v", runner.Issues[0].Rule.Severity())
+	}
+	if !strings.Contains(runner.Issues[0].Message, "removes [10.0.1.0/24]") {
+		t.Errorf("Expected message to list the removed range, got %q", runner.Issues[0].Message)
+	}
+}
+
+func TestNetworkAddressChange_SubnetInUse(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	consumers := `
+resource "azurerm_network_interface" "vm" {
+    name = "vm-nic"
+    ip_configuration {
+        name      = "internal"
+        subnet_id = azurerm_subnet.app.id
+    }
+}
+
+resource "azurerm_private_endpoint" "kv" {
+    name      = "kv-pe"
+    subnet_id = azurerm_subnet.app.id
+}`
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.1.0/24"]
+}` + consumers,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.9.0/24"]
+}` + consumers,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected ERROR severity, got %v", issue.Rule.Severity())
+	}
+	for _, want := range []string{"azurerm_network_interface.vm", "azurerm_private_endpoint.kv"} {
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected message to mention %s, got %q", want, issue.Message)
+		}
+	}
+}
+
+func TestNetworkAddressChange_SubnetDelegation(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "aci" {
+    name             = "aci"
+    address_prefixes = ["10.0.4.0/24"]
+    delegation {
+        name = "aci"
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "aci" {
+    name             = "aci"
+    address_prefixes = ["10.0.4.0/26"]
+    delegation {
+        name = "aci"
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if runner.Issues[0].Rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected ERROR severity, got %v", runner.Issues[0].Rule.Severity())
+	}
+	if !strings.Contains(runner.Issues[0].Message, "a delegation") {
+		t.Errorf("Expected message to mention the delegation, got %q", runner.Issues[0].Message)
+	}
+}
+
+func TestNetworkAddressChange_VirtualNetworkShrink(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_network" "hub" {
+    name          = "hub"
+    address_space = ["10.0.0.0/16", "10.1.0.0/16"]
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_network" "hub" {
+    name          = "hub"
+    address_space = ["10.0.0.0/16"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "Shrinking \"address_space\" of azurerm_virtual_network.hub removes [10.1.0.0/16]") {
+		t.Errorf("Unexpected message: %q", runner.Issues[0].Message)
+	}
+}
+
+func TestNetworkAddressChange_VirtualNetworkPeerOverlap(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	peering := `
+resource "azurerm_virtual_network" "spoke" {
+    name          = "spoke"
+    address_space = ["10.2.0.0/16"]
+}
+
+resource "azurerm_virtual_network_peering" "hub_to_spoke" {
+    name                      = "hub-to-spoke"
+    virtual_network_name      = azurerm_virtual_network.hub.name
+    remote_virtual_network_id = azurerm_virtual_network.spoke.id
+}`
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_network" "hub" {
+    name          = "hub"
+    address_space = ["10.0.0.0/16"]
+}` + peering,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_network" "hub" {
+    name          = "hub"
+    address_space = ["10.0.0.0/16", "10.2.128.0/17", "10.3.0.0/16"]
+}` + peering,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	msg := runner.Issues[0].Message
+	if !strings.Contains(msg, "adds [10.2.128.0/17], which overlaps peered azurerm_virtual_network.spoke") {
+		t.Errorf("Unexpected message: %q", msg)
+	}
+}
+
+func TestNetworkAddressChange_DynamicValues(t *testing.T) {
+	rule := NewAzurermNetworkAddressChangeRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = var.old_prefixes
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_subnet" "app" {
+    name             = "app"
+    address_prefixes = ["10.0.1.0/26"]
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// Values that cannot be statically resolved are not compared
+	helper.AssertNoIssues(t, runner.Issues)
+}
+
+func TestUncoveredPrefixes(t *testing.T) {
+	parse := func(cidrs ...string) []netip.Prefix {
+		var prefixes []netip.Prefix
+		for _, c := range cidrs {
+			prefixes = append(prefixes, netip.MustParsePrefix(c))
+		}
+		return prefixes
+	}
+
+	tests := []struct {
+		name string
+		from []netip.Prefix
+		to   []netip.Prefix
+		want string
+	}{
+		{"unchanged", parse("10.0.0.0/24"), parse("10.0.0.0/24"), "[]"},
+		{"expanded", parse("10.0.0.0/24"), parse("10.0.0.0/23"), "[]"},
+		{"added", parse("10.0.0.0/24"), parse("10.0.0.0/24", "10.0.5.0/24"), "[]"},
+		{"shrunk", parse("10.0.0.0/23"), parse("10.0.0.0/24"), "[10.0.0.0/23]"},
+		{"replaced", parse("10.0.0.0/24"), parse("10.1.0.0/24"), "[10.0.0.0/24]"},
+		{"split", parse("10.0.0.0/16"), parse("10.0.0.0/17", "10.0.128.0/17"), "[]"},
+		{"split unevenly", parse("10.0.0.0/16"), parse("10.0.0.0/17", "10.0.128.0/18", "10.0.192.0/18"), "[]"},
+		{"split with a gap", parse("10.0.0.0/16"), parse("10.0.0.0/17", "10.0.192.0/18"), "[10.0.0.0/16]"},
+		{"merged", parse("10.0.0.0/17", "10.0.128.0/17"), parse("10.0.0.0/16"), "[]"},
+		{"split IPv6", parse("fd00::/48"), parse("fd00::/49", "fd00:0:0:8000::/49"), "[]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPrefixes(uncoveredPrefixes(tt.from, tt.to)); got != tt.want {
+				t.Errorf("uncoveredPrefixes() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/project"
+)
+
+// resourceTypeMigration describes a legacy azurerm resource type and the types that replace it.
+type resourceTypeMigration struct {
+	// From is the legacy resource type.
+	From string
+	// To lists the replacement resource types.
+	To []string
+	// Importable reports whether the replacement types manage the same Azure resource,
+	// so the live resource can be adopted with a removed + import block pair.
+	// When false, the replacement is a different Azure resource and data must be migrated.
+	Importable bool
+}
+
+// resourceTypeMigrations is the curated list of legacy-to-replacement resource swaps.
+var resourceTypeMigrations = []resourceTypeMigration{
+	{From: "azurerm_app_service", To: []string{"azurerm_linux_web_app", "azurerm_windows_web_app"}, Importable: true},
+	{From: "azurerm_app_service_slot", To: []string{"azurerm_linux_web_app_slot", "azurerm_windows_web_app_slot"}, Importable: true},
+	{From: "azurerm_app_service_plan", To: []string{"azurerm_service_plan"}, Importable: true},
+	{From: "azurerm_function_app", To: []string{"azurerm_linux_function_app", "azurerm_windows_function_app"}, Importable: true},
+	{From: "azurerm_function_app_slot", To: []string{"azurerm_linux_function_app_slot", "azurerm_windows_function_app_slot"}, Importable: true},
+	{From: "azurerm_virtual_machine", To: []string{"azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine"}, Importable: true},
+	{From: "azurerm_virtual_machine_scale_set", To: []string{"azurerm_linux_virtual_machine_scale_set", "azurerm_windows_virtual_machine_scale_set", "azurerm_orchestrated_virtual_machine_scale_set"}, Importable: true},
+	{From: "azurerm_sql_server", To: []string{"azurerm_mssql_server"}, Importable: true},
+	{From: "azurerm_sql_database", To: []string{"azurerm_mssql_database"}, Importable: true},
+	{From: "azurerm_sql_elasticpool", To: []string{"azurerm_mssql_elasticpool"}, Importable: true},
+	{From: "azurerm_sql_firewall_rule", To: []string{"azurerm_mssql_firewall_rule"}, Importable: true},
+	{From: "azurerm_sql_virtual_network_rule", To: []string{"azurerm_mssql_virtual_network_rule"}, Importable: true},
+	{From: "azurerm_sql_failover_group", To: []string{"azurerm_mssql_failover_group"}, Importable: true},
+	{From: "azurerm_mysql_server", To: []string{"azurerm_mysql_flexible_server"}, Importable: false},
+	{From: "azurerm_mysql_database", To: []string{"azurerm_mysql_flexible_database"}, Importable: false},
+	{From: "azurerm_postgresql_server", To: []string{"azurerm_postgresql_flexible_server"}, Importable: false},
+	{From: "azurerm_postgresql_database", To: []string{"azurerm_postgresql_flexible_server_database"}, Importable: false},
+	{From: "azurerm_frontdoor", To: []string{"azurerm_cdn_frontdoor_profile"}, Importable: false},
+}
+
+// migrationKeyAttributes are the attributes that identify a resource, used to match a legacy
+// resource to its replacement when the resource names differ. All of them must match.
+var migrationKeyAttributes = []string{"name", "resource_group_name"}
+
+// migrationBlocksSchema retrieves removed and import blocks from the module.
+var migrationBlocksSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type: "removed",
+			Body: &hclext.BodySchema{
+				Attributes: []hclext.AttributeSchema{{Name: "from"}},
+				Blocks: []hclext.BlockSchema{
+					{
+						Type: "lifecycle",
+						Body: &hclext.BodySchema{
+							Attributes: []hclext.AttributeSchema{{Name: "destroy"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			Type: "import",
+			Body: &hclext.BodySchema{
+				Attributes: []hclext.AttributeSchema{{Name: "to"}, {Name: "id"}},
+			},
+		},
+	},
+}
+
+// AzurermResourceTypeMigrationRule detects swaps from a legacy azurerm resource type to its
+// replacement. Without removed and import blocks, Terraform plans such a swap as a delete of
+// the legacy resource and a create of the replacement, destroying the live resource.
+type AzurermResourceTypeMigrationRule struct {
+	tflint.DefaultRule
+}
+
+// NewAzurermResourceTypeMigrationRule creates a new resource type migration rule.
+func NewAzurermResourceTypeMigrationRule() *AzurermResourceTypeMigrationRule {
+	return &AzurermResourceTypeMigrationRule{}
+}
+
+// Name returns the rule name.
+func (r *AzurermResourceTypeMigrationRule) Name() string {
+	return "azurerm_resource_type_migration"
+}
+
+// Enabled returns whether the rule is enabled by default.
+func (r *AzurermResourceTypeMigrationRule) Enabled() bool {
+	return true
+}
+
+// Severity returns the rule severity.
+// An unprotected swap destroys the live resource. Swaps that use the safe
+// removed + import pattern are reported with a lower severity.
+func (r *AzurermResourceTypeMigrationRule) Severity() tflint.Severity {
+	return tflint.ERROR
+}
+
+// Link returns the documentation link for this rule.
+func (r *AzurermResourceTypeMigrationRule) Link() string {
+	return project.ReferenceLink(r.Name())
+}
+
+// Check checks for legacy-to-replacement resource type swaps between old and new configurations.
+func (r *AzurermResourceTypeMigrationRule) Check(runner tflint.Runner) error {
+	var blocks *hclext.BodyContent
+	bodySchema := &hclext.BodySchema{}
+	for _, name := range migrationKeyAttributes {
+		bodySchema.Attributes = append(bodySchema.Attributes, hclext.AttributeSchema{Name: name})
+	}
+
+	for _, migration := range resourceTypeMigrations {
+		oldLegacy, err := runner.GetOldResourceContent(migration.From, bodySchema, nil)
+		if err != nil {
+			return fmt.Errorf("get old %s: %w", migration.From, err)
+		}
+		if len(oldLegacy.Blocks) == 0 {
+			continue
+		}
+		newLegacy, err := runner.GetNewResourceContent(migration.From, bodySchema, nil)
+		if err != nil {
+			return fmt.Errorf("get new %s: %w", migration.From, err)
+		}
+
+		// Legacy resources that no longer exist under the same name
+		stillPresent := make(map[string]bool)
+		for _, block := range newLegacy.Blocks {
+			if len(block.Labels) >= 2 {
+				stillPresent[block.Labels[1]] = true
+			}
+		}
+		var removedLegacy []*hclext.Block
+		for _, block := range oldLegacy.Blocks {
+			if len(block.Labels) >= 2 && !stillPresent[block.Labels[1]] {
+				removedLegacy = append(removedLegacy, block)
+			}
+		}
+		if len(removedLegacy) == 0 {
+			continue
+		}
+
+		if blocks == nil {
+			blocks, err = runner.GetNewModuleContent(migrationBlocksSchema, nil)
+			if err != nil {
+				return fmt.Errorf("get new removed and import blocks: %w", err)
+			}
+		}
+		protection := migrationBlocks(blocks)
+
+		// Replacement resources that are new in this change
+		var added []*hclext.Block
+		for _, toType := range migration.To {
+			oldReplacement, err := runner.GetOldResourceContent(toType, bodySchema, nil)
+			if err != nil {
+				return fmt.Errorf("get old %s: %w", toType, err)
+			}
+			newReplacement, err := runner.GetNewResourceContent(toType, bodySchema, nil)
+			if err != nil {
+				return fmt.Errorf("get new %s: %w", toType, err)
+			}
+
+			existed := make(map[string]bool)
+			for _, block := range oldReplacement.Blocks {
+				if len(block.Labels) >= 2 {
+					existed[block.Labels[1]] = true
+				}
+			}
+			for _, newBlock := range newReplacement.Blocks {
+				if len(newBlock.Labels) >= 2 && !existed[newBlock.Labels[1]] {
+					added = append(added, newBlock)
+				}
+			}
+		}
+
+		pairs := pairLegacyResources(removedLegacy, added)
+		for _, newBlock := range added {
+			oldBlock, ok := pairs[newBlock]
+			if !ok {
+				continue
+			}
+
+			fromAddr := migration.From + "." + oldBlock.Labels[1]
+			toAddr := newBlock.Labels[0] + "." + newBlock.Labels[1]
+			severity, message := migrationFinding(migration, fromAddr, toAddr, protection)
+			if err := runner.EmitIssue(withSeverity(r, severity), message, newBlock.DefRange); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrationProtection holds the removed and import blocks of a configuration.
+type migrationProtection struct {
+	// Removed maps the addresses of removed blocks to whether they keep the live resource
+	// (lifecycle destroy = false).
+	Removed map[string]bool
+	// Imported holds the addresses of import blocks.
+	Imported map[string]bool
+	// Unresolved is true if a removed or import block refers to an address that could not be
+	// read, which is the case for attributes received over gRPC.
+	Unresolved bool
+}
+
+// migrationBlocks indexes removed and import blocks by the address they refer to.
+func migrationBlocks(content *hclext.BodyContent) migrationProtection {
+	protection := migrationProtection{
+		Removed:  make(map[string]bool),
+		Imported: make(map[string]bool),
+	}
+	if content == nil {
+		return protection
+	}
+
+	for _, block := range content.Blocks {
+		if block.Body == nil {
+			continue
+		}
+		switch block.Type {
+		case "removed":
+			addr := attrAddress(block.Body.Attributes["from"])
+			if addr == "" {
+				protection.Unresolved = protection.Unresolved || block.Body.Attributes["from"] != nil
+				continue
+			}
+			keeps := false
+			for _, lifecycle := range block.Body.Blocks {
+				if lifecycle.Type == "lifecycle" && lifecycle.Body != nil {
+					keeps = evalAttr(lifecycle.Body.Attributes["destroy"]) == "false"
+				}
+			}
+			protection.Removed[addr] = protection.Removed[addr] || keeps
+		case "import":
+			addr := attrAddress(block.Body.Attributes["to"])
+			if addr == "" {
+				protection.Unresolved = protection.Unresolved || block.Body.Attributes["to"] != nil
+				continue
+			}
+			protection.Imported[addr] = true
+		}
+	}
+
+	return protection
+}
+
+// pairLegacyResources pairs new replacement resources with the removed legacy resources
+// they replace. Resources pair when they share the same name label, or otherwise when
+// every key attribute is set on both resources with equal, statically known values.
+// Each legacy resource is paired with at most one replacement.
+func pairLegacyResources(legacy, replacements []*hclext.Block) map[*hclext.Block]*hclext.Block {
+	pairs := make(map[*hclext.Block]*hclext.Block)
+	paired := make(map[*hclext.Block]bool)
+	for _, match := range []func(oldBlock, newBlock *hclext.Block) bool{
+		func(oldBlock, newBlock *hclext.Block) bool { return oldBlock.Labels[1] == newBlock.Labels[1] },
+		sameKeyAttributes,
+	} {
+		for _, newBlock := range replacements {
+			if _, ok := pairs[newBlock]; ok {
+				continue
+			}
+			for _, oldBlock := range legacy {
+				if !paired[oldBlock] && match(oldBlock, newBlock) {
+					pairs[newBlock] = oldBlock
+					paired[oldBlock] = true
+					break
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// sameKeyAttributes reports whether two resources have the same identifying attributes.
+// Attributes that are unset or cannot be statically resolved do not identify a resource.
+func sameKeyAttributes(oldBlock, newBlock *hclext.Block) bool {
+	for _, name := range migrationKeyAttributes {
+		oldAttr := getAttributeByPath(oldBlock, name)
+		newAttr := getAttributeByPath(newBlock, name)
+		if oldAttr == nil || newAttr == nil {
+			return false
+		}
+		oldVal, newVal := evalAttr(oldAttr), evalAttr(newAttr)
+		if isUnresolvedValue(oldVal) || isUnresolvedValue(newVal) || oldVal != newVal {
+			return false
+		}
+	}
+	return true
+}
+
+// migrationFinding builds the severity and message for a detected resource type swap.
+// When the configuration has removed or import blocks whose addresses could not be read,
+// missing protection is reported as unverified instead of as a destroyed resource.
+func migrationFinding(migration resourceTypeMigration, fromAddr, toAddr string, protection migrationProtection) (tflint.Severity, string) {
+	removedKeeps, imported := protection.Removed[fromAddr], protection.Imported[toAddr]
+	if !migration.Importable {
+		if removedKeeps {
+			return tflint.WARNING, fmt.Sprintf(
+				"%s replaces %s, which is a different Azure resource. "+
+					"The removed block keeps the legacy resource, but data must be migrated to the new resource.",
+				toAddr, fromAddr,
+			)
+		}
+		if protection.Unresolved {
+			return tflint.WARNING, fmt.Sprintf(
+				"%s replaces %s, which is a different Azure resource. The addresses of the removed blocks cannot be read, "+
+					"so protection of the legacy resource cannot be verified. "+
+					"Migrate data first and make sure a removed block for %s sets lifecycle { destroy = false }.",
+				toAddr, fromAddr, fromAddr,
+			)
+		}
+		return tflint.ERROR, fmt.Sprintf(
+			"%s replaces %s, which is a different Azure resource; the legacy resource will be destroyed. "+
+				"Migrate data first and add a removed block for %s with lifecycle { destroy = false }.",
+			toAddr, fromAddr, fromAddr,
+		)
+	}
+
+	if removedKeeps && imported {
+		return tflint.NOTICE, fmt.Sprintf(
+			"%s replaces %s using removed and import blocks; the live resource is adopted without recreation.",
+			toAddr, fromAddr,
+		)
+	}
+
+	var missing []string
+	if !removedKeeps {
+		missing = append(missing, fmt.Sprintf("a removed block for %s with lifecycle { destroy = false }", fromAddr))
+	}
+	if !imported {
+		missing = append(missing, fmt.Sprintf("an import block for %s", toAddr))
+	}
+	if protection.Unresolved {
+		return tflint.WARNING, fmt.Sprintf(
+			"%s replaces %s. The addresses of the removed and import blocks cannot be read, "+
+				"so protection of the live resource cannot be verified. Make sure there is %s.",
+			toAddr, fromAddr, strings.Join(missing, " and "),
+		)
+	}
+	return tflint.ERROR, fmt.Sprintf(
+		"%s replaces %s; Terraform will destroy the live resource and create a new one. Add %s.",
+		toAddr, fromAddr, strings.Join(missing, " and "),
+	)
+}
+
+// isUnresolvedValue reports whether an evaluated attribute string is a placeholder
+// for a value that could not be statically determined, including the "${...}" expressions
+// of dynamic block content whose iterator is unknown.
+func isUnresolvedValue(v string) bool {
+	switch v {
+	case "<dynamic>", "<unknown>", "<null>", "<not set>":
+		return true
+	}
+	return strings.HasPrefix(v, "${") && strings.HasSuffix(v, "}")
+}
@@ -0,0 +1,395 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+func TestResourceTypeMigration_Metadata(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+	if rule.Name() != "azurerm_resource_type_migration" {
+		t.Errorf("Expected rule name to be 'azurerm_resource_type_migration', got '%s'", rule.Name())
+	}
+	if !rule.Enabled() {
+		t.Error("Expected rule to be enabled by default")
+	}
+	if rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected severity to be ERROR, got %v", rule.Severity())
+	}
+	if !strings.Contains(rule.Link(), "azurerm_resource_type_migration") {
+		t.Errorf("Expected link to contain rule name, got '%s'", rule.Link())
+	}
+}
+
+func TestResourceTypeMigration_UnsafeSwap(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "web" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_linux_web_app" "web" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.ERROR {
+		t.Errorf("Expected ERROR severity, got %v", issue.Rule.Severity())
+	}
+	for _, want := range []string{
+		"azurerm_linux_web_app.web replaces azurerm_app_service.web",
+		"a removed block for azurerm_app_service.web",
+		"an import block for azurerm_linux_web_app.web",
+	} {
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, issue.Message)
+		}
+	}
+}
+
+func TestResourceTypeMigration_SafeSwap(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_sql_server" "db" {
+    name                = "my-sql"
+    resource_group_name = "my-rg"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+removed {
+    from = azurerm_sql_server.db
+    lifecycle {
+        destroy = false
+    }
+}
+
+import {
+    to = azurerm_mssql_server.database
+    id = "/subscriptions/000/resourceGroups/my-rg/providers/Microsoft.Sql/servers/my-sql"
+}
+
+resource "azurerm_mssql_server" "database" {
+    name                = "my-sql"
+    resource_group_name = "my-rg"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if issue.Rule.Severity() != tflint.NOTICE {
+		t.Errorf("Expected NOTICE severity, got %v", issue.Rule.Severity())
+	}
+	if !strings.Contains(issue.Message, "using removed and import blocks") {
+		t.Errorf("Expected message to report the safe pattern, got %q", issue.Message)
+	}
+}
+
+func TestResourceTypeMigration_UnresolvedBlocks(t *testing.T) {
+	legacy := `
+resource "azurerm_sql_server" "db" {
+    name                = "my-sql"
+    resource_group_name = "my-rg"
+}`
+	replacement := `
+resource "azurerm_mssql_server" "database" {
+    name                = "my-sql"
+    resource_group_name = "my-rg"
+}`
+	protection := `
+removed {
+    from = azurerm_sql_server.db
+    lifecycle {
+        destroy = false
+    }
+}
+
+import {
+    to = azurerm_mssql_server.database
+    id = "/subscriptions/000/resourceGroups/my-rg/providers/Microsoft.Sql/servers/my-sql"
+}
+`
+
+	tests := []struct {
+		name         string
+		newConfig    string
+		wantSeverity tflint.Severity
+		wantText     string
+	}{
+		{"protected", protection + replacement, tflint.WARNING, "protection of the live resource cannot be verified"},
+		{"unprotected", replacement, tflint.ERROR, "Terraform will destroy the live resource"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermResourceTypeMigrationRule()
+
+			// Over gRPC, the references in from and to cannot be read
+			runner := &grpcRunner{helper.TestRunner(t,
+				map[string]string{"main.tf": legacy},
+				map[string]string{"main.tf": tt.newConfig},
+			)}
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			issue := runner.Issues[0]
+			if issue.Rule.Severity() != tt.wantSeverity {
+				t.Errorf("Expected %v severity, got %v", tt.wantSeverity, issue.Rule.Severity())
+			}
+			if !strings.Contains(issue.Message, tt.wantText) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantText, issue.Message)
+			}
+		})
+	}
+}
+
+func TestResourceTypeMigration_PairedOnce(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "legacy" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_linux_web_app" "first" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}
+
+resource "azurerm_windows_web_app" "second" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// One legacy resource is replaced by one resource at most
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "azurerm_linux_web_app.first replaces azurerm_app_service.legacy") {
+		t.Errorf("Unexpected message: %q", runner.Issues[0].Message)
+	}
+}
+
+func TestResourceTypeMigration_RemovedWithoutImport(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_virtual_machine" "vm" {
+    name = "my-vm"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+removed {
+    from = azurerm_virtual_machine.vm
+    lifecycle {
+        destroy = false
+    }
+}
+
+resource "azurerm_linux_virtual_machine" "vm" {
+    name = "my-vm"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	msg := runner.Issues[0].Message
+	if strings.Contains(msg, "a removed block") {
+		t.Errorf("Did not expect the removed block to be reported missing, got %q", msg)
+	}
+	if !strings.Contains(msg, "an import block for azurerm_linux_virtual_machine.vm") {
+		t.Errorf("Expected the import block to be reported missing, got %q", msg)
+	}
+}
+
+func TestResourceTypeMigration_NonImportable(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_mysql_server" "db" {
+    name = "my-mysql"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_mysql_flexible_server" "db" {
+    name = "my-mysql"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "different Azure resource") {
+		t.Errorf("Expected message to explain the replacement is a different resource, got %q", runner.Issues[0].Message)
+	}
+}
+
+func TestResourceTypeMigration_MatchByKeyAttributes(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "legacy" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_windows_web_app" "modern" {
+    name                = "my-app"
+    resource_group_name = "my-rg"
+}
+
+resource "azurerm_linux_web_app" "unrelated" {
+    name                = "other-app"
+    resource_group_name = "my-rg"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if !strings.Contains(runner.Issues[0].Message, "azurerm_windows_web_app.modern replaces azurerm_app_service.legacy") {
+		t.Errorf("Unexpected message: %q", runner.Issues[0].Message)
+	}
+}
+
+func TestResourceTypeMigration_PartialKeyAttributes(t *testing.T) {
+	tests := []struct {
+		name   string
+		legacy string
+		modern string
+	}{
+		{
+			name:   "same resource group, other name",
+			legacy: `name = "my-app"` + "\n" + `resource_group_name = "my-rg"`,
+			modern: `name = var.name` + "\n" + `resource_group_name = "my-rg"`,
+		},
+		{
+			name:   "same name, unset resource group",
+			legacy: `name = "my-app"` + "\n" + `resource_group_name = "my-rg"`,
+			modern: `name = "my-app"`,
+		},
+		{
+			name:   "same location only",
+			legacy: `name = "my-app"` + "\n" + `location = "westeurope"`,
+			modern: `name = var.name` + "\n" + `location = "westeurope"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermResourceTypeMigrationRule()
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": "resource \"azurerm_app_service\" \"legacy\" {\n" + tt.legacy + "\n}"},
+				map[string]string{"main.tf": "resource \"azurerm_linux_web_app\" \"modern\" {\n" + tt.modern + "\n}"},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+
+			// A resource only replaces the legacy resource if it has the same name and resource group
+			helper.AssertNoIssues(t, runner.Issues)
+		})
+	}
+}
+
+func TestResourceTypeMigration_LegacyKept(t *testing.T) {
+	rule := NewAzurermResourceTypeMigrationRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "web" {
+    name = "my-app"
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_app_service" "web" {
+    name = "my-app"
+}
+
+resource "azurerm_linux_web_app" "web" {
+    name = "my-app"
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+
+	// The legacy resource is still managed, so this is not a swap
+	helper.AssertNoIssues(t, runner.Issues)
+}
@@ -0,0 +1,159 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+// resourceCriticality classifies resource types by the impact of recreating them.
+type resourceCriticality string
+
+const (
+	// criticalityStateful resources hold data that is lost when they are recreated.
+	criticalityStateful resourceCriticality = "stateful"
+	// criticalityNetwork resources carry traffic for other resources.
+	criticalityNetwork resourceCriticality = "network"
+	// criticalityIdentity resources hold identities, keys, secrets or access assignments.
+	criticalityIdentity resourceCriticality = "identity"
+	// criticalityStateless resources are cheap to recreate without lasting impact.
+	criticalityStateless resourceCriticality = "stateless"
+)
+
+// criticalityTiers lists the tiers in order of decreasing impact.
+var criticalityTiers = []resourceCriticality{
+	criticalityStateful,
+	criticalityNetwork,
+	criticalityIdentity,
+	criticalityStateless,
+}
+
+// defaultCriticalitySeverities is the severity reported for each tier.
+// Recreating a stateless resource is a warning; everything else blocks.
+var defaultCriticalitySeverities = map[resourceCriticality]tflint.Severity{
+	criticalityStateful:  tflint.ERROR,
+	criticalityNetwork:   tflint.ERROR,
+	criticalityIdentity:  tflint.ERROR,
+	criticalityStateless: tflint.WARNING,
+}
+
+// criticalityImpacts explains the impact of recreating a resource of each tier.
+var criticalityImpacts = map[resourceCriticality]string{
+	criticalityStateful: "Data stored in the resource is lost unless it is backed up and restored.",
+	criticalityNetwork:  "Resources that depend on it lose connectivity while it is recreated.",
+	criticalityIdentity: "Principal IDs, keys and access assignments tied to the resource change.",
+}
+
+// resourceCriticalities is the built-in classification of azurerm resource types.
+// Unclassified resource types keep the rule's default severity.
+var resourceCriticalities = map[string]resourceCriticality{
+	// Databases, storage and other data-bearing resources
+	"azurerm_cosmosdb_account":                    criticalityStateful,
+	"azurerm_cosmosdb_cassandra_keyspace":         criticalityStateful,
+	"azurerm_cosmosdb_gremlin_database":           criticalityStateful,
+	"azurerm_cosmosdb_mongo_collection":           criticalityStateful,
+	"azurerm_cosmosdb_mongo_database":             criticalityStateful,
+	"azurerm_cosmosdb_sql_container":              criticalityStateful,
+	"azurerm_cosmosdb_sql_database":               criticalityStateful,
+	"azurerm_cosmosdb_table":                      criticalityStateful,
+	"azurerm_data_lake_gen2_filesystem":           criticalityStateful,
+	"azurerm_eventhub":                            criticalityStateful,
+	"azurerm_eventhub_namespace":                  criticalityStateful,
+	"azurerm_kusto_cluster":                       criticalityStateful,
+	"azurerm_kusto_database":                      criticalityStateful,
+	"azurerm_log_analytics_workspace":             criticalityStateful,
+	"azurerm_managed_disk":                        criticalityStateful,
+	"azurerm_mariadb_database":                    criticalityStateful,
+	"azurerm_mariadb_server":                      criticalityStateful,
+	"azurerm_mssql_database":                      criticalityStateful,
+	"azurerm_mssql_managed_database":              criticalityStateful,
+	"azurerm_mssql_managed_instance":              criticalityStateful,
+	"azurerm_mssql_server":                        criticalityStateful,
+	"azurerm_mysql_database":                      criticalityStateful,
+	"azurerm_mysql_flexible_database":             criticalityStateful,
+	"azurerm_mysql_flexible_server":               criticalityStateful,
+	"azurerm_mysql_server":                        criticalityStateful,
+	"azurerm_postgresql_database":                 criticalityStateful,
+	"azurerm_postgresql_flexible_server":          criticalityStateful,
+	"azurerm_postgresql_flexible_server_database": criticalityStateful,
+	"azurerm_postgresql_server":                   criticalityStateful,
+	"azurerm_recovery_services_vault":             criticalityStateful,
+	"azurerm_redis_cache":                         criticalityStateful,
+	"azurerm_search_service":                      criticalityStateful,
+	"azurerm_servicebus_namespace":                criticalityStateful,
+	"azurerm_servicebus_queue":                    criticalityStateful,
+	"azurerm_servicebus_topic":                    criticalityStateful,
+	"azurerm_snapshot":                            criticalityStateful,
+	"azurerm_sql_database":                        criticalityStateful,
+	"azurerm_sql_server":                          criticalityStateful,
+	"azurerm_storage_account":                     criticalityStateful,
+	"azurerm_storage_blob":                        criticalityStateful,
+	"azurerm_storage_container":                   criticalityStateful,
+	"azurerm_storage_queue":                       criticalityStateful,
+	"azurerm_storage_share":                       criticalityStateful,
+	"azurerm_storage_table":                       criticalityStateful,
+	"azurerm_synapse_workspace":                   criticalityStateful,
+
+	// Networking that carries traffic for other resources
+	"azurerm_application_gateway":                criticalityNetwork,
+	"azurerm_dns_zone":                           criticalityNetwork,
+	"azurerm_express_route_circuit":              criticalityNetwork,
+	"azurerm_express_route_gateway":              criticalityNetwork,
+	"azurerm_firewall":                           criticalityNetwork,
+	"azurerm_frontdoor":                          criticalityNetwork,
+	"azurerm_lb":                                 criticalityNetwork,
+	"azurerm_nat_gateway":                        criticalityNetwork,
+	"azurerm_private_dns_zone":                   criticalityNetwork,
+	"azurerm_private_endpoint":                   criticalityNetwork,
+	"azurerm_public_ip":                          criticalityNetwork,
+	"azurerm_public_ip_prefix":                   criticalityNetwork,
+	"azurerm_subnet":                             criticalityNetwork,
+	"azurerm_virtual_hub":                        criticalityNetwork,
+	"azurerm_virtual_network":                    criticalityNetwork,
+	"azurerm_virtual_network_gateway":            criticalityNetwork,
+	"azurerm_virtual_network_gateway_connection": criticalityNetwork,
+	"azurerm_virtual_wan":                        criticalityNetwork,
+	"azurerm_vpn_gateway":                        criticalityNetwork,
+
+	// Identities, secrets and access control
+	"azurerm_key_vault":                                  criticalityIdentity,
+	"azurerm_key_vault_certificate":                      criticalityIdentity,
+	"azurerm_key_vault_key":                              criticalityIdentity,
+	"azurerm_key_vault_managed_hardware_security_module": criticalityIdentity,
+	"azurerm_key_vault_secret":                           criticalityIdentity,
+	"azurerm_role_assignment":                            criticalityIdentity,
+	"azurerm_role_definition":                            criticalityIdentity,
+	"azurerm_user_assigned_identity":                     criticalityIdentity,
+
+	// Cheap resources that can be recreated without lasting impact
+	"azurerm_application_security_group":                   criticalityStateless,
+	"azurerm_monitor_action_group":                         criticalityStateless,
+	"azurerm_monitor_diagnostic_setting":                   criticalityStateless,
+	"azurerm_monitor_metric_alert":                         criticalityStateless,
+	"azurerm_network_interface_security_group_association": criticalityStateless,
+	"azurerm_network_security_group":                       criticalityStateless,
+	"azurerm_network_security_rule":                        criticalityStateless,
+	"azurerm_resource_group_policy_assignment":             criticalityStateless,
+	"azurerm_route":                                        criticalityStateless,
+	"azurerm_route_table":                                  criticalityStateless,
+	"azurerm_subnet_network_security_group_association":    criticalityStateless,
+	"azurerm_subnet_route_table_association":               criticalityStateless,
+}
+
+// parseCriticality parses a tier name as used in rule configuration.
+func parseCriticality(name string) (resourceCriticality, error) {
+	for _, tier := range criticalityTiers {
+		if strings.EqualFold(name, string(tier)) {
+			return tier, nil
+		}
+	}
+	names := make([]string, len(criticalityTiers))
+	for i, tier := range criticalityTiers {
+		names[i] = string(tier)
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("unknown criticality %q, expected one of %s", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,46 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResourceCriticalities(t *testing.T) {
+	tiers := make(map[resourceCriticality]bool)
+	for _, tier := range criticalityTiers {
+		tiers[tier] = true
+		if _, ok := defaultCriticalitySeverities[tier]; !ok {
+			t.Errorf("Expected a default severity for tier %q", tier)
+		}
+	}
+
+	for resourceType, tier := range resourceCriticalities {
+		if !strings.HasPrefix(resourceType, "azurerm_") {
+			t.Errorf("Expected %q to be an azurerm resource type", resourceType)
+		}
+		if !tiers[tier] {
+			t.Errorf("Unknown tier %q for %q", tier, resourceType)
+		}
+	}
+}
+
+func TestParseCriticality(t *testing.T) {
+	for name, want := range map[string]resourceCriticality{
+		"stateful":  criticalityStateful,
+		"Network":   criticalityNetwork,
+		"IDENTITY":  criticalityIdentity,
+		"stateless": criticalityStateless,
+	} {
+		got, err := parseCriticality(name)
+		if err != nil {
+			t.Errorf("parseCriticality(%q) returned error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("parseCriticality(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if _, err := parseCriticality("critical"); err == nil || !strings.Contains(err.Error(), "identity, network, stateful, stateless") {
+		t.Errorf("Expected error listing the tiers, got %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// dynamicBlockSchema returns the schema of the dynamic blocks that generate nested blocks,
+// whose content holds the attributes in attrPaths.
+func dynamicBlockSchema(attrPaths []string) hclext.BlockSchema {
+	return hclext.BlockSchema{
+		Type:       "dynamic",
+		LabelNames: []string{"type"},
+		Body: &hclext.BodySchema{
+			Attributes: []hclext.AttributeSchema{{Name: "for_each"}, {Name: "iterator"}},
+			Blocks:     []hclext.BlockSchema{{Type: "content", Body: buildBodySchema(attrPaths)}},
+		},
+	}
+}
+
+// expandDynamicBlocks replaces the dynamic blocks in the resources of a body content
+// with the nested blocks they generate, so that getAttributeByPath finds their attributes.
+func expandDynamicBlocks(content *hclext.BodyContent) {
+	if content == nil {
+		return
+	}
+	for _, block := range content.Blocks {
+		expandBody(block.Body, nil)
+	}
+}
+
+// expandBody expands the dynamic blocks of a body. Generated blocks follow the literal
+// blocks of the body. When for_each is statically known, one block is generated per
+// element, with the iterator bound in ctx; otherwise a single block is generated from the
+// content, whose attributes that depend on the iterator hold their expression as a
+// "${...}" string, so that changes to the content are still compared.
+func expandBody(body *hclext.BodyContent, ctx *hcl.EvalContext) {
+	if body == nil {
+		return
+	}
+
+	var blocks []*hclext.Block
+	var generated []*hclext.Block
+	for _, block := range body.Blocks {
+		if block.Type != "dynamic" {
+			expandBody(block.Body, ctx)
+			blocks = append(blocks, block)
+			continue
+		}
+		generated = append(generated, generateBlocks(block, ctx)...)
+	}
+	body.Blocks = append(blocks, generated...)
+}
+
+// generateBlocks returns the nested blocks generated by a dynamic block.
+func generateBlocks(dynamic *hclext.Block, ctx *hcl.EvalContext) []*hclext.Block {
+	if len(dynamic.Labels) == 0 || dynamic.Body == nil {
+		return nil
+	}
+	blockType := dynamic.Labels[0]
+	var content *hclext.Block
+	for _, block := range dynamic.Body.Blocks {
+		if block.Type == "content" {
+			content = block
+		}
+	}
+	if content == nil {
+		return nil
+	}
+
+	iterator := blockType
+	if name := attrAddress(dynamic.Body.Attributes["iterator"]); name != "" {
+		iterator = name
+	}
+
+	forEach, ok := evalInContext(dynamic.Body.Attributes["for_each"], ctx)
+	if !ok || forEach.IsNull() || !forEach.IsWhollyKnown() || !forEach.CanIterateElements() {
+		// The elements are unknown, so compare the content as written
+		return []*hclext.Block{generateBlock(blockType, content, dynamic.DefRange, ctx)}
+	}
+
+	var blocks []*hclext.Block
+	for it := forEach.ElementIterator(); it.Next(); {
+		key, value := it.Element()
+		if forEach.Type().IsSetType() {
+			key = value
+		}
+		elemCtx := childContext(ctx, iterator, cty.ObjectVal(map[string]cty.Value{"key": key, "value": value}))
+		blocks = append(blocks, generateBlock(blockType, content, dynamic.DefRange, elemCtx))
+	}
+	return blocks
+}
+
+// generateBlock builds a nested block of blockType from the content of a dynamic block,
+// evaluating its attributes in ctx.
+func generateBlock(blockType string, content *hclext.Block, defRange hcl.Range, ctx *hcl.EvalContext) *hclext.Block {
+	body := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	if content.Body != nil {
+		for name, attr := range content.Body.Attributes {
+			generated := &hclext.Attribute{Name: name, Expr: attr.Expr, Range: attr.Range, NameRange: attr.NameRange}
+			if val, ok := evalInContext(attr, ctx); ok {
+				generated.Value = val
+			} else if attr.Expr != nil {
+				generated.Value = cty.StringVal("${" + exprString(attr.Expr) + "}")
+			}
+			body.Attributes[name] = generated
+		}
+		body.Blocks = content.Body.Blocks
+	}
+	expandBody(body, ctx)
+	return &hclext.Block{Type: blockType, Body: body, DefRange: defRange}
+}
+
+// evalInContext evaluates an attribute with the iterators of the enclosing dynamic blocks.
+// Without an expression, which is the case over gRPC, the pre-evaluated value is used.
+func evalInContext(attr *hclext.Attribute, ctx *hcl.EvalContext) (cty.Value, bool) {
+	if attr == nil {
+		return cty.NilVal, false
+	}
+	if attr.Expr == nil || ctx == nil {
+		val, ok := attrValue(attr)
+		if !ok || !val.IsWhollyKnown() {
+			return cty.NilVal, false
+		}
+		return val, true
+	}
+	val, diags := attr.Expr.Value(ctx)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// childContext returns an evaluation context that binds an iterator on top of ctx.
+func childContext(ctx *hcl.EvalContext, name string, value cty.Value) *hcl.EvalContext {
+	vars := map[string]cty.Value{name: value}
+	if ctx == nil {
+		return &hcl.EvalContext{Variables: vars}
+	}
+	child := ctx.NewChild()
+	child.Variables = vars
+	return child
+}
@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+func TestDynamicBlockSchema(t *testing.T) {
+	bodySchema := buildBodySchema([]string{"location", "ip_configuration.subnet_id", "security_rule.name"})
+
+	var dynamic *hclext.BlockSchema
+	for i := range bodySchema.Blocks {
+		if bodySchema.Blocks[i].Type == "dynamic" {
+			dynamic = &bodySchema.Blocks[i]
+		}
+	}
+	if dynamic == nil {
+		t.Fatal("expected a dynamic block schema for nested blocks")
+	}
+	if len(dynamic.LabelNames) != 1 || len(dynamic.Body.Blocks) != 1 || dynamic.Body.Blocks[0].Type != "content" {
+		t.Fatalf("unexpected dynamic block schema: %+v", dynamic)
+	}
+
+	var contentAttrs []string
+	for _, attr := range dynamic.Body.Blocks[0].Body.Attributes {
+		contentAttrs = append(contentAttrs, attr.Name)
+	}
+	if strings.Join(contentAttrs, ",") != "name,subnet_id" {
+		t.Errorf("content attributes = %v, want [name subnet_id]", contentAttrs)
+	}
+}
+
+func TestForceNew_DynamicBlocks(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_example": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true}
+					},
+					"block_types": {
+						"ip_configuration": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"name": {"type": "string", "required": true},
+									"subnet_id": {"type": "string", "optional": true, "force_new": true}
+								},
+								"block_types": {
+									"public_ip": {
+										"nesting_mode": "list",
+										"block": {
+											"attributes": {
+												"sku": {"type": "string", "optional": true, "force_new": true}
+											}
+										}
+									}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	literal := `
+  ip_configuration {
+    name      = "primary"
+    subnet_id = "subnet-a"
+  }`
+	staticDynamic := func(subnet string) string {
+		return `
+  dynamic "ip_configuration" {
+    for_each = [{ name = "primary", subnet = "` + subnet + `" }]
+    content {
+      name      = ip_configuration.value.name
+      subnet_id = ip_configuration.value.subnet
+    }
+  }`
+	}
+	variableDynamic := func(expr string) string {
+		return `
+  dynamic "ip_configuration" {
+    for_each = var.ip_configurations
+    iterator = ip
+    content {
+      name      = ip.value.name
+      subnet_id = ` + expr + `
+    }
+  }`
+	}
+	nestedDynamic := func(sku string) string {
+		return `
+  dynamic "ip_configuration" {
+    for_each = { primary = "` + sku + `" }
+    content {
+      name = ip_configuration.key
+      dynamic "public_ip" {
+        for_each = [ip_configuration.value]
+        content {
+          sku = public_ip.value
+        }
+      }
+    }
+  }`
+	}
+
+	tests := []struct {
+		name       string
+		oldBody    string
+		newBody    string
+		wantIssues []string
+	}{
+		{"static for_each changed", staticDynamic("subnet-a"), staticDynamic("subnet-b"), []string{`"ip_configuration.subnet_id"`, "(old: subnet-a, new: subnet-b)"}},
+		{"static for_each unchanged", staticDynamic("subnet-a"), staticDynamic("subnet-a"), nil},
+		{"literal block moved to dynamic", literal, staticDynamic("subnet-a"), nil},
+		{"literal block moved to changed dynamic", literal, staticDynamic("subnet-b"), []string{"(old: subnet-a, new: subnet-b)"}},
+		{"unknown for_each unchanged", variableDynamic("ip.value.subnet_id"), variableDynamic("ip.value.subnet_id"), nil},
+		{"unknown for_each content changed", variableDynamic("ip.value.subnet_id"), variableDynamic("var.subnet_id"), []string{"(old: ${ip.value.subnet_id}, new: ${var.subnet_id})"}},
+		{"unknown for_each with static content", variableDynamic(`"subnet-a"`), variableDynamic(`"subnet-b"`), []string{"(old: subnet-a, new: subnet-b)"}},
+		{"nested dynamic changed", nestedDynamic("Basic"), nestedDynamic("Standard"), []string{`"ip_configuration.public_ip.sku"`, "(old: Basic, new: Standard)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &AzurermForceNewRule{schema: s, now: time.Now}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": "resource \"azurerm_example\" \"example\" {\n  name = \"example\"" + tt.oldBody + "\n}"},
+				map[string]string{"main.tf": "resource \"azurerm_example\" \"example\" {\n  name = \"example\"" + tt.newBody + "\n}"},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(tt.wantIssues) == 0 {
+				if len(runner.Issues) != 0 {
+					t.Fatalf("Expected no issues, got %d: %v", len(runner.Issues), runner.Issues)
+				}
+				return
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			for _, want := range tt.wantIssues {
+				if !strings.Contains(runner.Issues[0].Message, want) {
+					t.Errorf("Expected message to contain %q, got %q", want, runner.Issues[0].Message)
+				}
+			}
+		})
+	}
+}
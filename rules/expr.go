@@ -0,0 +1,186 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// attrTraversals returns the static references in an attribute expression.
+// A single reference (e.g. `from = azurerm_app_service.example`) yields one traversal,
+// a list of references (e.g. `ignore_changes = [location, tags]`) yields one per element.
+// Returns nil when the expression is unavailable, which is the case for attributes
+// received over gRPC, or when it is not made of static references.
+func attrTraversals(attr *hclext.Attribute) []hcl.Traversal {
+	if attr == nil || attr.Expr == nil {
+		return nil
+	}
+
+	if traversal, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+		return []hcl.Traversal{traversal}
+	}
+
+	exprs, diags := hcl.ExprList(attr.Expr)
+	if diags.HasErrors() {
+		return nil
+	}
+	var traversals []hcl.Traversal
+	for _, expr := range exprs {
+		traversal, diags := hcl.AbsTraversalForExpr(expr)
+		if diags.HasErrors() {
+			continue
+		}
+		traversals = append(traversals, traversal)
+	}
+	return traversals
+}
+
+// attrAddress returns the reference in an attribute as an address string,
+// e.g. "azurerm_app_service.example" or "module.web.azurerm_app_service.this[0]".
+// Returns an empty string when the attribute is not a single static reference.
+func attrAddress(attr *hclext.Attribute) string {
+	traversals := attrTraversals(attr)
+	if len(traversals) != 1 {
+		return ""
+	}
+	return traversalString(traversals[0])
+}
+
+// traversalString formats a traversal as a Terraform address string.
+func traversalString(traversal hcl.Traversal) string {
+	var sb strings.Builder
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			sb.WriteString(s.Name)
+		case hcl.TraverseAttr:
+			sb.WriteString(".")
+			sb.WriteString(s.Name)
+		case hcl.TraverseIndex:
+			if s.Key.Type() == cty.String {
+				fmt.Fprintf(&sb, "[%q]", s.Key.AsString())
+			} else {
+				sb.WriteString("[")
+				sb.WriteString(formatCtyValue(s.Key))
+				sb.WriteString("]")
+			}
+		case hcl.TraverseSplat:
+			sb.WriteString("[*]")
+		}
+	}
+	return sb.String()
+}
+
+// operators maps HCL operations to their symbols, for exprString.
+var operators = map[*hclsyntax.Operation]string{
+	hclsyntax.OpLogicalOr:          "||",
+	hclsyntax.OpLogicalAnd:         "&&",
+	hclsyntax.OpLogicalNot:         "!",
+	hclsyntax.OpEqual:              "==",
+	hclsyntax.OpNotEqual:           "!=",
+	hclsyntax.OpGreaterThan:        ">",
+	hclsyntax.OpGreaterThanOrEqual: ">=",
+	hclsyntax.OpLessThan:           "<",
+	hclsyntax.OpLessThanOrEqual:    "<=",
+	hclsyntax.OpAdd:                "+",
+	hclsyntax.OpSubtract:           "-",
+	hclsyntax.OpMultiply:           "*",
+	hclsyntax.OpDivide:             "/",
+	hclsyntax.OpModulo:             "%",
+	hclsyntax.OpNegate:             "-",
+}
+
+// exprString formats an expression as normalized HCL source, e.g. `lower(each.value.name)`,
+// so that expressions which cannot be evaluated can still be compared.
+// Whitespace and comments are not preserved, so only semantic edits change the result.
+// Expressions that are not native HCL syntax are formatted as "<expression>".
+func exprString(expr hcl.Expression) string {
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		return formatNestedCtyValue(e.Val)
+	case *hclsyntax.ScopeTraversalExpr:
+		return traversalString(e.Traversal)
+	case *hclsyntax.RelativeTraversalExpr:
+		return exprString(e.Source) + traversalString(e.Traversal)
+	case *hclsyntax.AnonSymbolExpr:
+		return ""
+	case *hclsyntax.SplatExpr:
+		return exprString(e.Source) + "[*]" + exprString(e.Each)
+	case *hclsyntax.ParenthesesExpr:
+		return "(" + exprString(e.Expression) + ")"
+	case *hclsyntax.TemplateWrapExpr:
+		return `"${` + exprString(e.Wrapped) + `}"`
+	case *hclsyntax.TemplateExpr:
+		var sb strings.Builder
+		sb.WriteString(`"`)
+		for _, part := range e.Parts {
+			if lit, ok := part.(*hclsyntax.LiteralValueExpr); ok && lit.Val.Type() == cty.String {
+				sb.WriteString(lit.Val.AsString())
+				continue
+			}
+			sb.WriteString("${" + exprString(part) + "}")
+		}
+		sb.WriteString(`"`)
+		return sb.String()
+	case *hclsyntax.FunctionCallExpr:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = exprString(arg)
+		}
+		if e.ExpandFinal && len(args) > 0 {
+			args[len(args)-1] += "..."
+		}
+		return e.Name + "(" + strings.Join(args, ", ") + ")"
+	case *hclsyntax.ConditionalExpr:
+		return exprString(e.Condition) + " ? " + exprString(e.TrueResult) + " : " + exprString(e.FalseResult)
+	case *hclsyntax.BinaryOpExpr:
+		return exprString(e.LHS) + " " + operators[e.Op] + " " + exprString(e.RHS)
+	case *hclsyntax.UnaryOpExpr:
+		return operators[e.Op] + exprString(e.Val)
+	case *hclsyntax.IndexExpr:
+		return exprString(e.Collection) + "[" + exprString(e.Key) + "]"
+	case *hclsyntax.TupleConsExpr:
+		elems := make([]string, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			elems[i] = exprString(elem)
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case *hclsyntax.ObjectConsExpr:
+		items := make([]string, len(e.Items))
+		for i, item := range e.Items {
+			items[i] = exprString(item.KeyExpr) + " = " + exprString(item.ValueExpr)
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	case *hclsyntax.ObjectConsKeyExpr:
+		if name := hcl.ExprAsKeyword(e.Wrapped); name != "" && !e.ForceNonLiteral {
+			return name
+		}
+		return exprString(e.Wrapped)
+	case *hclsyntax.ForExpr:
+		vars := e.ValVar
+		if e.KeyVar != "" {
+			vars = e.KeyVar + ", " + e.ValVar
+		}
+		body := exprString(e.ValExpr)
+		if e.KeyExpr != nil {
+			body = exprString(e.KeyExpr) + " => " + body
+		}
+		if e.Group {
+			body += "..."
+		}
+		if e.CondExpr != nil {
+			body += " if " + exprString(e.CondExpr)
+		}
+		if e.KeyExpr != nil {
+			return "{for " + vars + " in " + exprString(e.CollExpr) + " : " + body + "}"
+		}
+		return "[for " + vars + " in " + exprString(e.CollExpr) + " : " + body + "]"
+	default:
+		return "<expression>"
+	}
+}
@@ -0,0 +1,132 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// grpcRunner returns content like the gRPC runner: attributes carry only their evaluated
+// value and no expression. Values that need an evaluation context, such as references,
+// are unknown.
+type grpcRunner struct {
+	*helper.Runner
+}
+
+func (r *grpcRunner) GetOldModuleContent(schema *hclext.BodySchema, opts *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	content, err := r.Runner.GetOldModuleContent(schema, opts)
+	return withoutExpressions(content), err
+}
+
+func (r *grpcRunner) GetNewModuleContent(schema *hclext.BodySchema, opts *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	content, err := r.Runner.GetNewModuleContent(schema, opts)
+	return withoutExpressions(content), err
+}
+
+func (r *grpcRunner) GetOldResourceContent(resourceType string, schema *hclext.BodySchema, opts *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	content, err := r.Runner.GetOldResourceContent(resourceType, schema, opts)
+	return withoutExpressions(content), err
+}
+
+func (r *grpcRunner) GetNewResourceContent(resourceType string, schema *hclext.BodySchema, opts *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	content, err := r.Runner.GetNewResourceContent(resourceType, schema, opts)
+	return withoutExpressions(content), err
+}
+
+// withoutExpressions replaces the expressions of all attributes in content with their values.
+func withoutExpressions(content *hclext.BodyContent) *hclext.BodyContent {
+	if content == nil {
+		return nil
+	}
+	for _, attr := range content.Attributes {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			val = cty.DynamicVal
+		}
+		attr.Expr, attr.Value = nil, val
+	}
+	for _, block := range content.Blocks {
+		withoutExpressions(block.Body)
+	}
+	return content
+}
+
+func parseTestAttr(t *testing.T, src string) *hclext.Attribute {
+	t.Helper()
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("failed to parse %q: %s", src, diags.Error())
+	}
+	return &hclext.Attribute{Name: "test", Expr: expr}
+}
+
+func TestAttrAddress(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`azurerm_app_service.example`, "azurerm_app_service.example"},
+		{`module.web.azurerm_app_service.this[0]`, "module.web.azurerm_app_service.this[0]"},
+		{`azurerm_app_service.example["key"]`, `azurerm_app_service.example["key"]`},
+		{`"not-a-reference"`, ""},
+		{`[location, tags]`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			if got := attrAddress(parseTestAttr(t, tt.src)); got != tt.want {
+				t.Errorf("attrAddress(%s) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttrTraversals(t *testing.T) {
+	traversals := attrTraversals(parseTestAttr(t, `[location, identity[0].type]`))
+	if len(traversals) != 2 {
+		t.Fatalf("expected 2 traversals, got %d", len(traversals))
+	}
+	if got := traversalString(traversals[1]); got != "identity[0].type" {
+		t.Errorf("traversalString() = %q, want %q", got, "identity[0].type")
+	}
+
+	// Attributes received over gRPC carry no expression
+	if got := attrTraversals(&hclext.Attribute{Name: "test", Value: cty.StringVal("x")}); got != nil {
+		t.Errorf("expected nil traversals without an expression, got %v", got)
+	}
+}
+
+func TestExprString(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`each.value.name`, "each.value.name"},
+		{`"literal"`, `"literal"`},
+		{`42`, "42"},
+		{`"${ip.value.name}-nic"`, `"${ip.value.name}-nic"`},
+		{`lower( ip.value.name )`, "lower(ip.value.name)"},
+		{`var.enabled ? "a" : "b"`, `var.enabled ? "a" : "b"`},
+		{`ip.value.count + 1`, "ip.value.count + 1"},
+		{`!ip.value.primary`, "!ip.value.primary"},
+		{`var.subnets[ip.key].id`, "var.subnets[ip.key].id"},
+		{`[ip.value.a, "b"]`, `[ip.value.a, "b"]`},
+		{`{ name = ip.value.name }`, `{name = ip.value.name}`},
+		{`var.rules[*].name`, "var.rules[*].name"},
+		{`[for r in var.rules : r.name if r.enabled]`, "[for r in var.rules : r.name if r.enabled]"},
+		{`{for k, v in var.rules : k => v.priority}`, "{for k, v in var.rules : k => v.priority}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			if got := exprString(parseTestAttr(t, tt.src).Expr); got != tt.want {
+				t.Errorf("exprString(%s) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
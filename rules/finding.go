@@ -0,0 +1,76 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// findingVersion is the version of the finding payload.
+// It is incremented when fields are removed or change meaning; new fields may be added
+// without a version change. The schema is documented in docs/finding.schema.json.
+const findingVersion = 1
+
+// findingPrefix starts the line of an issue message that holds the finding payload.
+// The payload is always the last line of the message.
+const findingPrefix = "finding: "
+
+// findingCategory classifies what causes the recreation reported by a finding.
+type findingCategory string
+
+const (
+	// findingForceNew is a change of a ForceNew attribute.
+	findingForceNew findingCategory = "force_new"
+	// findingReplaceTriggered is a recreation triggered by lifecycle.replace_triggered_by.
+	findingReplaceTriggered findingCategory = "replace_triggered_by"
+	// findingOneWay is an in-place change that cannot be reverted without a recreation.
+	findingOneWay findingCategory = "one_way"
+)
+
+// finding is the machine-readable payload of an issue, so that consumers do not
+// have to parse the human-readable message.
+type finding struct {
+	Version      int             `json:"version"`
+	Category     findingCategory `json:"category"`
+	ResourceType string          `json:"resource_type"`
+	Address      string          `json:"address"`
+	// Attribute is the changed attribute path, for force_new and one_way findings.
+	Attribute string `json:"attribute,omitempty"`
+	// OldValue and NewValue are the displayed values, for force_new and one_way findings.
+	OldValue string `json:"old_value,omitempty"`
+	NewValue string `json:"new_value,omitempty"`
+	// Trigger is the replace_triggered_by reference that changed, for replace_triggered_by findings.
+	Trigger     string              `json:"trigger,omitempty"`
+	Criticality resourceCriticality `json:"criticality,omitempty"`
+	// DataLoss and Explanation are curated for the attribute in the schema overlay.
+	DataLoss    bool               `json:"data_loss,omitempty"`
+	Explanation string             `json:"explanation,omitempty"`
+	Remediation findingRemediation `json:"remediation"`
+}
+
+// findingRemediation is the remediation of a finding.
+type findingRemediation struct {
+	Kind    remediationKind `json:"kind"`
+	Summary string          `json:"summary"`
+	Snippet string          `json:"snippet,omitempty"`
+}
+
+// newFindingRemediation converts a remediation to its payload form.
+func newFindingRemediation(r remediation) findingRemediation {
+	return findingRemediation{Kind: r.Kind, Summary: r.Summary, Snippet: r.Snippet}
+}
+
+// withFinding appends the finding payload to an issue message as its last line.
+func withFinding(message string, f finding) string {
+	f.Version = findingVersion
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	// Values such as "<not set>" are kept readable in the message
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(f); err != nil {
+		return message
+	}
+	return message + "\n\n" + findingPrefix + strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+)
+
+// parseFinding extracts the finding payload from an issue message.
+func parseFinding(t *testing.T, message string) finding {
+	t.Helper()
+	i := strings.LastIndex(message, "\n"+findingPrefix)
+	if i < 0 {
+		t.Fatalf("Expected a finding payload in %q", message)
+	}
+	var f finding
+	if err := json.Unmarshal([]byte(message[i+1+len(findingPrefix):]), &f); err != nil {
+		t.Fatalf("Failed to decode finding payload: %v", err)
+	}
+	return f
+}
+
+func TestWithFinding(t *testing.T) {
+	message := withFinding("Changing \"name\" forces recreation.\n\nremoved {}", finding{
+		Category:     findingForceNew,
+		ResourceType: "azurerm_resource_group",
+		Address:      "azurerm_resource_group.example",
+		Attribute:    "name",
+		OldValue:     "<not set>",
+		NewValue:     "my-rg",
+		Remediation:  findingRemediation{Kind: remediationRename, Summary: "Rename.", Snippet: "removed {}\n"},
+	})
+
+	// The payload is a single line, kept readable
+	lines := strings.Split(message, "\n")
+	if last := lines[len(lines)-1]; !strings.HasPrefix(last, findingPrefix) || !strings.Contains(last, `"old_value":"<not set>"`) {
+		t.Errorf("Expected the payload on the last line, got %q", last)
+	}
+
+	f := parseFinding(t, message)
+	if f.Version != findingVersion {
+		t.Errorf("Version = %d, want %d", f.Version, findingVersion)
+	}
+	if f.Attribute != "name" || f.NewValue != "my-rg" || f.Remediation.Snippet != "removed {}\n" {
+		t.Errorf("Unexpected payload %+v", f)
+	}
+}
+
+func TestFinding_Schema(t *testing.T) {
+	data, err := os.ReadFile("../docs/finding.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	var doc struct {
+		Properties map[string]struct {
+			Properties map[string]any `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Failed to decode schema: %v", err)
+	}
+
+	// The documented properties match the payload fields
+	if got, want := sortedKeys(doc.Properties), jsonFields(reflect.TypeOf(finding{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("Schema properties = %v, want %v", got, want)
+	}
+	if got, want := sortedKeys(doc.Properties["remediation"].Properties), jsonFields(reflect.TypeOf(findingRemediation{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("Schema remediation properties = %v, want %v", got, want)
+	}
+}
+
+// jsonFields returns the sorted JSON field names of a struct type.
+func jsonFields(typ reflect.Type) []string {
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		names = append(names, strings.Split(typ.Field(i).Tag.Get("json"), ",")[0])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestForceNew_Finding(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+
+	runner := helper.TestRunner(t,
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "westeurope"
+}
+
+resource "azurerm_user_assigned_identity" "example" {
+    name = "id"
+    lifecycle {
+        replace_triggered_by = [azurerm_resource_group.example]
+    }
+}`,
+		},
+		map[string]string{
+			"main.tf": `
+resource "azurerm_resource_group" "example" {
+    name     = "my-rg"
+    location = "eastus"
+}
+
+resource "azurerm_user_assigned_identity" "example" {
+    name = "id"
+    lifecycle {
+        replace_triggered_by = [azurerm_resource_group.example]
+    }
+}`,
+		},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+
+	want := []finding{
+		{
+			Version:      findingVersion,
+			Category:     findingForceNew,
+			ResourceType: "azurerm_resource_group",
+			Address:      "azurerm_resource_group.example",
+			Attribute:    "location",
+			OldValue:     "westeurope",
+			NewValue:     "eastus",
+			Remediation:  newFindingRemediation(forceNewRemediation("azurerm_resource_group", "azurerm_resource_group.example", &hclext.Block{Labels: []string{"azurerm_resource_group", "example"}}, "location")),
+		},
+		{
+			Version:      findingVersion,
+			Category:     findingReplaceTriggered,
+			ResourceType: "azurerm_user_assigned_identity",
+			Address:      "azurerm_user_assigned_identity.example",
+			Trigger:      "azurerm_resource_group.example",
+			Criticality:  criticalityIdentity,
+			Remediation:  newFindingRemediation(triggerRemediation("azurerm_user_assigned_identity.example")),
+		},
+	}
+	for i, issue := range runner.Issues {
+		if got := parseFinding(t, issue.Message); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Issue %d payload = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+)
+
+// lifecycleBlockSchema retrieves the lifecycle meta-arguments of a resource.
+var lifecycleBlockSchema = hclext.BlockSchema{
+	Type: "lifecycle",
+	Body: &hclext.BodySchema{
+		Attributes: []hclext.AttributeSchema{
+			{Name: "prevent_destroy"},
+			{Name: "ignore_changes"},
+			{Name: "replace_triggered_by"},
+		},
+	},
+}
+
+// lifecycleResourcesSchema retrieves the lifecycle block of every resource in the module.
+var lifecycleResourcesSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type:       "resource",
+			LabelNames: []string{"type", "name"},
+			Body: &hclext.BodySchema{
+				Blocks: []hclext.BlockSchema{lifecycleBlockSchema},
+			},
+		},
+	},
+}
+
+// lifecycleSettings holds the lifecycle meta-arguments of a resource.
+type lifecycleSettings struct {
+	// Block is the lifecycle block, or nil if the resource has none.
+	Block *hclext.Block
+	// PreventDestroy reports whether prevent_destroy is statically true.
+	PreventDestroy bool
+	// IgnoreAll reports whether ignore_changes is set to all.
+	IgnoreAll bool
+	// IgnoreChanges holds the attribute paths listed in ignore_changes, e.g. "identity.type".
+	IgnoreChanges map[string]bool
+	// IgnoreKnown is false when ignore_changes is set but its references could not be read,
+	// which is the case for attributes received over gRPC.
+	IgnoreKnown bool
+	// ReplaceTriggeredBy holds the references listed in replace_triggered_by.
+	ReplaceTriggeredBy []hcl.Traversal
+}
+
+// readLifecycle reads the lifecycle meta-arguments from a resource block retrieved
+// with lifecycleBlockSchema.
+func readLifecycle(block *hclext.Block) lifecycleSettings {
+	settings := lifecycleSettings{
+		IgnoreChanges: make(map[string]bool),
+		IgnoreKnown:   true,
+	}
+	for _, nested := range nestedBlocks(block) {
+		if nested.Type == "lifecycle" {
+			settings.Block = nested
+			break
+		}
+	}
+	if settings.Block == nil || settings.Block.Body == nil {
+		return settings
+	}
+	attrs := settings.Block.Body.Attributes
+
+	settings.PreventDestroy = evalAttr(attrs["prevent_destroy"]) == "true"
+
+	if ignore := attrs["ignore_changes"]; ignore != nil {
+		traversals := attrTraversals(ignore)
+		if traversals == nil {
+			settings.IgnoreKnown = false
+		}
+		for _, traversal := range traversals {
+			if len(traversal) == 1 && traversal.RootName() == "all" {
+				settings.IgnoreAll = true
+				continue
+			}
+			settings.IgnoreChanges[attributePath(traversal)] = true
+		}
+	}
+
+	settings.ReplaceTriggeredBy = attrTraversals(attrs["replace_triggered_by"])
+
+	return settings
+}
+
+// Ignores reports whether changes to an attribute path are ignored.
+// A path is ignored if it or any of its parents is listed, e.g. ignoring
+// "identity" also ignores "identity.type".
+func (s lifecycleSettings) Ignores(path string) bool {
+	if s.IgnoreAll {
+		return true
+	}
+	for {
+		if s.IgnoreChanges[path] {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// ignoredPaths returns the ignored attribute paths in sorted order.
+func (s lifecycleSettings) ignoredPaths() []string {
+	paths := make([]string, 0, len(s.IgnoreChanges))
+	for path := range s.IgnoreChanges {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// attributePath converts an ignore_changes traversal to a dot-separated attribute path.
+// Index steps are dropped, so `identity[0].type` and `tags["env"]` become
+// "identity.type" and "tags".
+func attributePath(traversal hcl.Traversal) string {
+	var parts []string
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			parts = append(parts, s.Name)
+		case hcl.TraverseAttr:
+			parts = append(parts, s.Name)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,28 @@
+package rules
+
+import "testing"
+
+func TestLifecycleSettings_Ignores(t *testing.T) {
+	attr := parseTestAttr(t, `[identity, tags["env"], site_config[0].always_on]`)
+	settings := lifecycleSettings{IgnoreChanges: make(map[string]bool)}
+	for _, traversal := range attrTraversals(attr) {
+		settings.IgnoreChanges[attributePath(traversal)] = true
+	}
+
+	for path, want := range map[string]bool{
+		"identity":              true,
+		"identity.type":         true,
+		"tags":                  true,
+		"site_config.always_on": true,
+		"site_config.ftps":      false,
+		"location":              false,
+	} {
+		if got := settings.Ignores(path); got != want {
+			t.Errorf("Ignores(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	if !(lifecycleSettings{IgnoreAll: true}).Ignores("location") {
+		t.Error("Expected ignore_changes = all to ignore every attribute")
+	}
+}
@@ -0,0 +1,651 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// maxModuleDepth limits how deep module calls are followed, so that a module
+// that (indirectly) calls itself does not recurse forever.
+const maxModuleDepth = 10
+
+// moduleCallsSchema retrieves the module calls of a module.
+var moduleCallsSchema = &hclext.BodySchema{
+	Blocks: []hclext.BlockSchema{
+		{
+			Type:       "module",
+			LabelNames: []string{"name"},
+			Body:       &hclext.BodySchema{Attributes: []hclext.AttributeSchema{{Name: "source"}}},
+		},
+	},
+}
+
+// moduleDeclarationsSchema retrieves the input variables and local values of a module.
+var moduleDeclarationsSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+		{Type: "locals"},
+	},
+}
+
+// variableSchema retrieves the default value of an input variable.
+var variableSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{{Name: "default"}},
+}
+
+// readModuleFiles reads the Terraform files of a module directory, by file name.
+// A directory that does not exist has no files.
+func readModuleFiles(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tf") {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name()] = src
+	}
+	return files, nil
+}
+
+// isLocalModuleSource reports whether a module source is a local path,
+// which Terraform recognizes by its "./" or "../" prefix.
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// localModuleDir returns the directory of a module call with a local source,
+// relative to the file that contains the call. Returns false for other sources.
+func localModuleDir(call *hclext.Block) (string, bool) {
+	source := evalAttr(call.Body.Attributes["source"])
+	if !isLocalModuleSource(source) {
+		return "", false
+	}
+	return filepath.Join(filepath.Dir(call.DefRange.Filename), source), true
+}
+
+// moduleManifest is the manifest of the modules installed by terraform init,
+// in .terraform/modules/modules.json of the root module.
+type moduleManifest struct {
+	Modules []moduleManifestEntry `json:"Modules"`
+}
+
+// moduleManifestEntry is an installed module call. Key is the path of module call names,
+// e.g. "network" or "app.network", and Dir is relative to the root module.
+type moduleManifestEntry struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version"`
+	Dir     string `json:"Dir"`
+}
+
+// moduleNotInitializedError reports a module call whose installed module has another
+// source or version than the call requires, because terraform init was not run after the
+// call changed.
+type moduleNotInitializedError struct {
+	// Name is the name of the module call.
+	Name string
+	// RootDir is the directory of the root module.
+	RootDir string
+	// Installed and Required describe the differing source or version.
+	Installed, Required string
+}
+
+func (e *moduleNotInitializedError) Error() string {
+	return fmt.Sprintf("module.%s in %s is not initialized for this version (installed: %s, required: %s)",
+		e.Name, e.RootDir, e.Installed, e.Required)
+}
+
+// installedModuleDir returns the directory that terraform init installed a module call of
+// the root module to, from the module manifest read with readFile. Returns false when the
+// root module has not been initialized or the call is not installed, and a
+// *moduleNotInitializedError when the installed module does not match the call's source
+// or version.
+func installedModuleDir(readFile func(string) ([]byte, error), call *hclext.Block) (string, bool, error) {
+	rootDir := filepath.Dir(call.DefRange.Filename)
+	data, err := readFile(filepath.Join(rootDir, ".terraform", "modules", "modules.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	var manifest moduleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", false, fmt.Errorf("parse module manifest of %s: %w", rootDir, err)
+	}
+	for _, entry := range manifest.Modules {
+		if entry.Key != call.Labels[0] {
+			continue
+		}
+		source, version := evalAttr(call.Body.Attributes["source"]), evalAttr(call.Body.Attributes["version"])
+		if !isUnresolvedValue(source) && registrySource(source) != registrySource(entry.Source) {
+			return "", false, &moduleNotInitializedError{Name: entry.Key, RootDir: rootDir, Installed: entry.Source, Required: source}
+		}
+		if !isUnresolvedValue(version) && entry.Version != "" && !versionSatisfies(version, entry.Version) {
+			return "", false, &moduleNotInitializedError{Name: entry.Key, RootDir: rootDir, Installed: entry.Version, Required: version}
+		}
+		if filepath.IsAbs(entry.Dir) {
+			return entry.Dir, true, nil
+		}
+		return filepath.Join(rootDir, entry.Dir), true, nil
+	}
+	return "", false, nil
+}
+
+// registrySource returns a module source without the default registry host, which the
+// module manifest includes and module calls usually omit.
+func registrySource(source string) string {
+	return strings.TrimPrefix(source, "registry.terraform.io/")
+}
+
+// versionSatisfies reports whether a module version satisfies a version constraint of a
+// module call, such as "0.8.0", "~> 0.8" or ">= 0.7, < 1.0". Constraints that cannot be
+// parsed are treated as satisfied.
+func versionSatisfies(constraint, version string) bool {
+	installed, ok := parseModuleVersion(version)
+	if !ok {
+		return true
+	}
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		operator := ""
+		for _, candidate := range []string{"~>", ">=", "<=", "!=", "=", ">", "<"} {
+			if strings.HasPrefix(part, candidate) {
+				operator, part = candidate, strings.TrimSpace(strings.TrimPrefix(part, candidate))
+				break
+			}
+		}
+		required, ok := parseModuleVersion(part)
+		if !ok {
+			return true
+		}
+		cmp := compareModuleVersions(installed, required)
+		switch operator {
+		case "", "=":
+			if cmp != 0 {
+				return false
+			}
+		case "!=":
+			if cmp == 0 {
+				return false
+			}
+		case ">":
+			if cmp <= 0 {
+				return false
+			}
+		case ">=":
+			if cmp < 0 {
+				return false
+			}
+		case "<":
+			if cmp >= 0 {
+				return false
+			}
+		case "<=":
+			if cmp > 0 {
+				return false
+			}
+		case "~>":
+			// Only the rightmost given segment may increase, e.g. "~> 0.8" allows 0.9 but not 1.0
+			upper := moduleVersion{segments: append([]int(nil), required.segments...)}
+			if len(upper.segments) > 1 {
+				upper.segments = upper.segments[:len(upper.segments)-1]
+			}
+			upper.segments[len(upper.segments)-1]++
+			if cmp < 0 || compareModuleVersions(installed, upper) >= 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// moduleVersion is a parsed module version, e.g. "1.2.0-beta".
+type moduleVersion struct {
+	segments   []int
+	prerelease string
+}
+
+// parseModuleVersion parses a version with an optional "v" prefix, pre-release and build metadata.
+func parseModuleVersion(version string) (moduleVersion, bool) {
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, _ := strings.Cut(version, "-")
+	var parsed moduleVersion
+	for _, segment := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(segment)
+		if err != nil || n < 0 {
+			return moduleVersion{}, false
+		}
+		parsed.segments = append(parsed.segments, n)
+	}
+	parsed.prerelease = prerelease
+	return parsed, true
+}
+
+// compareModuleVersions compares two versions, treating missing segments as 0 and a
+// pre-release as lower than its release.
+func compareModuleVersions(a, b moduleVersion) int {
+	for i := 0; i < len(a.segments) || i < len(b.segments); i++ {
+		var x, y int
+		if i < len(a.segments) {
+			x = a.segments[i]
+		}
+		if i < len(b.segments) {
+			y = b.segments[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case a.prerelease == b.prerelease:
+		return 0
+	case a.prerelease == "":
+		return 1
+	case b.prerelease == "":
+		return -1
+	}
+	return strings.Compare(a.prerelease, b.prerelease)
+}
+
+// checkModuleCalls follows the module calls with local sources that exist in both
+// configurations, and checks the resources of the called modules as if they were part
+// of the calling module, with the call arguments bound to the module's variables.
+// Findings are reported at the module call, and name the resource inside the module.
+func (r *AzurermForceNewRule) checkModuleCalls(runner tflint.Runner, policy *forceNewPolicy) error {
+	if r.readModule == nil {
+		return nil
+	}
+	if m, ok := runner.(*moduleRunner); ok && strings.Count(m.module, "module.") >= maxModuleDepth {
+		return nil
+	}
+
+	oldCalls, err := runner.GetOldModuleContent(moduleCallsSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get old module calls: %w", err)
+	}
+	newCalls, err := runner.GetNewModuleContent(moduleCallsSchema, nil)
+	if err != nil {
+		return fmt.Errorf("get new module calls: %w", err)
+	}
+	oldByName := make(map[string]*hclext.Block)
+	for _, call := range oldCalls.Blocks {
+		if len(call.Labels) == 1 {
+			oldByName[call.Labels[0]] = call
+		}
+	}
+
+	for _, newCall := range newCalls.Blocks {
+		if len(newCall.Labels) != 1 {
+			continue
+		}
+		oldCall, ok := oldByName[newCall.Labels[0]]
+		if !ok {
+			continue // New module call, its resources are created
+		}
+		oldDir, oldLocal := localModuleDir(oldCall)
+		newDir, newLocal := localModuleDir(newCall)
+		if !oldLocal || !newLocal {
+			continue
+		}
+
+		child, err := r.followModuleCall(runner, newCall.Labels[0], oldDir, newDir, newCall.DefRange)
+		if err != nil {
+			return err
+		}
+		if child == nil {
+			continue
+		}
+		replaced, err := r.checkResources(child, policy)
+		if err != nil {
+			return err
+		}
+		if err := r.checkReplaceTriggeredBy(child, policy, replaced); err != nil {
+			return err
+		}
+		if err := r.checkModuleCalls(child, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followModuleCall loads the old and new module directories of a module call, and returns
+// a runner that reads the called module. Returns nil if either directory has no files.
+func (r *AzurermForceNewRule) followModuleCall(runner tflint.Runner, name, oldDir, newDir string, callRange hcl.Range) (*moduleRunner, error) {
+	oldFiles, err := loadModuleFiles(r.readModule, oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := loadModuleFiles(r.readModule, newDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(oldFiles) == 0 || len(newFiles) == 0 {
+		return nil, nil
+	}
+
+	// The call arguments are read for the variables declared by either version of the module
+	oldVars, oldLocals := moduleDeclarations(oldFiles)
+	newVars, newLocals := moduleDeclarations(newFiles)
+	argsSchema := moduleArgumentsSchema(oldVars, newVars)
+	oldArgs, err := runner.GetOldModuleContent(argsSchema, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get old module.%s: %w", name, err)
+	}
+	newArgs, err := runner.GetNewModuleContent(argsSchema, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get new module.%s: %w", name, err)
+	}
+
+	child := &moduleRunner{
+		root:      runner,
+		module:    "module." + name,
+		callRange: callRange,
+		old:       &moduleFiles{files: oldFiles, ctx: moduleEvalContext(oldVars, oldLocals, moduleArguments(oldArgs, name))},
+		new:       &moduleFiles{files: newFiles, ctx: moduleEvalContext(newVars, newLocals, moduleArguments(newArgs, name))},
+	}
+	// Findings in nested module calls are reported at the call in the root module
+	if parent, ok := runner.(*moduleRunner); ok {
+		child.root = parent.root
+		child.module = parent.module + "." + child.module
+		child.callRange = parent.callRange
+	}
+	return child, nil
+}
+
+// loadModuleFiles reads the Terraform files of a module directory with readModule,
+// and parses them in file name order.
+func loadModuleFiles(readModule func(string) (map[string][]byte, error), dir string) ([]*hcl.File, error) {
+	sources, err := readModule(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read module %s: %w", dir, err)
+	}
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parser := hclparse.NewParser()
+	files := make([]*hcl.File, 0, len(names))
+	for _, name := range names {
+		file, diags := parser.ParseHCL(sources[name], filepath.Join(dir, name))
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("parse module %s: %w", dir, diags)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// moduleDeclarations returns the input variables of a module, by name, and its local values.
+func moduleDeclarations(files []*hcl.File) (map[string]*hcl.Block, []*hcl.Attribute) {
+	variables := make(map[string]*hcl.Block)
+	var locals []*hcl.Attribute
+	for _, file := range files {
+		content, _, _ := file.Body.PartialContent(moduleDeclarationsSchema)
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "variable":
+				variables[block.Labels[0]] = block
+			case "locals":
+				attrs, _ := block.Body.JustAttributes()
+				for _, attr := range attrs {
+					locals = append(locals, attr)
+				}
+			}
+		}
+	}
+	// Attributes of a locals block are unordered, sort them for deterministic evaluation
+	sort.Slice(locals, func(i, j int) bool { return locals[i].Name < locals[j].Name })
+	return variables, locals
+}
+
+// moduleArgumentsSchema retrieves the module calls with the arguments for the given variables.
+func moduleArgumentsSchema(variables ...map[string]*hcl.Block) *hclext.BodySchema {
+	names := make(map[string]bool)
+	for _, vars := range variables {
+		for name := range vars {
+			names[name] = true
+		}
+	}
+	body := &hclext.BodySchema{}
+	for name := range names {
+		body.Attributes = append(body.Attributes, hclext.AttributeSchema{Name: name})
+	}
+	sort.Slice(body.Attributes, func(i, j int) bool {
+		return body.Attributes[i].Name < body.Attributes[j].Name
+	})
+	return &hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{{Type: "module", LabelNames: []string{"name"}, Body: body}},
+	}
+}
+
+// moduleArguments returns the arguments of the named module call in a module content.
+func moduleArguments(content *hclext.BodyContent, name string) map[string]*hclext.Attribute {
+	for _, call := range content.Blocks {
+		if len(call.Labels) == 1 && call.Labels[0] == name && call.Body != nil {
+			return call.Body.Attributes
+		}
+	}
+	return nil
+}
+
+// moduleEvalContext returns the evaluation context of a called module, in which var holds
+// the call arguments, or the variable defaults, and local holds the local values that can
+// be evaluated. Arguments that cannot be statically resolved are bound as unknown values,
+// so that attributes depending on them are not compared, like unresolved values in the
+// root module.
+func moduleEvalContext(variables map[string]*hcl.Block, locals []*hcl.Attribute, args map[string]*hclext.Attribute) *hcl.EvalContext {
+	vars := make(map[string]cty.Value)
+	for name, block := range variables {
+		vars[name] = cty.DynamicVal
+		if arg, ok := args[name]; ok {
+			vars[name] = argumentValue(arg)
+			continue
+		}
+		content, _, _ := block.Body.PartialContent(variableSchema)
+		if def, ok := content.Attributes["default"]; ok {
+			if val, diags := def.Expr.Value(nil); !diags.HasErrors() {
+				vars[name] = val
+			}
+		}
+	}
+	ctx := &hcl.EvalContext{Variables: map[string]cty.Value{"var": cty.ObjectVal(vars)}}
+
+	// Local values may refer to each other, so evaluate them until no more can be
+	values := make(map[string]cty.Value)
+	pending := locals
+	for progress := true; progress && len(pending) > 0; {
+		progress = false
+		ctx.Variables["local"] = cty.ObjectVal(values)
+		remaining := pending[:0]
+		for _, local := range pending {
+			val, diags := local.Expr.Value(ctx)
+			if diags.HasErrors() {
+				remaining = append(remaining, local)
+				continue
+			}
+			values[local.Name] = val
+			progress = true
+		}
+		pending = remaining
+	}
+	ctx.Variables["local"] = cty.ObjectVal(values)
+	return ctx
+}
+
+// argumentValue returns the value of a module call argument, or an unknown value if it
+// cannot be statically resolved.
+func argumentValue(arg *hclext.Attribute) cty.Value {
+	if val, ok := attrValue(arg); ok && val.IsWhollyKnown() {
+		return val
+	}
+	return cty.DynamicVal
+}
+
+// moduleFiles is one version of a called module.
+type moduleFiles struct {
+	files []*hcl.File
+	// ctx binds the call arguments and local values of the module.
+	ctx *hcl.EvalContext
+}
+
+// content extracts the content of the module files, evaluating attributes in the
+// module's context. Attributes that cannot be evaluated keep only their expression.
+func (m *moduleFiles) content(schema *hclext.BodySchema) (*hclext.BodyContent, error) {
+	content := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	for _, file := range m.files {
+		fileContent, err := m.bodyContent(file.Body, schema)
+		if err != nil {
+			return nil, err
+		}
+		for name, attr := range fileContent.Attributes {
+			content.Attributes[name] = attr
+		}
+		content.Blocks = append(content.Blocks, fileContent.Blocks...)
+	}
+	return content, nil
+}
+
+// bodyContent extracts the content of a body and its nested blocks.
+func (m *moduleFiles) bodyContent(body hcl.Body, schema *hclext.BodySchema) (*hclext.BodyContent, error) {
+	content := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	if schema == nil {
+		return content, nil
+	}
+	hclContent, _, diags := body.PartialContent(hclext.ToHCLBodySchema(schema))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	for name, hclAttr := range hclContent.Attributes {
+		attr := hclext.FromHCLAttribute(hclAttr)
+		if val, diags := hclAttr.Expr.Value(m.ctx); !diags.HasErrors() {
+			attr.Value = val
+		}
+		content.Attributes[name] = attr
+	}
+	for _, hclBlock := range hclContent.Blocks {
+		block := hclext.FromHCLBlock(hclBlock)
+		for _, blockSchema := range schema.Blocks {
+			if blockSchema.Type != hclBlock.Type {
+				continue
+			}
+			nested, err := m.bodyContent(hclBlock.Body, blockSchema.Body)
+			if err != nil {
+				return nil, err
+			}
+			block.Body = nested
+		}
+		content.Blocks = append(content.Blocks, block)
+	}
+	return content, nil
+}
+
+// moduleRunner is a runner that reads a module called from the configurations read by
+// another runner. Issues are emitted to the runner of the root module at the module call,
+// with a note on where the resource is declared in the called module.
+type moduleRunner struct {
+	// root is the runner of the root module.
+	root tflint.Runner
+	// module is the module path of the called module, e.g. "module.app.module.network".
+	module string
+	// callRange is the range of the module call in the root module.
+	callRange hcl.Range
+	old, new  *moduleFiles
+}
+
+// resourceAddress returns the absolute address of a resource in the module read by runner,
+// e.g. "module.network.azurerm_subnet.example" for a resource in a called module.
+func resourceAddress(runner tflint.Runner, resourceType, name string) string {
+	if m, ok := runner.(*moduleRunner); ok {
+		return m.module + "." + resourceType + "." + name
+	}
+	return resourceType + "." + name
+}
+
+// GetOldModuleContent retrieves content from the old version of the called module.
+func (m *moduleRunner) GetOldModuleContent(schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return m.old.content(schema)
+}
+
+// GetNewModuleContent retrieves content from the new version of the called module.
+func (m *moduleRunner) GetNewModuleContent(schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return m.new.content(schema)
+}
+
+// GetOldResourceContent retrieves resources of a type from the old version of the called module.
+func (m *moduleRunner) GetOldResourceContent(resourceType string, schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return resourceContent(m.old, resourceType, schema)
+}
+
+// GetNewResourceContent retrieves resources of a type from the new version of the called module.
+func (m *moduleRunner) GetNewResourceContent(resourceType string, schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return resourceContent(m.new, resourceType, schema)
+}
+
+// EmitIssue emits an issue at the module call in the root module. The declaration of the
+// resource in the called module is noted in the message, before the finding payload.
+func (m *moduleRunner) EmitIssue(rule tflint.Rule, message string, issueRange hcl.Range) error {
+	if issueRange.Filename != "" {
+		note := fmt.Sprintf("Declared in %s at %s:%d.", m.module, issueRange.Filename, issueRange.Start.Line)
+		if i := strings.LastIndex(message, "\n\n"+findingPrefix); i >= 0 {
+			message = message[:i] + "\n\n" + note + message[i:]
+		} else {
+			message += "\n\n" + note
+		}
+	}
+	return m.root.EmitIssue(rule, message, m.callRange)
+}
+
+// DecodeRuleConfig decodes the rule configuration of the root module.
+func (m *moduleRunner) DecodeRuleConfig(ruleName string, target any) error {
+	return m.root.DecodeRuleConfig(ruleName, target)
+}
+
+// resourceContent retrieves the resources of a type from a module.
+func resourceContent(files *moduleFiles, resourceType string, schema *hclext.BodySchema) (*hclext.BodyContent, error) {
+	content, err := files.content(&hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{{Type: "resource", LabelNames: []string{"type", "name"}, Body: schema}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	for _, block := range content.Blocks {
+		if block.Labels[0] == resourceType {
+			resources.Blocks = append(resources.Blocks, block)
+		}
+	}
+	return resources, nil
+}
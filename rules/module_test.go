@@ -0,0 +1,283 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+)
+
+// testModules returns a readModule function that reads module files from a map
+// of directories to file contents, and records the directories it reads.
+func testModules(modules map[string]map[string]string, read *[]string) func(string) (map[string][]byte, error) {
+	return func(dir string) (map[string][]byte, error) {
+		if read != nil {
+			*read = append(*read, dir)
+		}
+		files := make(map[string][]byte)
+		for name, src := range modules[dir] {
+			files[name] = []byte(src)
+		}
+		return files, nil
+	}
+}
+
+const networkModuleCall = `
+module "network" {
+  source   = "./modules/network"
+  location = %s
+}
+`
+
+const networkModule = `
+variable "location" {}
+
+variable "prefix" {
+  default = "app"
+}
+
+# name refers to a local value that sorts after it
+locals {
+  name      = local.prefix_rg
+  prefix_rg = "${var.prefix}-rg"
+}
+
+resource "azurerm_resource_group" "this" {
+  name     = local.name
+  location = var.location
+}
+`
+
+func moduleCallFile(location string) string {
+	return strings.Replace(networkModuleCall, "%s", location, 1)
+}
+
+func TestForceNew_ModuleCalls(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldLocation string
+		newLocation string
+		oldModule   string
+		newModule   string
+		wantIssue   string
+		// wantRemediation is text the remediation must contain, with module-qualified addresses
+		wantRemediation []string
+	}{
+		{
+			name:        "argument unchanged",
+			oldLocation: `"westeurope"`,
+			newLocation: `"westeurope"`,
+			oldModule:   networkModule,
+			newModule:   networkModule,
+		},
+		{
+			name:            "argument changed",
+			oldLocation:     `"westeurope"`,
+			newLocation:     `"northeurope"`,
+			oldModule:       networkModule,
+			newModule:       networkModule,
+			wantIssue:       `Changing "location" forces recreation of module.network.azurerm_resource_group.this (old: westeurope, new: northeurope)`,
+			wantRemediation: []string{"Keep module.network.azurerm_resource_group.this at its current location"},
+		},
+		{
+			name:        "unresolved argument unchanged",
+			oldLocation: `var.location`,
+			newLocation: `var.location`,
+			oldModule:   networkModule,
+			newModule:   networkModule,
+		},
+		{
+			name:        "unresolved argument reworded",
+			oldLocation: `var.location`,
+			newLocation: `local.location`,
+			oldModule:   networkModule,
+			newModule:   networkModule,
+		},
+		{
+			name:        "variable default changed in module",
+			oldLocation: `"westeurope"`,
+			newLocation: `"westeurope"`,
+			oldModule:   networkModule,
+			newModule:   strings.Replace(networkModule, `default = "app"`, `default = "web"`, 1),
+			wantIssue:   `Changing "name" forces recreation of module.network.azurerm_resource_group.this (old: app-rg, new: web-rg)`,
+			wantRemediation: []string{
+				"rename the resource block to module.network.azurerm_resource_group.web-rg and add these blocks to the root module",
+				"from = module.network.azurerm_resource_group.this",
+				"to = module.network.azurerm_resource_group.web-rg",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			rule.readModule = testModules(map[string]map[string]string{
+				filepath.Join("old", "modules", "network"): {"main.tf": tt.oldModule},
+				filepath.Join("new", "modules", "network"): {"main.tf": tt.newModule},
+			}, nil)
+
+			runner := helper.TestRunner(t,
+				map[string]string{"old/main.tf": moduleCallFile(tt.oldLocation)},
+				map[string]string{"new/main.tf": moduleCallFile(tt.newLocation)},
+			)
+
+			if err := rule.Check(runner); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if tt.wantIssue == "" {
+				if len(runner.Issues) != 0 {
+					t.Fatalf("Expected no issues, got %d: %v", len(runner.Issues), runner.Issues)
+				}
+				return
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			issue := runner.Issues[0]
+			if !strings.Contains(issue.Message, tt.wantIssue) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantIssue, issue.Message)
+			}
+
+			// Reported at the module call, noting the declaration in the module
+			if issue.Range.Filename != "new/main.tf" || issue.Range.Start.Line != 2 {
+				t.Errorf("Expected the issue at the module call, got %s", issue.Range)
+			}
+			wantNote := "Declared in module.network at " + filepath.Join("new", "modules", "network", "main.tf") + ":"
+			if !strings.Contains(issue.Message, wantNote) {
+				t.Errorf("Expected message to contain %q, got %q", wantNote, issue.Message)
+			}
+			if f := parseFinding(t, issue.Message); f.Address != "module.network.azurerm_resource_group.this" {
+				t.Errorf("Expected finding address of the resource in the module, got %q", f.Address)
+			}
+			for _, text := range tt.wantRemediation {
+				if !strings.Contains(issue.Message, text) {
+					t.Errorf("Expected remediation to contain %q, got %q", text, issue.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestForceNew_ModuleCallsTrigger(t *testing.T) {
+	identityModule := networkModule + `
+resource "azurerm_user_assigned_identity" "this" {
+  name                = "id"
+  resource_group_name = "rg"
+  location            = "westeurope"
+
+  lifecycle {
+    replace_triggered_by = [azurerm_resource_group.this]
+  }
+}
+`
+	rule := NewAzurermForceNewRule()
+	rule.readModule = testModules(map[string]map[string]string{
+		filepath.Join("old", "modules", "network"): {"main.tf": identityModule},
+		filepath.Join("new", "modules", "network"): {"main.tf": identityModule},
+	}, nil)
+
+	runner := helper.TestRunner(t,
+		map[string]string{"old/main.tf": moduleCallFile(`"westeurope"`)},
+		map[string]string{"new/main.tf": moduleCallFile(`"northeurope"`)},
+	)
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	var found bool
+	for _, issue := range runner.Issues {
+		if f := parseFinding(t, issue.Message); f.Category != findingReplaceTriggered {
+			continue
+		}
+		found = true
+		want := "Remove the reference if recreating module.network.azurerm_user_assigned_identity.this is not intended."
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, issue.Message)
+		}
+	}
+	if !found {
+		t.Fatalf("Expected a replace_triggered_by issue, got %v", runner.Issues)
+	}
+}
+
+func TestForceNew_NestedModuleCalls(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+	appModule := func(location string) string {
+		return `
+module "network" {
+  source   = "../network"
+  location = "` + location + `"
+}
+`
+	}
+	rule.readModule = testModules(map[string]map[string]string{
+		filepath.Join("old", "modules", "app"):     {"main.tf": appModule("westeurope")},
+		filepath.Join("new", "modules", "app"):     {"main.tf": appModule("northeurope")},
+		filepath.Join("old", "modules", "network"): {"main.tf": networkModule},
+		filepath.Join("new", "modules", "network"): {"main.tf": networkModule},
+	}, nil)
+
+	root := `module "app" {
+  source = "./modules/app"
+}
+`
+	runner := helper.TestRunner(t, map[string]string{"old/main.tf": root}, map[string]string{"new/main.tf": root})
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	issue := runner.Issues[0]
+	if !strings.Contains(issue.Message, "module.app.module.network.azurerm_resource_group.this") {
+		t.Errorf("Expected the nested module address in %q", issue.Message)
+	}
+	if issue.Range.Filename != "new/main.tf" || issue.Range.Start.Line != 1 {
+		t.Errorf("Expected the issue at the module call in the root module, got %s", issue.Range)
+	}
+}
+
+func TestForceNew_RemoteModuleCallsNotFollowed(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+	var read []string
+	rule.readModule = testModules(nil, &read)
+
+	call := `module "network" {
+  source  = "Azure/avm-res-network-virtualnetwork/azurerm"
+  version = "0.7.1"
+}
+`
+	runner := helper.TestRunner(t, map[string]string{"old/main.tf": call}, map[string]string{"new/main.tf": call})
+
+	if err := rule.Check(runner); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(read) != 0 {
+		t.Errorf("Expected registry modules not to be read, read %v", read)
+	}
+}
+
+func TestReadModuleFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{"main.tf": `variable "x" {}`, "README.md": "# module"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := readModuleFiles(dir)
+	if err != nil {
+		t.Fatalf("readModuleFiles failed: %v", err)
+	}
+	if len(files) != 1 || string(files["main.tf"]) != `variable "x" {}` {
+		t.Errorf("Expected only main.tf, got %v", files)
+	}
+
+	files, err = readModuleFiles(filepath.Join(dir, "missing"))
+	if err != nil || files != nil {
+		t.Errorf("Expected no files and no error for a missing directory, got %v, %v", files, err)
+	}
+}
@@ -10,4 +10,11 @@ import (
 // This follows the tflint-ruleset-azurerm pattern.
 var Rules = []tflint.Rule{
 	NewAzurermForceNewRule(),
+	NewAzurermDeprecatedAttributeRule(),
+	NewAzurermResourceTypeMigrationRule(),
+	NewAzurermNetworkAddressChangeRule(),
+	NewAzurermAvailabilityZoneChangeRule(),
+	NewAzurermAksNodePoolRecreationRule(),
+	NewAzurermLifecycleProtectionRemovedRule(),
+	NewAzurermModuleSourceChangeRule(),
 }
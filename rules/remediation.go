@@ -0,0 +1,196 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/resourceid"
+)
+
+// remediationKind classifies a ForceNew change by how the recreation can be avoided.
+type remediationKind string
+
+const (
+	// remediationRename is a change of the resource's name. The live resource can be left
+	// in place by removing it from state, while a resource with the new name is created or adopted.
+	remediationRename remediationKind = "rename"
+	// remediationMove is a change of the resource group. The live resource can be moved
+	// to the new resource group outside Terraform and adopted at its new ID.
+	remediationMove remediationKind = "move"
+	// remediationRelocate is a change of the location. Azure resources cannot change region,
+	// so a new resource is created alongside the existing one.
+	remediationRelocate remediationKind = "relocate"
+	// remediationRevert is any other change, which can only be reverted or ignored.
+	remediationRevert remediationKind = "revert"
+	// remediationRemoveTrigger is a recreation triggered by lifecycle.replace_triggered_by,
+	// which is avoided by removing the reference.
+	remediationRemoveTrigger remediationKind = "remove_trigger"
+)
+
+// remediation is a suggestion for avoiding the recreation caused by a ForceNew change.
+type remediation struct {
+	Kind remediationKind
+	// Summary is a sentence describing the suggested steps.
+	Summary string
+	// Snippet is HCL to add to the configuration, or empty if there is none.
+	Snippet string
+}
+
+// String formats the remediation for an issue message, with the snippet on its own lines.
+func (r remediation) String() string {
+	if r.Snippet == "" {
+		return r.Summary
+	}
+	return r.Summary + "\n\n" + r.Snippet
+}
+
+// idTemplateAttributes returns the configuration attributes used by the resource ID template
+// of a resource type, so they can be included in the body schema.
+func idTemplateAttributes(resourceType string) []string {
+	template, ok := resourceid.Lookup(resourceType)
+	if !ok {
+		return nil
+	}
+	return template.Attributes()
+}
+
+// resourceID computes the Azure resource ID of a resource from its configuration.
+// Segments whose attribute cannot be statically resolved keep their placeholder.
+// Returns a descriptive placeholder if the ID shape of the resource type is unknown.
+func resourceID(resourceType string, block *hclext.Block) string {
+	template, ok := resourceid.Lookup(resourceType)
+	if !ok {
+		return fmt.Sprintf("<Azure resource ID of the %s>", resourceType)
+	}
+	values := make(map[string]string)
+	for _, segment := range template.Segments {
+		if segment.Attribute == "" {
+			continue
+		}
+		if value := evalAttr(getAttributeByPath(block, segment.Attribute)); !isUnresolvedValue(value) {
+			values[segment.Name] = value
+		}
+	}
+	return template.Format(values)
+}
+
+// invalidIdentifierChars matches characters that are not allowed in a Terraform identifier.
+var invalidIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// renamedAddress returns the resource address to adopt a renamed resource at.
+// The address is derived from the new name, since a removed block and a resource
+// block cannot refer to the same address.
+func renamedAddress(resourceType, label, newName string) string {
+	name := label + "_new"
+	if !isUnresolvedValue(newName) && newName != "" {
+		name = invalidIdentifierChars.ReplaceAllString(newName, "_")
+		if c := name[0]; !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '_') {
+			name = "_" + name
+		}
+		if name == label {
+			name += "_new"
+		}
+	}
+	return resourceType + "." + name
+}
+
+// adoptionSnippet returns a removed block that keeps the live resource at address,
+// and an import block that adopts the resource with the given ID at newAddress.
+func adoptionSnippet(address, newAddress, id string) string {
+	return fmt.Sprintf(`removed {
+  from = %s
+
+  lifecycle {
+    destroy = false
+  }
+}
+
+import {
+  to = %s
+  id = %q
+}`, address, newAddress, id)
+}
+
+// ignoreChangesPath formats an attribute path as an ignore_changes entry,
+// indexing nested blocks with [0], e.g. "identity.type" becomes "identity[0].type".
+func ignoreChangesPath(attrPath string) string {
+	return strings.ReplaceAll(attrPath, ".", "[0].")
+}
+
+// forceNewRemediation suggests how to avoid the recreation caused by a change of attrPath
+// in the resource defined by newBlock, at address. For resources in a called module, the
+// address is qualified with the module path, and so are the addresses in the snippets,
+// since removed and import blocks for them belong in the root module.
+func forceNewRemediation(resourceType, address string, newBlock *hclext.Block, attrPath string) remediation {
+	label := newBlock.Labels[1]
+	modulePath := strings.TrimSuffix(address, resourceType+"."+label)
+	blocks := "these blocks"
+	if modulePath != "" {
+		blocks = "these blocks to the root module"
+	}
+
+	switch attrPath {
+	case "name":
+		newAddress := modulePath + renamedAddress(resourceType, label, evalAttr(getAttributeByPath(newBlock, "name")))
+		return remediation{
+			Kind: remediationRename,
+			Summary: fmt.Sprintf(
+				"Azure resources cannot be renamed, so a resource with the new name is a new resource. "+
+					"To avoid destroying the existing resource, rename the resource block to %s and add %s. "+
+					"The removed block leaves the existing resource in Azure, no longer managed by Terraform; "+
+					"the import block adopts a resource that already has the new name, so drop it to let Terraform create one:",
+				newAddress, blocks,
+			),
+			Snippet: adoptionSnippet(address, newAddress, resourceID(resourceType, newBlock)),
+		}
+	case "resource_group_name":
+		newAddress := modulePath + renamedAddress(resourceType, label, "")
+		return remediation{
+			Kind: remediationMove,
+			Summary: fmt.Sprintf(
+				"To keep the existing resource, move it to the new resource group outside Terraform "+
+					"(e.g. az resource move), rename the resource block to %s and add %s:",
+				newAddress, blocks,
+			),
+			Snippet: adoptionSnippet(address, newAddress, resourceID(resourceType, newBlock)),
+		}
+	case "location":
+		return remediation{
+			Kind: remediationRelocate,
+			Summary: fmt.Sprintf(
+				"Azure resources cannot change region, and a moved block does not avoid the recreation. "+
+					"Keep %s at its current location, add a second resource at the new location, "+
+					"migrate workloads and data to it, then remove the old resource.",
+				address,
+			),
+		}
+	default:
+		return remediation{
+			Kind: remediationRevert,
+			Summary: fmt.Sprintf(
+				"Revert the change, or add %s to lifecycle.ignore_changes to keep the existing resource.",
+				ignoreChangesPath(attrPath),
+			),
+		}
+	}
+}
+
+// triggerRemediation suggests how to avoid a recreation of the resource at address
+// that is triggered by lifecycle.replace_triggered_by.
+func triggerRemediation(address string) remediation {
+	return remediation{
+		Kind:    remediationRemoveTrigger,
+		Summary: fmt.Sprintf("Remove the reference if recreating %s is not intended.", address),
+	}
+}
+
+// oneWayRemediation suggests how to handle an in-place change that cannot be reverted.
+func oneWayRemediation() remediation {
+	return remediation{
+		Kind:    remediationRevert,
+		Summary: "Revert the change if it is not intended; once applied, reverting it recreates the resource.",
+	}
+}
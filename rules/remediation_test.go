@@ -0,0 +1,77 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+)
+
+func TestForceNewRemediation(t *testing.T) {
+	block := &hclext.Block{
+		Type:   "resource",
+		Labels: []string{"azurerm_subnet", "app"},
+		Body: &hclext.BodyContent{
+			Attributes: map[string]*hclext.Attribute{
+				"name":                 parseTestAttr(t, `"app-subnet"`),
+				"resource_group_name":  parseTestAttr(t, `"network-rg"`),
+				"virtual_network_name": parseTestAttr(t, `var.vnet_name`),
+			},
+		},
+	}
+
+	tests := []struct {
+		attrPath string
+		wantKind remediationKind
+		wantText []string
+	}{
+		{"name", remediationRename, []string{
+			"leaves the existing resource in Azure, no longer managed by Terraform",
+			"to = azurerm_subnet.app-subnet",
+			`id = "/subscriptions/{subscription_id}/resourceGroups/network-rg/providers/Microsoft.Network/virtualNetworks/{virtual_network_name}/subnets/app-subnet"`,
+		}},
+		{"resource_group_name", remediationMove, []string{"az resource move", "to = azurerm_subnet.app_new"}},
+		{"location", remediationRelocate, []string{"cannot change region"}},
+		{"delegation.name", remediationRevert, []string{"add delegation[0].name to lifecycle.ignore_changes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.attrPath, func(t *testing.T) {
+			got := forceNewRemediation("azurerm_subnet", "azurerm_subnet.app", block, tt.attrPath)
+			if got.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", got.Kind, tt.wantKind)
+			}
+			for _, text := range tt.wantText {
+				if !strings.Contains(got.String(), text) {
+					t.Errorf("Expected remediation to contain %q, got %q", text, got.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRenamedAddress(t *testing.T) {
+	tests := []struct {
+		newName string
+		want    string
+	}{
+		{"my-rg-v2", "azurerm_resource_group.my-rg-v2"},
+		{"rg.prod", "azurerm_resource_group.rg_prod"},
+		{"2024-rg", "azurerm_resource_group._2024-rg"},
+		{"example", "azurerm_resource_group.example_new"},
+		{"<dynamic>", "azurerm_resource_group.example_new"},
+	}
+
+	for _, tt := range tests {
+		if got := renamedAddress("azurerm_resource_group", "example", tt.newName); got != tt.want {
+			t.Errorf("renamedAddress(%q) = %q, want %q", tt.newName, got, tt.want)
+		}
+	}
+}
+
+func TestResourceID_UnknownType(t *testing.T) {
+	block := &hclext.Block{Labels: []string{"azurerm_example", "x"}, Body: &hclext.BodyContent{}}
+	if got := resourceID("azurerm_example", block); got != "<Azure resource ID of the azurerm_example>" {
+		t.Errorf("Expected placeholder ID, got %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+// severityRule wraps a rule so a single issue can be emitted with a severity
+// other than the rule's default. Runners read the severity from the rule passed
+// to EmitIssue, and the gRPC runner serializes it alongside the rule name.
+type severityRule struct {
+	tflint.Rule
+	severity tflint.Severity
+}
+
+// Severity returns the overridden severity.
+func (r *severityRule) Severity() tflint.Severity {
+	return r.severity
+}
+
+// withSeverity returns a rule that reports the given severity.
+// The rule is returned unchanged if it already has that severity.
+func withSeverity(rule tflint.Rule, severity tflint.Severity) tflint.Rule {
+	if rule.Severity() == severity {
+		return rule
+	}
+	return &severityRule{Rule: rule, severity: severity}
+}
+
+// parseSeverity parses a severity name as used in rule configuration.
+// Names are case-insensitive: "error", "warning" or "notice".
+func parseSeverity(name string) (tflint.Severity, error) {
+	switch strings.ToUpper(name) {
+	case "ERROR":
+		return tflint.ERROR, nil
+	case "WARNING":
+		return tflint.WARNING, nil
+	case "NOTICE":
+		return tflint.NOTICE, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q, expected ERROR, WARNING or NOTICE", name)
+}
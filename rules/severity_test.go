@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+func TestWithSeverity(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+
+	if got := withSeverity(rule, tflint.ERROR); got != tflint.Rule(rule) {
+		t.Error("Expected the rule to be returned unchanged when the severity matches")
+	}
+
+	wrapped := withSeverity(rule, tflint.WARNING)
+	if wrapped.Severity() != tflint.WARNING {
+		t.Errorf("Expected severity to be WARNING, got %v", wrapped.Severity())
+	}
+	if wrapped.Name() != rule.Name() || wrapped.Link() != rule.Link() {
+		t.Error("Expected the wrapped rule to keep the rule name and link")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for name, want := range map[string]tflint.Severity{
+		"ERROR":   tflint.ERROR,
+		"warning": tflint.WARNING,
+		"Notice":  tflint.NOTICE,
+	} {
+		got, err := parseSeverity(name)
+		if err != nil {
+			t.Errorf("parseSeverity(%q) returned error: %v", name, err)
+		}
+		if got != want {
+			t.Errorf("parseSeverity(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := parseSeverity("critical"); err == nil {
+		t.Error("Expected error for unknown severity")
+	}
+}
@@ -0,0 +1,200 @@
+// Package rules provides the tfbreak rules for Azure RM provider.
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// priorState holds the attribute values of the managed resources in Terraform state,
+// by resource address without instance keys, with one object per instance.
+// It provides old values that the configuration does not determine statically,
+// such as values of variables or data sources.
+type priorState struct {
+	resources map[string][]cty.Value
+}
+
+// rawState is a state file (terraform.tfstate, format version 4).
+type rawState struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			Attributes json.RawMessage `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// stateOutput is the output of `terraform show -json` for state, or the prior_state of a plan.
+type stateOutput struct {
+	Values *stateModule `json:"values"`
+	// PriorState is set in the output of `terraform show -json` for a saved plan.
+	PriorState *stateOutput `json:"prior_state"`
+}
+
+// stateModule is a module in the output of `terraform show -json`.
+type stateModule struct {
+	RootModule *stateModule `json:"root_module"`
+	Resources  []struct {
+		Address string          `json:"address"`
+		Mode    string          `json:"mode"`
+		Values  json.RawMessage `json:"values"`
+	} `json:"resources"`
+	ChildModules []*stateModule `json:"child_modules"`
+}
+
+// instanceKeys matches the instance keys of an address, e.g. `[0]` or `["a"]`.
+var instanceKeys = regexp.MustCompile(`\[("(\\.|[^"\\])*"|[^\]"]*)\]`)
+
+// parsePriorState parses a state file, or the JSON output of `terraform show -json`
+// for state or for a saved plan.
+func parsePriorState(data []byte) (*priorState, error) {
+	state := &priorState{resources: make(map[string][]cty.Value)}
+
+	var raw rawState
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Version != 0 {
+		if raw.Version != 4 {
+			return nil, fmt.Errorf("unsupported state version %d", raw.Version)
+		}
+		for _, resource := range raw.Resources {
+			if resource.Mode != "managed" {
+				continue
+			}
+			address := resource.Type + "." + resource.Name
+			if resource.Module != "" {
+				address = instanceKeys.ReplaceAllString(resource.Module, "") + "." + address
+			}
+			for _, instance := range resource.Instances {
+				if err := state.add(address, instance.Attributes); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return state, nil
+	}
+
+	var output stateOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, err
+	}
+	if output.PriorState != nil {
+		output = *output.PriorState
+	}
+	if output.Values == nil {
+		return nil, fmt.Errorf("neither a state file nor `terraform show -json` output")
+	}
+	if err := state.addModule(output.Values.RootModule); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// addModule adds the managed resources of a module and its child modules.
+func (s *priorState) addModule(module *stateModule) error {
+	if module == nil {
+		return nil
+	}
+	for _, resource := range module.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+		if err := s.add(instanceKeys.ReplaceAllString(resource.Address, ""), resource.Values); err != nil {
+			return err
+		}
+	}
+	for _, child := range module.ChildModules {
+		if err := s.addModule(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds the attribute values of a resource instance.
+func (s *priorState) add(address string, attributes json.RawMessage) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	ty, err := ctyjson.ImpliedType(attributes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, err)
+	}
+	val, err := ctyjson.Unmarshal(attributes, ty)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, err)
+	}
+	s.resources[address] = append(s.resources[address], val)
+	return nil
+}
+
+// value returns the value of an attribute path of a resource, such as "identity.type".
+// Nested blocks are followed if there is exactly one of them. It reports false if the
+// resource or attribute is not in state, or if the instances of the resource disagree.
+func (s *priorState) value(address, attrPath string) (cty.Value, bool) {
+	var result cty.Value
+	for i, instance := range s.resources[address] {
+		val, ok := stateAttribute(instance, strings.Split(attrPath, "."))
+		if !ok || (i > 0 && !val.RawEquals(result)) {
+			return cty.NilVal, false
+		}
+		result = val
+	}
+	return result, result != cty.NilVal
+}
+
+// stateAttribute returns the value at a path within a state object.
+func stateAttribute(val cty.Value, path []string) (cty.Value, bool) {
+	for _, name := range path {
+		ty := val.Type()
+		if (ty.IsListType() || ty.IsTupleType() || ty.IsSetType()) && !val.IsNull() {
+			if val.LengthInt() != 1 {
+				return cty.NilVal, false
+			}
+			for it := val.ElementIterator(); it.Next(); {
+				_, val = it.Element()
+			}
+			ty = val.Type()
+		}
+		if val.IsNull() || !ty.IsObjectType() || !ty.HasAttribute(name) {
+			return cty.NilVal, false
+		}
+		val = val.GetAttr(name)
+	}
+	return val, true
+}
+
+// oldAttribute returns an attribute holding the value in state of an old attribute that
+// cannot be statically resolved, and reports whether state was used. Unset attributes,
+// resolved attributes, and attributes without a value in state are returned as is.
+func (s *priorState) oldAttribute(address, attrPath string, attr *hclext.Attribute) (*hclext.Attribute, bool) {
+	if s == nil || attr == nil || staticallyResolved(attr) {
+		return attr, false
+	}
+	val, ok := s.value(address, attrPath)
+	if !ok {
+		return attr, false
+	}
+	return &hclext.Attribute{Name: attr.Name, Expr: attr.Expr, Range: attr.Range, Value: val}, true
+}
+
+// staticallyResolved reports whether the value of an attribute is known without state.
+// Dynamic block content that could not be evaluated is a string holding its expression.
+func staticallyResolved(attr *hclext.Attribute) bool {
+	val, ok := attrValue(attr)
+	if !ok || !val.IsWhollyKnown() {
+		return false
+	}
+	return val.IsNull() || val.Type() != cty.String || !isUnresolvedValue(val.AsString())
+}
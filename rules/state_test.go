@@ -0,0 +1,331 @@
+package rules
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/tfbreak-plugin-sdk/helper"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// testStateFile is a state file with a resource group, two instances of a storage account
+// that disagree on account_tier, and a resource group in a module instance.
+const testStateFile = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "azurerm_resource_group",
+      "name": "example",
+      "instances": [{"attributes": {"name": "rg", "location": "westeurope", "tags": {"env": "prod"}}}]
+    },
+    {
+      "mode": "managed",
+      "type": "azurerm_storage_account",
+      "name": "example",
+      "instances": [
+        {"index_key": 0, "attributes": {"account_tier": "Standard", "identity": [{"type": "SystemAssigned"}]}},
+        {"index_key": 1, "attributes": {"account_tier": "Premium", "identity": [{"type": "SystemAssigned"}]}}
+      ]
+    },
+    {
+      "module": "module.network[\"a\"]",
+      "mode": "managed",
+      "type": "azurerm_resource_group",
+      "name": "this",
+      "instances": [{"attributes": {"location": "westeurope"}}]
+    },
+    {
+      "mode": "data",
+      "type": "azurerm_client_config",
+      "name": "current",
+      "instances": [{"attributes": {"tenant_id": "00000000-0000-0000-0000-000000000000"}}]
+    }
+  ]
+}`
+
+// testStateOutput is the output of `terraform show -json` for the same resource groups.
+const testStateOutput = `{
+  "format_version": "1.0",
+  "values": {
+    "root_module": {
+      "resources": [
+        {"address": "azurerm_resource_group.example", "mode": "managed", "type": "azurerm_resource_group", "name": "example", "values": {"location": "westeurope"}},
+        {"address": "data.azurerm_client_config.current", "mode": "data", "type": "azurerm_client_config", "name": "current", "values": {"tenant_id": "x"}}
+      ],
+      "child_modules": [
+        {
+          "address": "module.network[\"a\"]",
+          "resources": [
+            {"address": "module.network[\"a\"].azurerm_resource_group.this", "mode": "managed", "type": "azurerm_resource_group", "name": "this", "values": {"location": "westeurope"}}
+          ]
+        }
+      ]
+    }
+  }
+}`
+
+func TestParsePriorState(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"state file", testStateFile},
+		{"show output", testStateOutput},
+		{"plan output", `{"format_version": "1.2", "prior_state": ` + testStateOutput + `}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := parsePriorState([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parsePriorState returned error: %v", err)
+			}
+			for _, address := range []string{"azurerm_resource_group.example", "module.network.azurerm_resource_group.this"} {
+				if val, ok := state.value(address, "location"); !ok || !val.RawEquals(cty.StringVal("westeurope")) {
+					t.Errorf("Expected location of %s to be westeurope, got %#v, %v", address, val, ok)
+				}
+			}
+			if _, ok := state.value("data.azurerm_client_config.current", "tenant_id"); ok {
+				t.Error("Expected data sources to be left out")
+			}
+		})
+	}
+}
+
+func TestParsePriorState_Errors(t *testing.T) {
+	for _, data := range []string{
+		`not json`,
+		`{"version": 3, "modules": []}`,
+		`{"format_version": "1.0"}`,
+	} {
+		if _, err := parsePriorState([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %s", data)
+		}
+	}
+}
+
+func TestPriorStateValue(t *testing.T) {
+	state, err := parsePriorState([]byte(testStateFile))
+	if err != nil {
+		t.Fatalf("parsePriorState returned error: %v", err)
+	}
+
+	tests := []struct {
+		address  string
+		attrPath string
+		want     cty.Value
+	}{
+		{"azurerm_resource_group.example", "tags", cty.ObjectVal(map[string]cty.Value{"env": cty.StringVal("prod")})},
+		{"azurerm_storage_account.example", "identity.type", cty.StringVal("SystemAssigned")},
+		// The instances disagree
+		{"azurerm_storage_account.example", "account_tier", cty.NilVal},
+		{"azurerm_resource_group.example", "managed_by", cty.NilVal},
+		{"azurerm_resource_group.missing", "location", cty.NilVal},
+	}
+
+	for _, tt := range tests {
+		got, ok := state.value(tt.address, tt.attrPath)
+		if ok != (tt.want != cty.NilVal) || (ok && !got.RawEquals(tt.want)) {
+			t.Errorf("value(%s, %s) = %#v, %v, want %#v", tt.address, tt.attrPath, got, ok, tt.want)
+		}
+	}
+}
+
+func TestForceNew_StateFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldLocation string
+		newLocation string
+		wantIssue   string
+	}{
+		{
+			name:        "dynamic old value changed",
+			oldLocation: `var.location`,
+			newLocation: `"northeurope"`,
+			wantIssue:   `Changing "location" forces recreation of azurerm_resource_group.example (old: westeurope (state), new: northeurope)`,
+		},
+		{
+			name:        "dynamic old value unchanged",
+			oldLocation: `var.location`,
+			newLocation: `"westeurope"`,
+		},
+		{
+			// Static old values are compared as configured
+			name:        "static old value",
+			oldLocation: `"eastus"`,
+			newLocation: `"northeurope"`,
+			wantIssue:   `(old: eastus, new: northeurope)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			rule.readFile = func(name string) ([]byte, error) {
+				if name != "terraform.tfstate" {
+					return nil, fs.ErrNotExist
+				}
+				return []byte(testStateFile), nil
+			}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": `
+resource "azurerm_resource_group" "example" {
+  name     = "rg"
+  location = ` + tt.oldLocation + `
+}`},
+				map[string]string{"main.tf": `
+resource "azurerm_resource_group" "example" {
+  name     = "rg"
+  location = ` + tt.newLocation + `
+}`},
+			)
+
+			if err := rule.Check(&configRunner{Runner: runner, config: `state_file = "terraform.tfstate"`}); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if tt.wantIssue == "" {
+				if len(runner.Issues) != 0 {
+					t.Fatalf("Expected no issues, got %d: %v", len(runner.Issues), runner.Issues)
+				}
+				return
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			if !strings.Contains(runner.Issues[0].Message, tt.wantIssue) {
+				t.Errorf("Expected message to contain %q, got %q", tt.wantIssue, runner.Issues[0].Message)
+			}
+		})
+	}
+}
+
+func TestForceNew_StateFileModuleCalls(t *testing.T) {
+	rule := NewAzurermForceNewRule()
+	rule.readFile = func(string) ([]byte, error) { return []byte(testStateOutput), nil }
+	rule.readModule = testModules(map[string]map[string]string{
+		filepath.Join("old", "modules", "network"): {"main.tf": networkModule},
+		filepath.Join("new", "modules", "network"): {"main.tf": networkModule},
+	}, nil)
+
+	runner := helper.TestRunner(t,
+		map[string]string{"old/main.tf": moduleCallFile(`var.location`)},
+		map[string]string{"new/main.tf": moduleCallFile(`"northeurope"`)},
+	)
+
+	if err := rule.Check(&configRunner{Runner: runner, config: `state_file = "state.json"`}); err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if len(runner.Issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+	}
+	if f := parseFinding(t, runner.Issues[0].Message); f.OldValue != "westeurope (state)" || f.NewValue != "northeurope" {
+		t.Errorf("Expected the old value from state, got %q and %q", f.OldValue, f.NewValue)
+	}
+}
+
+func TestForceNew_StateFileSensitive(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_mssql_server": {
+				"block": {
+					"attributes": {
+						"administrator_login_password": {"type": "string", "optional": true, "force_new": true, "sensitive": true}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		wantOld string
+		wantNew string
+	}{
+		{"redacted", ``, "<sensitive> (state)", "<sensitive>"},
+		{"hashed", `hash_sensitive_values = true`, "<sensitive:5d865dea> (state)", "<sensitive:fc97bb52>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			rule.schema = s
+			rule.readFile = func(string) ([]byte, error) {
+				return []byte(`{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "azurerm_mssql_server",
+      "name": "example",
+      "instances": [{"attributes": {"administrator_login_password": "old-secret"}}]
+    }
+  ]
+}`), nil
+			}
+
+			runner := helper.TestRunner(t,
+				map[string]string{"main.tf": `
+resource "azurerm_mssql_server" "example" {
+  administrator_login_password = var.password
+}`},
+				map[string]string{"main.tf": `
+resource "azurerm_mssql_server" "example" {
+  administrator_login_password = "new-secret"
+}`},
+			)
+
+			if err := rule.Check(&configRunner{Runner: runner, config: tt.config + "\nstate_file = \"terraform.tfstate\""}); err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if len(runner.Issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %d: %v", len(runner.Issues), runner.Issues)
+			}
+			message := runner.Issues[0].Message
+			if strings.Contains(message, "secret") {
+				t.Errorf("Expected sensitive values to be masked, got %q", message)
+			}
+			// The value is masked before it is marked as coming from state
+			if f := parseFinding(t, message); f.OldValue != tt.wantOld || f.NewValue != tt.wantNew {
+				t.Errorf("Expected %q and %q, got %q and %q", tt.wantOld, tt.wantNew, f.OldValue, f.NewValue)
+			}
+		})
+	}
+}
+
+func TestForceNew_StateFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		readFile func(string) ([]byte, error)
+		wantErr  string
+	}{
+		{"missing", func(string) ([]byte, error) { return nil, fs.ErrNotExist }, "read azurerm_force_new state_file"},
+		{"invalid", func(string) ([]byte, error) { return []byte(`{}`), nil }, "parse azurerm_force_new state_file terraform.tfstate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewAzurermForceNewRule()
+			rule.readFile = tt.readFile
+			runner := helper.TestRunner(t, map[string]string{"main.tf": ""}, map[string]string{"main.tf": ""})
+
+			err := rule.Check(&configRunner{Runner: runner, config: `state_file = "terraform.tfstate"`})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if tt.name == "missing" && !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Expected the read error to be wrapped, got %v", err)
+			}
+		})
+	}
+}
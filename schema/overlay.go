@@ -0,0 +1,150 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Overlay is a curated set of corrections applied on top of the extracted schema,
+// for ForceNew facts that the extraction gets wrong or cannot see.
+// The embedded overlay is schema/overlay.json.
+type Overlay struct {
+	// Attributes holds the corrections by "type.path", e.g. "azurerm_storage_account.account_kind".
+	Attributes map[string]*AttributeOverlay `json:"attributes"`
+}
+
+// AttributeOverlay is a correction of a single attribute.
+type AttributeOverlay struct {
+	// ForceNew, when set, adds (true) or removes (false) ForceNew. Removing ForceNew
+	// also removes conditions, unless ForceNewIf is set.
+	ForceNew *bool `json:"force_new,omitempty"`
+	// ForceNewIf, when set, replaces the conditions under which a change forces a new resource.
+	ForceNewIf []ForceNewCondition `json:"force_new_if,omitempty"`
+	// Default, when set, is the provider default of the attribute.
+	Default interface{} `json:"default,omitempty"`
+	// OneWay marks a change that is applied in place but cannot be reverted
+	// without recreating the resource.
+	OneWay bool `json:"one_way,omitempty"`
+	// DataLoss marks an attribute whose recreation loses data stored in the resource.
+	DataLoss bool `json:"data_loss,omitempty"`
+	// Explanation describes the correction, and is shown with findings.
+	Explanation string `json:"explanation,omitempty"`
+	// DeprecationMessage, when set, marks the attribute as deprecated with this message,
+	// for deprecations whose message cannot be extracted from the provider source.
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
+}
+
+// ParseOverlay parses an overlay from JSON.
+func ParseOverlay(data []byte) (*Overlay, error) {
+	var overlay Overlay
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, err
+	}
+	for key := range overlay.Attributes {
+		if _, _, ok := splitOverlayKey(key); !ok {
+			return nil, fmt.Errorf("overlay key %q is not a type.path", key)
+		}
+	}
+	return &overlay, nil
+}
+
+// LoadOverlay returns the embedded overlay.
+func LoadOverlay() (*Overlay, error) {
+	data, err := embeddedSchema.ReadFile("overlay.json")
+	if err != nil {
+		return nil, err
+	}
+	return ParseOverlay(data)
+}
+
+// splitOverlayKey splits a "type.path" key into the resource type and attribute path.
+func splitOverlayKey(key string) (string, string, bool) {
+	resourceType, path, ok := strings.Cut(key, ".")
+	return resourceType, path, ok && resourceType != "" && path != ""
+}
+
+// ApplyOverlay merges the corrections of an overlay into the schema.
+// Entries for attributes that are not in the schema are skipped.
+func (s *Schema) ApplyOverlay(overlay *Overlay) {
+	for key, entry := range overlay.Attributes {
+		attr := s.overlayAttribute(key)
+		if attr == nil {
+			continue
+		}
+		if entry.ForceNew != nil {
+			attr.ForceNew = *entry.ForceNew
+			if !attr.ForceNew {
+				attr.ForceNewIf = nil
+			}
+		}
+		if len(entry.ForceNewIf) > 0 {
+			attr.ForceNewIf = entry.ForceNewIf
+		}
+		if entry.Default != nil {
+			attr.Default = entry.Default
+		}
+		attr.OneWay = attr.OneWay || entry.OneWay
+		attr.DataLoss = attr.DataLoss || entry.DataLoss
+		if entry.Explanation != "" {
+			attr.Explanation = entry.Explanation
+		}
+		if entry.DeprecationMessage != "" {
+			attr.Deprecated = true
+			attr.DeprecationMessage = entry.DeprecationMessage
+		}
+	}
+}
+
+// StaleOverlayEntries checks the entries of an overlay against a schema that the
+// overlay has not been applied to, such as a freshly extracted schema.
+// It returns a warning, sorted by key, for each entry whose attribute is not in the
+// schema, or whose ForceNew or default correction already matches the schema.
+func (s *Schema) StaleOverlayEntries(overlay *Overlay) []string {
+	keys := make([]string, 0, len(overlay.Attributes))
+	for key := range overlay.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var warnings []string
+	for _, key := range keys {
+		entry := overlay.Attributes[key]
+		attr := s.overlayAttribute(key)
+		switch {
+		case attr == nil:
+			warnings = append(warnings, fmt.Sprintf("%s is not in the schema", key))
+		case len(entry.ForceNewIf) > 0 && reflect.DeepEqual(entry.ForceNewIf, attr.ForceNewIf):
+			warnings = append(warnings, fmt.Sprintf("%s: force_new_if already matches the schema", key))
+		case entry.ForceNew != nil && len(entry.ForceNewIf) == 0 && *entry.ForceNew == attr.ForceNew &&
+			(attr.ForceNew || len(attr.ForceNewIf) == 0):
+			warnings = append(warnings, fmt.Sprintf("%s: force_new already matches the schema", key))
+		case entry.Default != nil && sameJSON(entry.Default, attr.Default):
+			warnings = append(warnings, fmt.Sprintf("%s: default already matches the schema", key))
+		}
+	}
+	return warnings
+}
+
+// overlayAttribute returns the attribute addressed by a "type.path" key, or nil.
+func (s *Schema) overlayAttribute(key string) *AttributeSchema {
+	resourceType, path, ok := splitOverlayKey(key)
+	if !ok {
+		return nil
+	}
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok || rs.Block == nil {
+		return nil
+	}
+	return getAttributeFromBlock(rs.Block, path)
+}
+
+// sameJSON reports whether two JSON values are equal, regardless of their Go types,
+// e.g. an int64 from the extraction and a float64 from a parsed file.
+func sameJSON(a, b interface{}) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(dataA) == string(dataB)
+}
@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+const overlayTestSchema = `{
+	"resource_schemas": {
+		"test_resource": {
+			"block": {
+				"attributes": {
+					"name": {"type": "string", "required": true, "force_new": true},
+					"kind": {"type": "string", "optional": true, "force_new": true},
+					"size_gb": {"type": "number", "optional": true},
+					"tier": {"type": "string", "optional": true, "force_new_if": [{"change": "increase"}]},
+					"sku": {"type": "string", "optional": true, "default": "Standard"}
+				},
+				"block_types": {
+					"identity": {
+						"nesting_mode": "list",
+						"block": {
+							"attributes": {
+								"type": {"type": "string", "required": true}
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestParseOverlay(t *testing.T) {
+	overlay, err := ParseOverlay([]byte(`{
+		"attributes": {
+			"test_resource.size_gb": {"force_new_if": [{"change": "decrease"}], "data_loss": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseOverlay failed: %v", err)
+	}
+	entry := overlay.Attributes["test_resource.size_gb"]
+	if entry == nil || !entry.DataLoss || len(entry.ForceNewIf) != 1 || entry.ForceNewIf[0].Change != ForceNewDecrease {
+		t.Errorf("Unexpected overlay entry: %+v", entry)
+	}
+
+	for _, data := range []string{`{invalid}`, `{"attributes": {"test_resource": {}}}`, `{"attributes": {".name": {}}}`} {
+		if _, err := ParseOverlay([]byte(data)); err == nil {
+			t.Errorf("Expected ParseOverlay(%s) to fail", data)
+		}
+	}
+}
+
+func TestSchema_ApplyOverlay(t *testing.T) {
+	schema, err := LoadFromJSON([]byte(overlayTestSchema))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+	overlay, err := ParseOverlay([]byte(`{
+		"attributes": {
+			"test_resource.kind": {"force_new": false},
+			"test_resource.tier": {"force_new": false, "deprecation_message": "Use ` + "`kind`" + ` instead."},
+			"test_resource.identity.type": {"force_new": true, "data_loss": true},
+			"test_resource.size_gb": {
+				"force_new_if": [{"change": "decrease"}],
+				"default": 32,
+				"one_way": true,
+				"explanation": "Disks cannot shrink."
+			},
+			"test_resource.removed": {"force_new": true},
+			"unknown_resource.name": {"force_new": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseOverlay failed: %v", err)
+	}
+
+	schema.ApplyOverlay(overlay)
+
+	got := schema.GetForceNewAttributes("test_resource")
+	sort.Strings(got)
+	if want := []string{"identity.type", "name", "size_gb"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetForceNewAttributes() = %v, want %v", got, want)
+	}
+	if got := schema.GetForceNewConditions("test_resource", "size_gb"); len(got) != 1 || got[0].Change != ForceNewDecrease {
+		t.Errorf("Expected a decrease condition for size_gb, got %v", got)
+	}
+	if got, want := schema.GetOneWayAttributes("test_resource"), []string{"size_gb"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetOneWayAttributes() = %v, want %v", got, want)
+	}
+	if attr := schema.GetAttribute("test_resource", "size_gb"); attr.Explanation != "Disks cannot shrink." {
+		t.Errorf("Expected the explanation to be applied, got %q", attr.Explanation)
+	}
+	if def, ok := schema.GetAttribute("test_resource", "size_gb").DefaultValue(); !ok || !def.RawEquals(cty.NumberIntVal(32)) {
+		t.Errorf("Expected the default to be applied, got %#v", def)
+	}
+	if attr := schema.GetAttribute("test_resource", "identity.type"); !attr.DataLoss {
+		t.Error("Expected identity.type to be marked as data loss")
+	}
+	if got := schema.GetDeprecatedAttributes("test_resource"); !reflect.DeepEqual(got, map[string]string{"tier": "Use `kind` instead."}) {
+		t.Errorf("Expected tier to be deprecated with the overlay message, got %v", got)
+	}
+	if schema.GetAttribute("test_resource", "removed") != nil {
+		t.Error("Expected entries for unknown attributes to be skipped")
+	}
+}
+
+func TestSchema_StaleOverlayEntries(t *testing.T) {
+	schema, err := LoadFromJSON([]byte(overlayTestSchema))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+	overlay, err := ParseOverlay([]byte(`{
+		"attributes": {
+			"test_resource.name": {"force_new": true},
+			"test_resource.kind": {"force_new": false},
+			"test_resource.size_gb": {"force_new": false, "data_loss": true},
+			"test_resource.tier": {"force_new_if": [{"change": "increase"}]},
+			"test_resource.identity.type": {"force_new": true},
+			"test_resource.removed": {"explanation": "Removed upstream."},
+			"test_resource.sku": {"default": "Standard"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseOverlay failed: %v", err)
+	}
+
+	want := []string{
+		"test_resource.name: force_new already matches the schema",
+		"test_resource.removed is not in the schema",
+		"test_resource.size_gb: force_new already matches the schema",
+		"test_resource.sku: default already matches the schema",
+		"test_resource.tier: force_new_if already matches the schema",
+	}
+	if got := schema.StaleOverlayEntries(overlay); !reflect.DeepEqual(got, want) {
+		t.Errorf("StaleOverlayEntries() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadOverlay(t *testing.T) {
+	overlay, err := LoadOverlay()
+	if err != nil {
+		t.Fatalf("LoadOverlay failed: %v", err)
+	}
+	for key, entry := range overlay.Attributes {
+		if entry.ForceNew == nil && len(entry.ForceNewIf) == 0 && !entry.OneWay && !entry.DataLoss && entry.Explanation == "" {
+			t.Errorf("Overlay entry %s has no corrections", key)
+		}
+	}
+
+	// Load applies the embedded overlay
+	schema := Load()
+	if got := schema.GetForceNewConditions("azurerm_storage_account", "account_kind"); len(got) == 0 {
+		t.Error("Expected the overlay to add conditions to azurerm_storage_account.account_kind")
+	}
+	if attr := schema.GetAttribute("azurerm_storage_account", "account_kind"); attr == nil || !attr.OneWay || attr.Explanation == "" {
+		t.Errorf("Expected azurerm_storage_account.account_kind to be one-way with an explanation, got %+v", attr)
+	}
+}
+
+func TestLoadOverlay_ResolvesAgainstEmbeddedSchema(t *testing.T) {
+	overlay, err := LoadOverlay()
+	if err != nil {
+		t.Fatalf("LoadOverlay failed: %v", err)
+	}
+
+	// ApplyOverlay skips entries that are not in the schema, so check that none are
+	schema := Load()
+	for key := range overlay.Attributes {
+		resourceType, path, _ := splitOverlayKey(key)
+		if schema.GetAttribute(resourceType, path) == nil {
+			t.Errorf("Overlay entry %s is not in the embedded schema", key)
+		}
+	}
+}
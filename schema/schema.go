@@ -8,13 +8,18 @@ import (
 	"io"
 	"strings"
 	"sync"
+
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 )
 
-//go:embed azurerm.json.gz
+//go:embed azurerm.json.gz overlay.json
 var embeddedSchema embed.FS
 
 // Schema represents the Azure RM provider schema.
 type Schema struct {
+	// ProviderVersion is the provider version the schema was extracted from, if recorded.
+	ProviderVersion string                     `json:"provider_version,omitempty"`
 	ResourceSchemas map[string]*ResourceSchema `json:"resource_schemas"`
 }
 
@@ -25,20 +30,103 @@ type ResourceSchema struct {
 
 // BlockSchema represents a block within a resource schema.
 type BlockSchema struct {
-	Attributes map[string]*AttributeSchema `json:"attributes,omitempty"`
+	Attributes map[string]*AttributeSchema   `json:"attributes,omitempty"`
 	BlockTypes map[string]*NestedBlockSchema `json:"block_types,omitempty"`
+	// Deprecated marks a deprecated resource type, as in `terraform providers schema -json`.
+	Deprecated bool `json:"deprecated,omitempty"`
+	// DeprecationMessage is the provider's deprecation message, which the Terraform JSON
+	// schema does not include. It is extracted from the provider source.
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
 }
 
 // AttributeSchema represents an attribute within a block.
 type AttributeSchema struct {
+	// Type is the attribute type in Terraform's JSON type encoding,
+	// e.g. "string" or ["list", "string"]. Use CtyType to decode it.
 	Type        interface{} `json:"type,omitempty"`
 	Description string      `json:"description,omitempty"`
 	Required    bool        `json:"required,omitempty"`
 	Optional    bool        `json:"optional,omitempty"`
 	Computed    bool        `json:"computed,omitempty"`
 	ForceNew    bool        `json:"force_new,omitempty"`
-	Sensitive   bool        `json:"sensitive,omitempty"`
-	Deprecated  string      `json:"deprecated,omitempty"`
+	// ForceNewIf lists the conditions under which a change forces a new resource,
+	// for attributes that are only ForceNew for some changes. When set, a change
+	// forces a new resource if any condition holds, regardless of ForceNew.
+	ForceNewIf []ForceNewCondition `json:"force_new_if,omitempty"`
+	Sensitive  bool                `json:"sensitive,omitempty"`
+	Deprecated bool                `json:"deprecated,omitempty"`
+	// DeprecationMessage is the provider's deprecation message, extracted from the
+	// provider source or curated in the overlay.
+	DeprecationMessage string `json:"deprecation_message,omitempty"`
+	// Default is the value the provider uses when the attribute is not set,
+	// as a JSON value of the attribute type. Use DefaultValue to decode it.
+	Default interface{} `json:"default,omitempty"`
+	// OneWay, DataLoss and Explanation are curated in the overlay (see Overlay).
+	OneWay      bool   `json:"one_way,omitempty"`
+	DataLoss    bool   `json:"data_loss,omitempty"`
+	Explanation string `json:"explanation,omitempty"`
+}
+
+// CtyType decodes the attribute type into a cty.Type.
+// Returns cty.DynamicPseudoType if the type is missing or cannot be decoded.
+func (a *AttributeSchema) CtyType() cty.Type {
+	if a == nil || a.Type == nil {
+		return cty.DynamicPseudoType
+	}
+	data, err := json.Marshal(a.Type)
+	if err != nil {
+		return cty.DynamicPseudoType
+	}
+	ty, err := ctyjson.UnmarshalType(data)
+	if err != nil {
+		return cty.DynamicPseudoType
+	}
+	return ty
+}
+
+// DefaultValue decodes the provider default of the attribute into a value of its type.
+// Returns false if the attribute has no default, or the default does not match the type.
+func (a *AttributeSchema) DefaultValue() (cty.Value, bool) {
+	if a == nil || a.Default == nil {
+		return cty.NilVal, false
+	}
+	data, err := json.Marshal(a.Default)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	ty := a.CtyType()
+	if ty == cty.DynamicPseudoType {
+		if ty, err = ctyjson.ImpliedType(data); err != nil {
+			return cty.NilVal, false
+		}
+	}
+	val, err := ctyjson.Unmarshal(data, ty)
+	if err != nil {
+		return cty.NilVal, false
+	}
+	return val, true
+}
+
+// ForceNewChange is the kind of change a ForceNewCondition matches.
+type ForceNewChange string
+
+const (
+	// ForceNewDecrease matches a numeric value that decreases, e.g. a disk size.
+	ForceNewDecrease ForceNewChange = "decrease"
+	// ForceNewIncrease matches a numeric value that increases.
+	ForceNewIncrease ForceNewChange = "increase"
+	// ForceNewTransition matches a change from one of From to one of To.
+	ForceNewTransition ForceNewChange = "transition"
+)
+
+// ForceNewCondition is a condition under which a change of an attribute forces a new resource.
+// Azure RM implements these in CustomizeDiff, so they are not part of the boolean ForceNew.
+type ForceNewCondition struct {
+	Change ForceNewChange `json:"change"`
+	// From and To are the values a transition changes from and to, compared
+	// case-insensitively. An empty list matches any value.
+	From []string `json:"from,omitempty"`
+	To   []string `json:"to,omitempty"`
 }
 
 // NestedBlockSchema represents a nested block within a resource.
@@ -55,7 +143,7 @@ var (
 	schemaErr      error
 )
 
-// Load returns the embedded Azure RM provider schema.
+// Load returns the embedded Azure RM provider schema, with the embedded overlay applied.
 // The schema is loaded lazily and cached for subsequent calls.
 func Load() *Schema {
 	schemaOnce.Do(func() {
@@ -71,7 +159,7 @@ func Load() *Schema {
 	return schemaInstance
 }
 
-// loadFromEmbedded loads the schema from the embedded gzip file.
+// loadFromEmbedded loads the schema from the embedded gzip file and applies the embedded overlay.
 func loadFromEmbedded() (*Schema, error) {
 	f, err := embeddedSchema.Open("azurerm.json.gz")
 	if err != nil {
@@ -95,6 +183,12 @@ func loadFromEmbedded() (*Schema, error) {
 		return nil, err
 	}
 
+	overlay, err := LoadOverlay()
+	if err != nil {
+		return nil, err
+	}
+	schema.ApplyOverlay(overlay)
+
 	return &schema, nil
 }
 
@@ -107,7 +201,8 @@ func LoadFromJSON(data []byte) (*Schema, error) {
 	return &schema, nil
 }
 
-// GetForceNewAttributes returns the list of ForceNew attribute names for a resource type.
+// GetForceNewAttributes returns the list of ForceNew attribute names for a resource type,
+// including attributes that are only ForceNew under conditions.
 // It searches both top-level attributes and nested blocks.
 func (s *Schema) GetForceNewAttributes(resourceType string) []string {
 	rs, ok := s.ResourceSchemas[resourceType]
@@ -129,7 +224,7 @@ func getForceNewFromBlock(block *BlockSchema, prefix string) []string {
 
 	// Check attributes
 	for name, attr := range block.Attributes {
-		if attr.ForceNew {
+		if attr.ForceNew || len(attr.ForceNewIf) > 0 {
 			fullName := name
 			if prefix != "" {
 				fullName = prefix + "." + name
@@ -167,7 +262,8 @@ func (s *Schema) GetResourceTypes() []string {
 	return types
 }
 
-// IsForceNew checks if a specific attribute is ForceNew for a resource type.
+// IsForceNew checks if a specific attribute is ForceNew for a resource type,
+// either always or under conditions.
 func (s *Schema) IsForceNew(resourceType, attributePath string) bool {
 	rs, ok := s.ResourceSchemas[resourceType]
 	if !ok {
@@ -188,7 +284,7 @@ func isForceNewInBlock(block *BlockSchema, path string) bool {
 	// Check if it's a direct attribute
 	if attr, ok := block.Attributes[name]; ok {
 		if len(parts) == 1 {
-			return attr.ForceNew
+			return attr.ForceNew || len(attr.ForceNewIf) > 0
 		}
 		// Can't descend into attribute
 		return false
@@ -208,3 +304,176 @@ func isForceNewInBlock(block *BlockSchema, path string) bool {
 
 	return false
 }
+
+// GetForceNewConditions returns the conditions under which a change of an attribute
+// forces a new resource. An empty result means any change does, if the attribute is ForceNew.
+func (s *Schema) GetForceNewConditions(resourceType, attributePath string) []ForceNewCondition {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok || rs.Block == nil {
+		return nil
+	}
+
+	attr := getAttributeFromBlock(rs.Block, attributePath)
+	if attr == nil {
+		return nil
+	}
+	return attr.ForceNewIf
+}
+
+// GetAttribute returns the schema of an attribute at a dot-separated path,
+// or nil if the path is not an attribute of the resource type.
+func (s *Schema) GetAttribute(resourceType, attributePath string) *AttributeSchema {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok || rs.Block == nil {
+		return nil
+	}
+	return getAttributeFromBlock(rs.Block, attributePath)
+}
+
+// GetAttributeType returns the type of an attribute at a dot-separated path.
+// Returns cty.DynamicPseudoType if the attribute or its type is unknown.
+func (s *Schema) GetAttributeType(resourceType, attributePath string) cty.Type {
+	return s.GetAttribute(resourceType, attributePath).CtyType()
+}
+
+// GetOneWayAttributes returns the attribute paths of a resource type whose changes
+// cannot be reverted without recreating the resource.
+func (s *Schema) GetOneWayAttributes(resourceType string) []string {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok || rs.Block == nil {
+		return nil
+	}
+	return getOneWayFromBlock(rs.Block, "")
+}
+
+// getOneWayFromBlock recursively finds one-way attributes in a block.
+func getOneWayFromBlock(block *BlockSchema, prefix string) []string {
+	var attrs []string
+	for name, attr := range block.Attributes {
+		if attr.OneWay {
+			attrs = append(attrs, prefix+name)
+		}
+	}
+	for name, nested := range block.BlockTypes {
+		if nested.Block != nil {
+			attrs = append(attrs, getOneWayFromBlock(nested.Block, prefix+name+".")...)
+		}
+	}
+	return attrs
+}
+
+// IsSensitive checks if a specific attribute is Sensitive for a resource type.
+// Sensitive attributes hold secrets, such as passwords and access keys.
+func (s *Schema) IsSensitive(resourceType, attributePath string) bool {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok || rs.Block == nil {
+		return false
+	}
+
+	attr := getAttributeFromBlock(rs.Block, attributePath)
+	return attr != nil && attr.Sensitive
+}
+
+// getAttributeFromBlock returns the attribute at a dot-separated path in a block,
+// descending into nested blocks. Returns nil if the path is not an attribute.
+func getAttributeFromBlock(block *BlockSchema, path string) *AttributeSchema {
+	parts := strings.SplitN(path, ".", 2)
+	if len(parts) == 1 {
+		return block.Attributes[parts[0]]
+	}
+
+	nested, ok := block.BlockTypes[parts[0]]
+	if !ok || nested.Block == nil {
+		return nil
+	}
+	return getAttributeFromBlock(nested.Block, parts[1])
+}
+
+// GetResourceDeprecation reports whether a resource type is deprecated, and returns its
+// deprecation message. The message is empty if the provider source did not provide one.
+func (s *Schema) GetResourceDeprecation(resourceType string) (string, bool) {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok || rs.Block == nil || !rs.Block.Deprecated {
+		return "", false
+	}
+	return rs.Block.DeprecationMessage, true
+}
+
+// GetDeprecatedAttributes returns the deprecated attribute paths for a resource type,
+// mapped to their deprecation messages, which are empty if unknown.
+// It searches both top-level attributes and nested blocks.
+func (s *Schema) GetDeprecatedAttributes(resourceType string) map[string]string {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok {
+		return nil
+	}
+	if rs.Block == nil {
+		return nil
+	}
+
+	attrs := make(map[string]string)
+	getDeprecatedFromBlock(rs.Block, "", attrs)
+	return attrs
+}
+
+// getDeprecatedFromBlock recursively collects deprecated attributes in a block.
+func getDeprecatedFromBlock(block *BlockSchema, prefix string, attrs map[string]string) {
+	for name, attr := range block.Attributes {
+		if attr.Deprecated {
+			fullName := name
+			if prefix != "" {
+				fullName = prefix + "." + name
+			}
+			attrs[fullName] = attr.DeprecationMessage
+		}
+	}
+
+	for name, nested := range block.BlockTypes {
+		if nested.Block != nil {
+			nestedPrefix := name
+			if prefix != "" {
+				nestedPrefix = prefix + "." + name
+			}
+			getDeprecatedFromBlock(nested.Block, nestedPrefix, attrs)
+		}
+	}
+}
+
+// GetAttributePaths returns all attribute paths for a resource type, including
+// attributes of nested blocks (e.g. "identity.type").
+func (s *Schema) GetAttributePaths(resourceType string) []string {
+	rs, ok := s.ResourceSchemas[resourceType]
+	if !ok {
+		return nil
+	}
+	if rs.Block == nil {
+		return nil
+	}
+
+	return getAttributePathsFromBlock(rs.Block, "")
+}
+
+// getAttributePathsFromBlock recursively collects attribute paths in a block.
+func getAttributePathsFromBlock(block *BlockSchema, prefix string) []string {
+	var paths []string
+
+	for name := range block.Attributes {
+		fullName := name
+		if prefix != "" {
+			fullName = prefix + "." + name
+		}
+		paths = append(paths, fullName)
+	}
+
+	for name, nested := range block.BlockTypes {
+		if nested.Block != nil {
+			nestedPrefix := name
+			if prefix != "" {
+				nestedPrefix = prefix + "." + name
+			}
+			paths = append(paths, getAttributePathsFromBlock(nested.Block, nestedPrefix)...)
+		}
+	}
+
+	return paths
+}
@@ -2,7 +2,10 @@ package schema
 
 import (
 	"sort"
+	"strings"
 	"testing"
+
+	"github.com/zclconf/go-cty/cty"
 )
 
 func TestSchema_Load(t *testing.T) {
@@ -13,6 +16,9 @@ func TestSchema_Load(t *testing.T) {
 	if schema.ResourceSchemas == nil {
 		t.Fatal("ResourceSchemas is nil")
 	}
+	if schema.ProviderVersion == "" {
+		t.Error("Expected the embedded schema to record its provider version")
+	}
 }
 
 func TestSchema_GetForceNew_ResourceGroup(t *testing.T) {
@@ -89,6 +95,204 @@ func TestSchema_IsForceNew(t *testing.T) {
 	}
 }
 
+func TestSchema_IsSensitive(t *testing.T) {
+	schema, err := LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"test_resource": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true},
+						"password": {"type": "string", "optional": true, "force_new": true, "sensitive": true}
+					},
+					"block_types": {
+						"credential": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"secret": {"type": "string", "required": true, "sensitive": true}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		resourceType string
+		attribute    string
+		expected     bool
+	}{
+		{"test_resource", "name", false},
+		{"test_resource", "password", true},
+		{"test_resource", "credential.secret", true},
+		{"test_resource", "credential", false},
+		{"test_resource", "password.nested", false},
+		{"nonexistent_resource", "password", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceType+"."+tt.attribute, func(t *testing.T) {
+			if got := schema.IsSensitive(tt.resourceType, tt.attribute); got != tt.expected {
+				t.Errorf("IsSensitive(%s, %s) = %v, want %v", tt.resourceType, tt.attribute, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSchema_GetForceNewConditions(t *testing.T) {
+	schema, err := LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"test_resource": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true},
+						"size_gb": {"type": "number", "optional": true, "force_new_if": [{"change": "decrease"}]}
+					},
+					"block_types": {
+						"sku": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"tier": {"type": "string", "required": true, "force_new_if": [{"change": "transition", "from": ["Basic"], "to": ["Standard", "Premium"]}]}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	attrs := schema.GetForceNewAttributes("test_resource")
+	sort.Strings(attrs)
+	if strings.Join(attrs, ",") != "name,size_gb,sku.tier" {
+		t.Errorf("GetForceNewAttributes() = %v, want conditional attributes included", attrs)
+	}
+	if !schema.IsForceNew("test_resource", "size_gb") {
+		t.Error("Expected IsForceNew to be true for a conditional attribute")
+	}
+
+	if got := schema.GetForceNewConditions("test_resource", "name"); len(got) != 0 {
+		t.Errorf("Expected no conditions for name, got %v", got)
+	}
+	if got := schema.GetForceNewConditions("test_resource", "size_gb"); len(got) != 1 || got[0].Change != ForceNewDecrease {
+		t.Errorf("Expected a decrease condition for size_gb, got %v", got)
+	}
+	got := schema.GetForceNewConditions("test_resource", "sku.tier")
+	if len(got) != 1 || got[0].Change != ForceNewTransition || len(got[0].From) != 1 || len(got[0].To) != 2 {
+		t.Errorf("Expected a transition condition for sku.tier, got %v", got)
+	}
+	if got := schema.GetForceNewConditions("nonexistent_resource", "size_gb"); got != nil {
+		t.Errorf("Expected no conditions for nonexistent resource, got %v", got)
+	}
+}
+
+func TestAttributeSchema_CtyType(t *testing.T) {
+	schema, err := LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"test_resource": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string"},
+						"size": {"type": "number"},
+						"enabled": {"type": "bool"},
+						"zones": {"type": ["list", "string"]},
+						"ports": {"type": ["set", "number"]},
+						"tags": {"type": ["map", "string"]},
+						"rule": {"type": ["object", {"name": "string", "priority": "number"}]},
+						"pair": {"type": ["tuple", ["string", "bool"]]},
+						"value": {"type": "dynamic"},
+						"untyped": {},
+						"invalid": {"type": ["list"]}
+					},
+					"block_types": {
+						"identity": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"identity_ids": {"type": ["set", "string"]}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		attribute string
+		expected  cty.Type
+	}{
+		{"name", cty.String},
+		{"size", cty.Number},
+		{"enabled", cty.Bool},
+		{"zones", cty.List(cty.String)},
+		{"ports", cty.Set(cty.Number)},
+		{"tags", cty.Map(cty.String)},
+		{"rule", cty.Object(map[string]cty.Type{"name": cty.String, "priority": cty.Number})},
+		{"pair", cty.Tuple([]cty.Type{cty.String, cty.Bool})},
+		{"value", cty.DynamicPseudoType},
+		{"untyped", cty.DynamicPseudoType},
+		{"invalid", cty.DynamicPseudoType},
+		{"identity.identity_ids", cty.Set(cty.String)},
+		{"nonexistent", cty.DynamicPseudoType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.attribute, func(t *testing.T) {
+			if got := schema.GetAttributeType("test_resource", tt.attribute); !got.Equals(tt.expected) {
+				t.Errorf("GetAttributeType(%s) = %#v, want %#v", tt.attribute, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAttributeSchema_DefaultValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		attr     *AttributeSchema
+		expected cty.Value
+	}{
+		{"string", &AttributeSchema{Type: "string", Default: "StorageV2"}, cty.StringVal("StorageV2")},
+		{"number", &AttributeSchema{Type: "number", Default: float64(7)}, cty.NumberIntVal(7)},
+		{"bool", &AttributeSchema{Type: "bool", Default: true}, cty.True},
+		{"list", &AttributeSchema{Type: []interface{}{"list", "string"}, Default: []interface{}{"1"}}, cty.ListVal([]cty.Value{cty.StringVal("1")})},
+		{"untyped", &AttributeSchema{Default: "Hot"}, cty.StringVal("Hot")},
+		{"no default", &AttributeSchema{Type: "string"}, cty.NilVal},
+		{"mismatched type", &AttributeSchema{Type: "bool", Default: "yes"}, cty.NilVal},
+		{"nil attribute", nil, cty.NilVal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.attr.DefaultValue()
+			if ok != (tt.expected != cty.NilVal) {
+				t.Fatalf("DefaultValue() ok = %v, want %v", ok, !ok)
+			}
+			if ok && !got.RawEquals(tt.expected) {
+				t.Errorf("DefaultValue() = %#v, want %#v", got, tt.expected)
+			}
+		})
+	}
+
+	schema := Load()
+	if def, ok := schema.GetAttribute("azurerm_storage_account", "account_kind").DefaultValue(); !ok || def.AsString() != "StorageV2" {
+		t.Errorf("Expected the embedded schema to default account_kind to StorageV2, got %#v", def)
+	}
+}
+
 func TestLoadFromJSON(t *testing.T) {
 	jsonData := []byte(`{
 		"resource_schemas": {
@@ -297,3 +501,100 @@ func TestSchema_IsForceNew_NilNestedBlock(t *testing.T) {
 		t.Error("Expected false when nested block has nil Block field")
 	}
 }
+
+func TestSchema_GetDeprecatedAttributes(t *testing.T) {
+	jsonData := []byte(`{
+		"resource_schemas": {
+			"test_resource": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string"},
+						"old_flag": {"type": "bool", "deprecated": true, "deprecation_message": "use ` + "`new_flag`" + ` instead"}
+					},
+					"block_types": {
+						"nested": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"legacy": {"type": "string", "deprecated": true}
+								}
+							}
+						}
+					}
+				}
+			},
+			"test_legacy_resource": {
+				"block": {
+					"deprecated": true,
+					"deprecation_message": "superseded by ` + "`test_resource`" + `"
+				}
+			}
+		}
+	}`)
+
+	schema, err := LoadFromJSON(jsonData)
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	attrs := schema.GetDeprecatedAttributes("test_resource")
+	if len(attrs) != 2 {
+		t.Fatalf("Expected 2 deprecated attributes, got %d: %v", len(attrs), attrs)
+	}
+	if attrs["old_flag"] != "use `new_flag` instead" {
+		t.Errorf("Unexpected message for old_flag: %q", attrs["old_flag"])
+	}
+	if msg, ok := attrs["nested.legacy"]; !ok || msg != "" {
+		t.Errorf("Expected nested.legacy to be deprecated without a message, got %q, %v", msg, ok)
+	}
+
+	if msg, ok := schema.GetResourceDeprecation("test_legacy_resource"); !ok || msg != "superseded by `test_resource`" {
+		t.Errorf("Unexpected resource deprecation message: %q, %v", msg, ok)
+	}
+	if msg, ok := schema.GetResourceDeprecation("test_resource"); ok {
+		t.Errorf("Expected no resource deprecation, got %q", msg)
+	}
+	if msg, ok := schema.GetResourceDeprecation("nonexistent_resource"); ok {
+		t.Errorf("Expected no resource deprecation for nonexistent resource, got %q", msg)
+	}
+}
+
+func TestSchema_GetAttributePaths(t *testing.T) {
+	jsonData := []byte(`{
+		"resource_schemas": {
+			"test_resource": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "force_new": true},
+						"tags": {"type": ["map", "string"]}
+					},
+					"block_types": {
+						"identity": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"type": {"type": "string"}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`)
+
+	schema, err := LoadFromJSON(jsonData)
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	paths := schema.GetAttributePaths("test_resource")
+	sort.Strings(paths)
+	if got, want := strings.Join(paths, ","), "identity.type,name,tags"; got != want {
+		t.Errorf("GetAttributePaths() = %q, want %q", got, want)
+	}
+
+	if paths := schema.GetAttributePaths("nonexistent_resource"); paths != nil {
+		t.Errorf("Expected nil for nonexistent resource, got %v", paths)
+	}
+}
@@ -0,0 +1,931 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// maxResolveDepth limits how many calls and variables are followed to resolve an expression.
+const maxResolveDepth = 8
+
+// externalForceNewHelpers lists schema helpers outside the provider source
+// (go-azure-helpers) that return ForceNew schemas but do not say so in their name.
+var externalForceNewHelpers = map[string]bool{
+	"ResourceGroupName": true,
+	"Location":          true,
+	"LocationOptional":  true,
+}
+
+// analyzer statically analyzes the Go source of terraform-provider-azurerm.
+// It parses the source without type checking, so calls and identifiers are resolved
+// by package directory and name.
+type analyzer struct {
+	modulePath string
+	files      []*sourceFile
+	// funcs indexes top-level functions by package directory and name.
+	funcs map[string]*funcRef
+	// methods indexes methods by package directory, receiver type and name.
+	methods map[string]*funcRef
+	// values indexes package-level variables and constants by package directory and name.
+	values map[string]*valueRef
+}
+
+// sourceFile is a parsed Go file of the provider.
+type sourceFile struct {
+	dir  string
+	file *ast.File
+	// imports maps import names to the package directory of provider imports,
+	// or to an empty string for external imports.
+	imports map[string]string
+}
+
+// funcRef is a function declaration and the file it is declared in.
+type funcRef struct {
+	decl *ast.FuncDecl
+	file *sourceFile
+}
+
+// valueRef is a package-level variable or constant value.
+type valueRef struct {
+	expr ast.Expr
+	file *sourceFile
+}
+
+// scope is where an expression appears, used to resolve identifiers and calls.
+type scope struct {
+	file *sourceFile
+	fn   *ast.FuncDecl
+}
+
+// loadProvider parses the non-test Go files under the internal directory of a
+// terraform-provider-azurerm checkout.
+func loadProvider(root string) (*analyzer, error) {
+	modulePath, err := readModulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &analyzer{
+		modulePath: modulePath,
+		funcs:      make(map[string]*funcRef),
+		methods:    make(map[string]*funcRef),
+		values:     make(map[string]*valueRef),
+	}
+	fset := token.NewFileSet()
+	err = filepath.WalkDir(filepath.Join(root, "internal"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		dir, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		a.addFile(filepath.ToSlash(dir), file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// readModulePath reads the module path from a go.mod file.
+func readModulePath(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read go.mod: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("no module directive in %s", path)
+}
+
+// addFile indexes the declarations of a parsed file.
+func (a *analyzer) addFile(dir string, file *ast.File) {
+	sf := &sourceFile{dir: dir, file: file, imports: make(map[string]string)}
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		sf.imports[name] = ""
+		if rel, ok := strings.CutPrefix(importPath, a.modulePath+"/"); ok {
+			sf.imports[name] = rel
+		}
+	}
+	a.files = append(a.files, sf)
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				a.funcs[dir+"."+d.Name.Name] = &funcRef{decl: d, file: sf}
+			} else if typeName := receiverType(d); typeName != "" {
+				a.methods[dir+"."+typeName+"."+d.Name.Name] = &funcRef{decl: d, file: sf}
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						a.values[dir+"."+name.Name] = &valueRef{expr: vs.Values[i], file: sf}
+					}
+				}
+			}
+		}
+	}
+}
+
+// receiverType returns the type name of a method receiver, without a pointer.
+func receiverType(decl *ast.FuncDecl) string {
+	if len(decl.Recv.List) == 0 {
+		return ""
+	}
+	expr := decl.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// attributeFacts are the facts about an attribute found in the provider source.
+type attributeFacts struct {
+	ForceNew bool
+	// Default is the static default value, or nil if there is none.
+	Default interface{}
+	// Deprecation is the static deprecation message, or empty if there is none.
+	Deprecation string
+}
+
+// attributeSet holds the facts of the attributes of a resource by path.
+type attributeSet map[string]*attributeFacts
+
+// resourceFacts are the facts about a resource type found in the provider source.
+type resourceFacts struct {
+	attrs attributeSet
+	// deprecation is the static deprecation message of the resource type, or empty.
+	deprecation string
+}
+
+// get returns the facts of the attribute at path, adding it if needed.
+func (s attributeSet) get(path string) *attributeFacts {
+	f, ok := s[path]
+	if !ok {
+		f = &attributeFacts{}
+		s[path] = f
+	}
+	return f
+}
+
+// ForceNewAttributes returns the ForceNew attribute paths of every resource type found
+// in the provider source, e.g. "azurerm_storage_account" -> ["location", "name"].
+func (a *analyzer) ForceNewAttributes() map[string][]string {
+	result := make(map[string][]string)
+	for resourceType, resource := range a.resources() {
+		var paths []string
+		for path, facts := range resource.attrs {
+			if facts.ForceNew {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+		result[resourceType] = paths
+	}
+	return result
+}
+
+// Defaults returns the static default values of attributes of every resource type found
+// in the provider source, by resource type and attribute path. Resource types without
+// defaults are omitted.
+func (a *analyzer) Defaults() map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	for resourceType, resource := range a.resources() {
+		for path, facts := range resource.attrs {
+			if facts.Default == nil {
+				continue
+			}
+			if result[resourceType] == nil {
+				result[resourceType] = make(map[string]interface{})
+			}
+			result[resourceType][path] = facts.Default
+		}
+	}
+	return result
+}
+
+// DeprecationMessages returns the static deprecation messages of resource types and
+// attributes found in the provider source, by resource type and attribute path. The
+// message of a resource type itself has an empty path. Resource types without
+// deprecation messages are omitted. `terraform providers schema -json` only tells
+// whether something is deprecated; the messages name the replacements.
+func (a *analyzer) DeprecationMessages() map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for resourceType, resource := range a.resources() {
+		messages := make(map[string]string)
+		if resource.deprecation != "" {
+			messages[""] = resource.deprecation
+		}
+		for path, facts := range resource.attrs {
+			if facts.Deprecation != "" {
+				messages[path] = facts.Deprecation
+			}
+		}
+		if len(messages) > 0 {
+			result[resourceType] = messages
+		}
+	}
+	return result
+}
+
+// resources returns the facts of every resource type found in the provider source.
+func (a *analyzer) resources() map[string]*resourceFacts {
+	result := make(map[string]*resourceFacts)
+	for _, sf := range a.files {
+		for _, decl := range sf.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			switch {
+			case fn.Name.Name == "SupportedResources":
+				// Plugin SDK resources, registered as map[string]*pluginsdk.Resource
+				for resourceType, expr := range registeredResources(fn) {
+					resource := &resourceFacts{attrs: make(attributeSet)}
+					a.resourceAttributes(expr, scope{file: sf, fn: fn}, resource, 0)
+					result[resourceType] = resource
+				}
+			case fn.Recv != nil && (fn.Name.Name == "ResourceType" || fn.Name.Name == "Metadata"):
+				// Typed and framework resources, which declare their type in a method
+				resourceType := a.declaredResourceType(fn, sf)
+				typeName := receiverType(fn)
+				if resourceType == "" || a.methods[sf.dir+"."+typeName+".Create"] == nil {
+					continue
+				}
+				resource := &resourceFacts{attrs: make(attributeSet)}
+				a.typedResourceAttributes(sf.dir, typeName, resource)
+				result[resourceType] = resource
+			}
+		}
+	}
+	return result
+}
+
+// ForceNewConditions returns the conditional ForceNew attributes of the plugin SDK resources
+// found in the provider source, by resource type and attribute path. They are declared with
+// pluginsdk.ForceNewIfChange in CustomizeDiff; only predicates that compare the old and new
+// value, such as `return new.(int) < old.(int)`, are recognized. The "type.path" of the
+// attributes with other predicates are returned as skipped, sorted, so that they can be
+// curated in the overlay.
+func (a *analyzer) ForceNewConditions() (map[string]map[string][]schema.ForceNewCondition, []string) {
+	result := make(map[string]map[string][]schema.ForceNewCondition)
+	var skipped []string
+	for _, sf := range a.files {
+		for _, decl := range sf.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Name.Name != "SupportedResources" {
+				continue
+			}
+			for resourceType, expr := range registeredResources(fn) {
+				conditions, skippedPaths := a.resourceConditions(expr, scope{file: sf, fn: fn})
+				if len(conditions) > 0 {
+					result[resourceType] = conditions
+				}
+				for _, path := range skippedPaths {
+					skipped = append(skipped, resourceType+"."+path)
+				}
+			}
+		}
+	}
+	sort.Strings(skipped)
+	return result, skipped
+}
+
+// resourceConditions collects the ForceNewIfChange conditions of a plugin SDK resource
+// expression, and the attribute paths whose predicates are not recognized.
+func (a *analyzer) resourceConditions(expr ast.Expr, sc scope) (map[string][]schema.ForceNewCondition, []string) {
+	expr, sc = a.resolve(expr, sc, 0)
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, nil
+	}
+	customizeDiff := fieldValue(lit, "CustomizeDiff")
+	if customizeDiff == nil {
+		return nil, nil
+	}
+
+	conditions := make(map[string][]schema.ForceNewCondition)
+	var skipped []string
+	ast.Inspect(customizeDiff, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || callName(call) != "ForceNewIfChange" || len(call.Args) != 2 {
+			return true
+		}
+		key := a.stringValue(call.Args[0], sc.file)
+		if key == "" {
+			return true
+		}
+		path := conditionPath(key)
+		if change, ok := a.predicateChange(call.Args[1], sc); ok {
+			conditions[path] = append(conditions[path], schema.ForceNewCondition{Change: change})
+		} else {
+			skipped = append(skipped, path)
+		}
+		return true
+	})
+	return conditions, skipped
+}
+
+// conditionPath converts a ForceNewIfChange key to an attribute path. Keys address list
+// elements, e.g. "sku.0.tier" is the attribute path "sku.tier" and "sku.0" the block "sku".
+func conditionPath(key string) string {
+	parts := strings.Split(key, ".")
+	path := parts[:0]
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err != nil {
+			path = append(path, part)
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// predicateChange classifies a ForceNewIfChange predicate that compares the new value
+// to the old one as a decrease or increase. The comparison may follow statements that
+// name the values, such as `o, n := old.(int), new.(int)`. Returns false for any other
+// predicate.
+func (a *analyzer) predicateChange(expr ast.Expr, sc scope) (schema.ForceNewChange, bool) {
+	var fnType *ast.FuncType
+	var body *ast.BlockStmt
+	switch e := expr.(type) {
+	case *ast.FuncLit:
+		fnType, body = e.Type, e.Body
+	case *ast.Ident, *ast.SelectorExpr:
+		ref := a.calledFunc(&ast.CallExpr{Fun: e}, sc)
+		if ref == nil || ref.decl.Body == nil {
+			return "", false
+		}
+		fnType, body = ref.decl.Type, ref.decl.Body
+	default:
+		return "", false
+	}
+
+	// The predicate is func(ctx context.Context, old, new, meta interface{}) bool
+	var params []string
+	for _, field := range fnType.Params.List {
+		for _, name := range field.Names {
+			params = append(params, name.Name)
+		}
+	}
+	if len(params) != 4 || len(body.List) == 0 {
+		return "", false
+	}
+
+	// Statements before the return may only name the old and new value, e.g. `o := old.(int)`
+	aliases := map[string]string{params[1]: params[1], params[2]: params[2]}
+	for _, stmt := range body.List[:len(body.List)-1] {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != len(assign.Rhs) {
+			return "", false
+		}
+		for i, lhs := range assign.Lhs {
+			name, ok := aliases[identName(unwrapValue(assign.Rhs[i]))]
+			if !ok || identName(lhs) == "" {
+				return "", false
+			}
+			aliases[identName(lhs)] = name
+		}
+	}
+	ret, ok := body.List[len(body.List)-1].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", false
+	}
+	cmp, ok := unwrapValue(ret.Results[0]).(*ast.BinaryExpr)
+	if !ok {
+		return "", false
+	}
+
+	left, right := aliases[identName(unwrapValue(cmp.X))], aliases[identName(unwrapValue(cmp.Y))]
+	oldName, newName := params[1], params[2]
+	var newIsLess bool
+	switch {
+	case left == newName && right == oldName:
+		newIsLess = cmp.Op == token.LSS || cmp.Op == token.LEQ
+		if !newIsLess && cmp.Op != token.GTR && cmp.Op != token.GEQ {
+			return "", false
+		}
+	case left == oldName && right == newName:
+		newIsLess = cmp.Op == token.GTR || cmp.Op == token.GEQ
+		if !newIsLess && cmp.Op != token.LSS && cmp.Op != token.LEQ {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+	if newIsLess {
+		return schema.ForceNewDecrease, true
+	}
+	return schema.ForceNewIncrease, true
+}
+
+// unwrapValue strips parentheses, type assertions and conversions, e.g. int(new.(int)) becomes new.
+func unwrapValue(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.TypeAssertExpr:
+			expr = e.X
+		case *ast.CallExpr:
+			if len(e.Args) != 1 {
+				return expr
+			}
+			expr = e.Args[0]
+		default:
+			return expr
+		}
+	}
+}
+
+// identName returns the name of an identifier, or an empty string.
+func identName(expr ast.Expr) string {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// callName returns the name of the called function, without its package.
+func callName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+// registeredResources returns the resource expressions registered in a
+// SupportedResources function, by resource type.
+func registeredResources(fn *ast.FuncDecl) map[string]ast.Expr {
+	resources := make(map[string]ast.Expr)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		if key := stringLit(kv.Key); strings.HasPrefix(key, "azurerm_") {
+			resources[key] = kv.Value
+		}
+		return true
+	})
+	return resources
+}
+
+// declaredResourceType returns the resource type returned by a ResourceType method,
+// or assigned to resp.TypeName in a Metadata method.
+func (a *analyzer) declaredResourceType(fn *ast.FuncDecl, sf *sourceFile) string {
+	var resourceType string
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.ReturnStmt:
+			if fn.Name.Name == "ResourceType" && len(s.Results) == 1 {
+				resourceType = a.stringValue(s.Results[0], sf)
+			}
+		case *ast.AssignStmt:
+			if sel, ok := s.Lhs[0].(*ast.SelectorExpr); ok && sel.Sel.Name == "TypeName" && len(s.Rhs) == 1 {
+				resourceType = a.stringValue(s.Rhs[0], sf)
+			}
+		}
+		return true
+	})
+	if !strings.HasPrefix(resourceType, "azurerm_") {
+		return ""
+	}
+	return resourceType
+}
+
+// stringValue returns the value of a string literal or a package-level string constant.
+func (a *analyzer) stringValue(expr ast.Expr, sf *sourceFile) string {
+	if s := stringLit(expr); s != "" {
+		return s
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		if v := a.values[sf.dir+"."+ident.Name]; v != nil {
+			return stringLit(v.expr)
+		}
+	}
+	return ""
+}
+
+// typedResourceAttributes collects the attributes of a typed resource, declared by its
+// Arguments method (typed SDK) or its Schema method (plugin framework), and its deprecation
+// message, returned by its DeprecationMessage method (typed SDK) or set in its schema
+// (plugin framework).
+func (a *analyzer) typedResourceAttributes(dir, typeName string, resource *resourceFacts) {
+	if ref := a.methods[dir+"."+typeName+".Arguments"]; ref != nil {
+		if expr := returnedExpr(ref.decl); expr != nil {
+			a.schemaMap(expr, scope{file: ref.file, fn: ref.decl}, "", resource.attrs, 0)
+		}
+		if ref := a.methods[dir+"."+typeName+".DeprecationMessage"]; ref != nil {
+			if expr := returnedExpr(ref.decl); expr != nil {
+				resource.deprecation = a.messageValue(expr, scope{file: ref.file, fn: ref.decl}, 0)
+			}
+		}
+		return
+	}
+	if ref := a.methods[dir+"."+typeName+".Schema"]; ref != nil {
+		// The schema is assigned to resp.Schema as a literal with Attributes and Blocks
+		ast.Inspect(ref.decl.Body, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok || fieldValue(lit, "Attributes") == nil {
+				return true
+			}
+			sc := scope{file: ref.file, fn: ref.decl}
+			a.schemaEntryChildren(lit, sc, "", resource.attrs, 0)
+			resource.deprecation = a.messageValue(fieldValue(lit, "DeprecationMessage"), sc, 0)
+			return false
+		})
+	}
+}
+
+// resourceAttributes collects the attributes and the deprecation message of a plugin SDK
+// resource expression, such as a call to a function returning &pluginsdk.Resource{...}.
+func (a *analyzer) resourceAttributes(expr ast.Expr, sc scope, resource *resourceFacts, depth int) {
+	expr, sc = a.resolve(expr, sc, depth)
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	if schema := fieldValue(lit, "Schema"); schema != nil {
+		a.schemaMap(schema, sc, "", resource.attrs, depth)
+	}
+	resource.deprecation = a.messageValue(fieldValue(lit, "DeprecationMessage"), sc, depth)
+}
+
+// schemaMap collects the attributes of a map[string]*Schema (plugin SDK) or
+// map[string]schema.Attribute (plugin framework) expression under prefix.
+// attrs records every attribute path and whether it is ForceNew.
+func (a *analyzer) schemaMap(expr ast.Expr, sc scope, prefix string, attrs attributeSet, depth int) {
+	expr, sc = a.resolve(expr, sc, depth)
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		name := a.stringValue(kv.Key, sc.file)
+		if name == "" {
+			continue
+		}
+		a.schemaEntry(kv.Value, sc, prefix+name, attrs, depth)
+	}
+}
+
+// schemaEntry collects a single attribute or block at path.
+func (a *analyzer) schemaEntry(expr ast.Expr, sc scope, path string, attrs attributeSet, depth int) {
+	// Helpers from outside the provider source cannot be analyzed; rely on their name
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if name, external := a.externalCall(call, sc); external {
+			facts := attrs.get(path)
+			facts.ForceNew = facts.ForceNew || strings.Contains(name, "ForceNew") || externalForceNewHelpers[name]
+			return
+		}
+	}
+
+	expr, sc = a.resolve(expr, sc, depth)
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+
+	children := make(attributeSet)
+	a.schemaEntryChildren(lit, sc, path+".", children, depth)
+	forceNew := isTrue(fieldValue(lit, "ForceNew")) || requiresReplace(fieldValue(lit, "PlanModifiers"))
+	if len(children) == 0 {
+		facts := attrs.get(path)
+		facts.ForceNew = facts.ForceNew || forceNew
+		if def, ok := a.defaultValue(fieldValue(lit, "Default"), sc, depth); ok {
+			facts.Default = def
+		}
+		// Deprecated (plugin SDK) and DeprecationMessage (plugin framework) hold the message
+		for _, field := range []string{"Deprecated", "DeprecationMessage"} {
+			if message := a.messageValue(fieldValue(lit, field), sc, depth); message != "" {
+				facts.Deprecation = message
+			}
+		}
+		return
+	}
+	// A change anywhere in a ForceNew block recreates the resource
+	for child, childFacts := range children {
+		facts := attrs.get(child)
+		facts.ForceNew = facts.ForceNew || childFacts.ForceNew || forceNew
+		if childFacts.Default != nil {
+			facts.Default = childFacts.Default
+		}
+	}
+}
+
+// defaultValue returns the static value of a Default field: a literal or constant in the
+// plugin SDK, or a static default such as stringdefault.StaticString("x") in the plugin framework.
+// Returns false if the value cannot be determined statically.
+func (a *analyzer) defaultValue(expr ast.Expr, sc scope, depth int) (interface{}, bool) {
+	if expr == nil || depth >= maxResolveDepth {
+		return nil, false
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			s, err := strconv.Unquote(e.Value)
+			return s, err == nil
+		case token.INT:
+			n, err := strconv.ParseInt(e.Value, 0, 64)
+			return n, err == nil
+		case token.FLOAT:
+			f, err := strconv.ParseFloat(e.Value, 64)
+			return f, err == nil
+		}
+	case *ast.UnaryExpr:
+		if e.Op == token.SUB {
+			v, ok := a.defaultValue(e.X, sc, depth+1)
+			switch n := v.(type) {
+			case int64:
+				return -n, ok
+			case float64:
+				return -n, ok
+			}
+		}
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" {
+			return e.Name == "true", true
+		}
+		if v := a.values[sc.file.dir+"."+e.Name]; v != nil {
+			return a.defaultValue(v.expr, scope{file: v.file}, depth+1)
+		}
+	case *ast.CallExpr:
+		// Static framework defaults and conversions such as string(...) wrap the value
+		if name := callName(e); len(e.Args) == 1 && (strings.HasPrefix(name, "Static") || name == "string" || name == "int" || name == "float64") {
+			return a.defaultValue(e.Args[0], sc, depth+1)
+		}
+	}
+	return nil, false
+}
+
+// messageValue returns the static value of a message: a string literal, a string constant,
+// or a concatenation of them. Returns an empty string if it cannot be determined statically,
+// such as for messages built with fmt.Sprintf.
+func (a *analyzer) messageValue(expr ast.Expr, sc scope, depth int) string {
+	if expr == nil || depth >= maxResolveDepth {
+		return ""
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return stringLit(e)
+	case *ast.ParenExpr:
+		return a.messageValue(e.X, sc, depth+1)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return ""
+		}
+		left, right := a.messageValue(e.X, sc, depth+1), a.messageValue(e.Y, sc, depth+1)
+		if left == "" || right == "" {
+			return ""
+		}
+		return left + right
+	case *ast.Ident:
+		if v := a.values[sc.file.dir+"."+e.Name]; v != nil {
+			return a.messageValue(v.expr, scope{file: v.file}, depth+1)
+		}
+	}
+	return ""
+}
+
+// schemaEntryChildren collects the nested attributes of a block: Elem (plugin SDK),
+// or Attributes, Blocks and NestedObject (plugin framework).
+func (a *analyzer) schemaEntryChildren(lit *ast.CompositeLit, sc scope, prefix string, attrs attributeSet, depth int) {
+	if elem := fieldValue(lit, "Elem"); elem != nil {
+		elemExpr, elemScope := a.resolve(elem, sc, depth)
+		if elemLit, ok := elemExpr.(*ast.CompositeLit); ok {
+			if schema := fieldValue(elemLit, "Schema"); schema != nil {
+				a.schemaMap(schema, elemScope, prefix, attrs, depth+1)
+			}
+		}
+	}
+	for _, field := range []string{"Attributes", "Blocks"} {
+		if m := fieldValue(lit, field); m != nil {
+			a.schemaMap(m, sc, prefix, attrs, depth+1)
+		}
+	}
+	if nested := fieldValue(lit, "NestedObject"); nested != nil {
+		nestedExpr, nestedScope := a.resolve(nested, sc, depth)
+		if nestedLit, ok := nestedExpr.(*ast.CompositeLit); ok {
+			a.schemaEntryChildren(nestedLit, nestedScope, prefix, attrs, depth+1)
+		}
+	}
+}
+
+// externalCall returns the function name of a call to a package outside the provider source.
+func (a *analyzer) externalCall(call *ast.CallExpr, sc scope) (string, bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	dir, imported := sc.file.imports[pkg.Name]
+	return sel.Sel.Name, imported && dir == ""
+}
+
+// resolve follows pointers, calls to provider functions and variables to the expression
+// that defines a value. Expressions that cannot be followed are returned as is.
+func (a *analyzer) resolve(expr ast.Expr, sc scope, depth int) (ast.Expr, scope) {
+	for ; depth < maxResolveDepth; depth++ {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.UnaryExpr:
+			if e.Op != token.AND {
+				return expr, sc
+			}
+			expr = e.X
+		case *ast.CallExpr:
+			ref := a.calledFunc(e, sc)
+			if ref == nil {
+				return expr, sc
+			}
+			result := returnedExpr(ref.decl)
+			if result == nil {
+				return expr, sc
+			}
+			expr, sc = result, scope{file: ref.file, fn: ref.decl}
+		case *ast.Ident:
+			if local := localValue(sc.fn, e.Name); local != nil {
+				expr = local
+				continue
+			}
+			v := a.values[sc.file.dir+"."+e.Name]
+			if v == nil {
+				return expr, sc
+			}
+			expr, sc = v.expr, scope{file: v.file}
+		default:
+			return expr, sc
+		}
+	}
+	return expr, sc
+}
+
+// calledFunc returns the provider function called by a call expression.
+func (a *analyzer) calledFunc(call *ast.CallExpr, sc scope) *funcRef {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return a.funcs[sc.file.dir+"."+fun.Name]
+	case *ast.SelectorExpr:
+		pkg, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return nil
+		}
+		if dir := sc.file.imports[pkg.Name]; dir != "" {
+			return a.funcs[dir+"."+fun.Sel.Name]
+		}
+	}
+	return nil
+}
+
+// returnedExpr returns the expression returned by a function with a single result.
+// When the function has several return statements, the last one is used.
+func returnedExpr(fn *ast.FuncDecl) ast.Expr {
+	if fn.Body == nil {
+		return nil
+	}
+	var result ast.Expr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(s.Results) == 1 {
+				result = s.Results[0]
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// localValue returns the value first assigned to a local variable of a function.
+func localValue(fn *ast.FuncDecl, name string) ast.Expr {
+	if fn == nil || fn.Body == nil {
+		return nil
+	}
+	var value ast.Expr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if value != nil {
+			return false
+		}
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			if s.Tok != token.DEFINE || len(s.Lhs) != len(s.Rhs) {
+				return true
+			}
+			for i, lhs := range s.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok && ident.Name == name {
+					value = s.Rhs[i]
+				}
+			}
+		case *ast.ValueSpec:
+			for i, ident := range s.Names {
+				if ident.Name == name && i < len(s.Values) {
+					value = s.Values[i]
+				}
+			}
+		}
+		return true
+	})
+	return value
+}
+
+// fieldValue returns the value of a keyed field in a composite literal.
+func fieldValue(lit *ast.CompositeLit, name string) ast.Expr {
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == name {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// requiresReplace reports whether plan modifiers include a RequiresReplace modifier,
+// such as stringplanmodifier.RequiresReplace().
+func requiresReplace(modifiers ast.Expr) bool {
+	found := false
+	if modifiers == nil {
+		return false
+	}
+	ast.Inspect(modifiers, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if strings.HasPrefix(callName(call), "RequiresReplace") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isTrue reports whether an expression is the identifier true.
+func isTrue(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "true"
+}
+
+// stringLit returns the value of a string literal, or an empty string.
+func stringLit(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return s
+}
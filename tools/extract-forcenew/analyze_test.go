@@ -0,0 +1,518 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// providerFixture is a minimal provider source tree with a plugin SDK resource,
+// a typed resource and a plugin framework resource.
+var providerFixture = map[string]string{
+	"go.mod": "module github.com/hashicorp/terraform-provider-azurerm\n\ngo 1.22\n",
+
+	"internal/services/storage/registration.go": `package storage
+
+import "github.com/hashicorp/terraform-provider-azurerm/internal/tf/pluginsdk"
+
+type Registration struct{}
+
+func (r Registration) SupportedDataSources() map[string]*pluginsdk.Resource {
+	return map[string]*pluginsdk.Resource{
+		"azurerm_storage_account": dataSourceStorageAccount(),
+	}
+}
+
+func (r Registration) SupportedResources() map[string]*pluginsdk.Resource {
+	return map[string]*pluginsdk.Resource{
+		"azurerm_storage_account": resourceStorageAccount(),
+	}
+}
+`,
+
+	"internal/services/storage/storage_account_resource.go": `package storage
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonschema"
+	"github.com/hashicorp/terraform-provider-azurerm/internal/services/storage/helpers"
+	"github.com/hashicorp/terraform-provider-azurerm/internal/tf/pluginsdk"
+)
+
+const (
+	accountKindName    = "account_kind"
+	defaultAccountKind = "StorageV2"
+	httpsDeprecation   = "enable_https_traffic_only has been superseded by https_traffic_only_enabled"
+)
+
+func resourceStorageAccount() *pluginsdk.Resource {
+	resource := &pluginsdk.Resource{
+		Schema: map[string]*pluginsdk.Schema{
+			"name": {
+				Type:     pluginsdk.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"resource_group_name": commonschema.ResourceGroupName(),
+			"location":            commonschema.Location(),
+			"tags":                commonschema.Tags(),
+			"edge_zone":           commonschema.EdgeZoneOptionalForceNew(),
+			accountKindName: {
+				Type:     pluginsdk.TypeString,
+				Optional: true,
+				ForceNew: true,
+				Default:  defaultAccountKind,
+			},
+			"access_tier":                {Type: pluginsdk.TypeString, Optional: true, Default: string("Hot")},
+			"https_traffic_only_enabled": {Type: pluginsdk.TypeBool, Optional: true, Default: true},
+			"queue_retention_days":       {Type: pluginsdk.TypeInt, Optional: true, Default: 7},
+			"min_tls_version":            {Type: pluginsdk.TypeString, Optional: true, Default: string(storage.MinimumTLSVersionTLSOneTwo)},
+			"enable_https_traffic_only":  {Type: pluginsdk.TypeBool, Optional: true, Deprecated: httpsDeprecation + " and will be removed in v5.0."},
+			"allow_blob_public_access":   {Type: pluginsdk.TypeBool, Optional: true, Deprecated: fmt.Sprintf("%s is deprecated", "allow_blob_public_access")},
+			"account_tier":      helpers.AccountTierSchema(),
+			"network_rules":     networkRulesSchema(),
+			"customer_managed_key": {
+				Type:     pluginsdk.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &pluginsdk.Resource{
+					Schema: map[string]*pluginsdk.Schema{
+						"key_vault_key_id": {Type: pluginsdk.TypeString, Required: true},
+					},
+				},
+			},
+		},
+		CustomizeDiff: pluginsdk.CustomDiffWithAll(
+			pluginsdk.ForceNewIfChange("large_file_share_enabled", func(ctx context.Context, old, new, meta interface{}) bool {
+				return old.(bool) && !new.(bool)
+			}),
+			pluginsdk.ForceNewIfChange("share_properties.0.retention_days", func(ctx context.Context, old, new, meta interface{}) bool {
+				return old.(int) > new.(int)
+			}),
+			pluginsdk.ForceNewIfChange("min_tls_version", func(_ context.Context, before, after, _ interface{}) bool {
+				return (after.(int) >= before.(int))
+			}),
+			pluginsdk.ForceNewIfChange("max_size_gb", sizeDecreased),
+			pluginsdk.ForceNewIfChange("queue_retention_days", func(ctx context.Context, old, new, meta interface{}) bool {
+				before, after := old.(int), new.(int)
+				return after < before
+			}),
+			pluginsdk.ForceNewIfChange("customer_managed_key.0", func(ctx context.Context, old, new, meta interface{}) bool {
+				return len(old.([]interface{})) > 0 && len(new.([]interface{})) == 0
+			}),
+		),
+	}
+	return resource
+}
+
+func sizeDecreased(ctx context.Context, old, new, meta interface{}) bool {
+	return new.(int) < old.(int)
+}
+
+func networkRulesSchema() *pluginsdk.Schema {
+	return &pluginsdk.Schema{
+		Type:     pluginsdk.TypeList,
+		Optional: true,
+		Elem: &pluginsdk.Resource{
+			Schema: map[string]*pluginsdk.Schema{
+				"default_action": {Type: pluginsdk.TypeString, Optional: true, Default: "Allow"},
+				"bypass":         {Type: pluginsdk.TypeSet, Optional: true, ForceNew: true, Elem: &pluginsdk.Schema{Type: pluginsdk.TypeString}},
+			},
+		},
+	}
+}
+
+func dataSourceStorageAccount() *pluginsdk.Resource {
+	return &pluginsdk.Resource{
+		Schema: map[string]*pluginsdk.Schema{
+			"name": {Type: pluginsdk.TypeString, Required: true, ForceNew: true},
+		},
+	}
+}
+`,
+
+	"internal/services/storage/helpers/schema.go": `package helpers
+
+import "github.com/hashicorp/terraform-provider-azurerm/internal/tf/pluginsdk"
+
+func AccountTierSchema() *pluginsdk.Schema {
+	return &pluginsdk.Schema{
+		Type:     pluginsdk.TypeString,
+		Required: true,
+		ForceNew: true,
+	}
+}
+`,
+
+	"internal/services/network/subnet_resource.go": `package network
+
+import "github.com/hashicorp/terraform-provider-azurerm/internal/tf/pluginsdk"
+
+type SubnetResource struct{}
+
+type SubnetDataSource struct{}
+
+func (r SubnetResource) ResourceType() string { return "azurerm_subnet" }
+
+func (r SubnetResource) Arguments() map[string]*pluginsdk.Schema {
+	return map[string]*pluginsdk.Schema{
+		"name":                 {Type: pluginsdk.TypeString, Required: true, ForceNew: true},
+		"virtual_network_name": {Type: pluginsdk.TypeString, Required: true, ForceNew: true},
+		"address_prefixes":     {Type: pluginsdk.TypeList, Required: true},
+	}
+}
+
+func (r SubnetResource) Create() sdk.ResourceFunc { return sdk.ResourceFunc{} }
+
+func (r SubnetResource) DeprecationMessage() string {
+	return "azurerm_subnet is superseded by azurerm_virtual_network_subnet."
+}
+
+func (d SubnetDataSource) ResourceType() string { return "azurerm_subnet" }
+
+func (d SubnetDataSource) Arguments() map[string]*pluginsdk.Schema {
+	return map[string]*pluginsdk.Schema{
+		"address_prefixes": {Type: pluginsdk.TypeList, Required: true, ForceNew: true},
+	}
+}
+`,
+
+	"internal/services/network/framework_resource.go": `package network
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+)
+
+type RouteResource struct{}
+
+func (r *RouteResource) Metadata(_ context.Context, _ resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = "azurerm_route"
+}
+
+func (r *RouteResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"next_hop_type": schema.StringAttribute{Required: true},
+			"next_hop_ip":   schema.StringAttribute{Optional: true, DeprecationMessage: "Use next_hop_in_ip_address instead."},
+			"priority": schema.Int64Attribute{Optional: true, Computed: true, Default: int64default.StaticInt64(100)},
+		},
+		Blocks: map[string]schema.Block{
+			"timeouts": schema.SingleNestedBlock{
+				Attributes: map[string]schema.Attribute{
+					"create": schema.StringAttribute{
+						Optional:      true,
+						PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplaceIfConfigured()},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *RouteResource) Create(_ context.Context, _ resource.CreateRequest, _ *resource.CreateResponse) {}
+`,
+}
+
+func writeProviderFixture(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range providerFixture {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestForceNewAttributes(t *testing.T) {
+	a, err := loadProvider(writeProviderFixture(t))
+	if err != nil {
+		t.Fatalf("loadProvider failed: %v", err)
+	}
+
+	got := a.ForceNewAttributes()
+	want := map[string][]string{
+		"azurerm_storage_account": {
+			"account_kind",
+			"account_tier",
+			"customer_managed_key.key_vault_key_id",
+			"edge_zone",
+			"location",
+			"name",
+			"network_rules.bypass",
+			"resource_group_name",
+		},
+		"azurerm_subnet": {"name", "virtual_network_name"},
+		"azurerm_route":  {"name", "timeouts.create"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForceNewAttributes() = %v, want %v", got, want)
+	}
+}
+
+func TestForceNewConditions(t *testing.T) {
+	a, err := loadProvider(writeProviderFixture(t))
+	if err != nil {
+		t.Fatalf("loadProvider failed: %v", err)
+	}
+
+	got, skipped := a.ForceNewConditions()
+	want := map[string]map[string][]schema.ForceNewCondition{
+		"azurerm_storage_account": {
+			"share_properties.retention_days": {{Change: schema.ForceNewDecrease}},
+			"min_tls_version":                 {{Change: schema.ForceNewIncrease}},
+			"max_size_gb":                     {{Change: schema.ForceNewDecrease}},
+			"queue_retention_days":            {{Change: schema.ForceNewDecrease}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ForceNewConditions() = %v, want %v", got, want)
+	}
+
+	// Predicates that do not compare the old and new value are reported for the overlay
+	wantSkipped := []string{"azurerm_storage_account.customer_managed_key", "azurerm_storage_account.large_file_share_enabled"}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Errorf("skipped = %v, want %v", skipped, wantSkipped)
+	}
+}
+
+func TestConditionPath(t *testing.T) {
+	for key, want := range map[string]string{
+		"max_size_gb":                  "max_size_gb",
+		"sku.0.tier":                   "sku.tier",
+		"sku.0":                        "sku",
+		"windows_profile.0.gmsa.0.dns": "windows_profile.gmsa.dns",
+	} {
+		if got := conditionPath(key); got != want {
+			t.Errorf("conditionPath(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestMergeForceNewConditions(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_storage_account": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true, "force_new": true},
+						"max_size_gb": {"type": "number", "optional": true}
+					},
+					"block_types": {
+						"share_properties": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"retention_days": {"type": "number", "optional": true}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	decrease := []schema.ForceNewCondition{{Change: schema.ForceNewDecrease}}
+	unmatched := mergeForceNewConditions(s, map[string]map[string][]schema.ForceNewCondition{
+		"azurerm_storage_account": {
+			"name":                            decrease,
+			"max_size_gb":                     decrease,
+			"share_properties.retention_days": decrease,
+			"removed_attribute":               decrease,
+		},
+	})
+
+	if got := s.GetForceNewConditions("azurerm_storage_account", "name"); len(got) != 0 {
+		t.Errorf("Expected name to stay unconditional, got %v", got)
+	}
+	for _, path := range []string{"max_size_gb", "share_properties.retention_days"} {
+		if got := s.GetForceNewConditions("azurerm_storage_account", path); !reflect.DeepEqual(got, decrease) {
+			t.Errorf("conditions of %s = %v, want %v", path, got, decrease)
+		}
+	}
+	if want := []string{"azurerm_storage_account.removed_attribute"}; !reflect.DeepEqual(unmatched, want) {
+		t.Errorf("unmatched = %v, want %v", unmatched, want)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	a, err := loadProvider(writeProviderFixture(t))
+	if err != nil {
+		t.Fatalf("loadProvider failed: %v", err)
+	}
+
+	got := a.Defaults()
+	want := map[string]map[string]interface{}{
+		"azurerm_storage_account": {
+			"account_kind":                 "StorageV2",
+			"access_tier":                  "Hot",
+			"https_traffic_only_enabled":   true,
+			"queue_retention_days":         int64(7),
+			"network_rules.default_action": "Allow",
+		},
+		"azurerm_route": {"priority": int64(100)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Defaults() = %v, want %v", got, want)
+	}
+}
+
+func TestDeprecationMessages(t *testing.T) {
+	a, err := loadProvider(writeProviderFixture(t))
+	if err != nil {
+		t.Fatalf("loadProvider failed: %v", err)
+	}
+
+	got := a.DeprecationMessages()
+	want := map[string]map[string]string{
+		"azurerm_storage_account": {
+			"enable_https_traffic_only": "enable_https_traffic_only has been superseded by https_traffic_only_enabled and will be removed in v5.0.",
+		},
+		"azurerm_subnet": {"": "azurerm_subnet is superseded by azurerm_virtual_network_subnet."},
+		"azurerm_route":  {"next_hop_ip": "Use next_hop_in_ip_address instead."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DeprecationMessages() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeDeprecationMessages(t *testing.T) {
+	// Deprecation is a bool in `terraform providers schema -json`
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_storage_account": {
+				"block": {
+					"attributes": {
+						"enable_https_traffic_only": {"type": "bool", "optional": true, "deprecated": true}
+					}
+				}
+			},
+			"azurerm_subnet": {"block": {"deprecated": true}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	unmatched := mergeDeprecationMessages(s, map[string]map[string]string{
+		"azurerm_storage_account": {"enable_https_traffic_only": "Use https_traffic_only_enabled.", "removed_attribute": "Gone."},
+		"azurerm_subnet":          {"": "Use azurerm_virtual_network_subnet."},
+	})
+
+	if got := s.GetDeprecatedAttributes("azurerm_storage_account"); !reflect.DeepEqual(got, map[string]string{"enable_https_traffic_only": "Use https_traffic_only_enabled."}) {
+		t.Errorf("GetDeprecatedAttributes() = %v", got)
+	}
+	if msg, ok := s.GetResourceDeprecation("azurerm_subnet"); !ok || msg != "Use azurerm_virtual_network_subnet." {
+		t.Errorf("GetResourceDeprecation() = %q, %v", msg, ok)
+	}
+	if want := []string{"azurerm_storage_account.removed_attribute"}; !reflect.DeepEqual(unmatched, want) {
+		t.Errorf("unmatched = %v, want %v", unmatched, want)
+	}
+}
+
+func TestMergeDefaults(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_storage_account": {
+				"block": {
+					"attributes": {
+						"account_kind": {"type": "string", "optional": true}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	unmatched := mergeDefaults(s, map[string]map[string]interface{}{
+		"azurerm_storage_account": {"account_kind": "StorageV2", "removed_attribute": true},
+	})
+
+	if def, ok := s.GetAttribute("azurerm_storage_account", "account_kind").DefaultValue(); !ok || def.AsString() != "StorageV2" {
+		t.Errorf("Expected the account_kind default to be merged, got %#v", def)
+	}
+	if want := []string{"azurerm_storage_account.removed_attribute"}; !reflect.DeepEqual(unmatched, want) {
+		t.Errorf("unmatched = %v, want %v", unmatched, want)
+	}
+}
+
+func TestMergeForceNew(t *testing.T) {
+	s, err := schema.LoadFromJSON([]byte(`{
+		"resource_schemas": {
+			"azurerm_storage_account": {
+				"block": {
+					"attributes": {
+						"name": {"type": "string", "required": true},
+						"tags": {"type": ["map", "string"], "optional": true}
+					},
+					"block_types": {
+						"customer_managed_key": {
+							"nesting_mode": "list",
+							"block": {
+								"attributes": {
+									"key_vault_key_id": {"type": "string", "required": true}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadFromJSON failed: %v", err)
+	}
+
+	unmatched := mergeForceNew(s, map[string][]string{
+		"azurerm_storage_account": {"customer_managed_key", "name", "removed_attribute"},
+		"azurerm_unknown":         {"name"},
+	})
+
+	if got, want := s.GetForceNewAttributes("azurerm_storage_account"), []string{"customer_managed_key.key_vault_key_id", "name"}; !sameElements(got, want) {
+		t.Errorf("ForceNew attributes = %v, want %v", got, want)
+	}
+	if want := []string{"azurerm_storage_account.removed_attribute", "azurerm_unknown.name"}; !reflect.DeepEqual(unmatched, want) {
+		t.Errorf("unmatched = %v, want %v", unmatched, want)
+	}
+}
+
+// sameElements reports whether two string slices hold the same elements in any order.
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		seen[s]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
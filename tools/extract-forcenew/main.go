@@ -0,0 +1,272 @@
+// Package main provides a tool to derive ForceNew metadata from the azurerm provider source.
+// `terraform providers schema -json` does not include whether an attribute forces a new
+// resource, so this tool statically analyzes a checkout of terraform-provider-azurerm for
+// `ForceNew: true` in plugin SDK schemas and `RequiresReplace` plan modifiers in plugin
+// framework schemas, for conditional ForceNew declared with `ForceNewIfChange` in
+// CustomizeDiff, and for static attribute defaults, and merges the result into the
+// provider schema.
+//
+// Usage:
+//
+//	git clone --depth 1 --branch v4.0.0 https://github.com/hashicorp/terraform-provider-azurerm /tmp/azurerm
+//	terraform providers schema -json > providers.json
+//	go run ./tools/extract-forcenew -provider-source /tmp/azurerm -schema providers.json -provider-version 4.0.0 -output schema/azurerm.json.gz
+//
+// Check out the provider source at the same version as the provider installed for
+// `terraform providers schema`, so that the attributes of both match, and record that
+// version with -provider-version. Deprecation messages are copied verbatim from the source,
+// so they may name provider versions older than the one they were extracted from. Entries of the
+// curated overlay (schema/overlay.json) that no longer match the extracted schema are
+// reported as warnings.
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+func main() {
+	source := flag.String("provider-source", "", "Path to a terraform-provider-azurerm checkout")
+	schemaPath := flag.String("schema", "", "Path to the output of `terraform providers schema -json`")
+	output := flag.String("output", "azurerm.json.gz", "Output file path (will be gzip compressed)")
+	providerKey := flag.String("provider", "registry.terraform.io/hashicorp/azurerm", "Provider key in the schema output")
+	providerVersion := flag.String("provider-version", "", "Version of the provider source and schema, recorded in the output")
+	verbose := flag.Bool("v", false, "List attributes found in the source that are not in the provider schema")
+	flag.Parse()
+
+	if *source == "" || *schemaPath == "" {
+		fmt.Fprintln(os.Stderr, "Both -provider-source and -schema are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Parse the provider schema
+	data, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading schema: %v\n", err)
+		os.Exit(1)
+	}
+	providerSchema, err := parseProviderSchema(data, *providerKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Analyze the provider source
+	fmt.Printf("Analyzing %s...\n", *source)
+	a, err := loadProvider(*source)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error analyzing provider source: %v\n", err)
+		os.Exit(1)
+	}
+	forceNew := a.ForceNewAttributes()
+
+	unmatched := mergeForceNew(providerSchema, forceNew)
+	conditions, skipped := a.ForceNewConditions()
+	unmatched = append(unmatched, mergeForceNewConditions(providerSchema, conditions)...)
+	unmatched = append(unmatched, mergeDefaults(providerSchema, a.Defaults())...)
+	unmatched = append(unmatched, mergeDeprecationMessages(providerSchema, a.DeprecationMessages())...)
+	sort.Strings(unmatched)
+	if *verbose {
+		for _, path := range unmatched {
+			fmt.Fprintf(os.Stderr, "warning: %s is not in the provider schema\n", path)
+		}
+	}
+
+	// Conditional ForceNew that is not recognized is neither ForceNew nor conditional in the output
+	for _, path := range skipped {
+		fmt.Fprintf(os.Stderr, "warning: ForceNewIfChange predicate of %s is not recognized; curate it in the overlay if needed\n", path)
+	}
+
+	outputSchema := schema.Schema{ProviderVersion: *providerVersion, ResourceSchemas: providerSchema.ResourceSchemas}
+
+	// The overlay is applied at load time; warn about entries the new schema makes stale
+	overlay, err := schema.LoadOverlay()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading schema overlay: %v\n", err)
+		os.Exit(1)
+	}
+	for _, warning := range outputSchema.StaleOverlayEntries(overlay) {
+		fmt.Fprintf(os.Stderr, "warning: overlay entry %s\n", warning)
+	}
+	jsonData, err := json.Marshal(outputSchema)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling schema: %v\n", err)
+		os.Exit(1)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	gzw := gzip.NewWriter(f)
+	defer gzw.Close()
+
+	if _, err := gzw.Write(jsonData); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing compressed data: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Print stats
+	forceNewCount, conditionalCount := 0, 0
+	for _, resourceType := range outputSchema.GetResourceTypes() {
+		for _, path := range outputSchema.GetForceNewAttributes(resourceType) {
+			forceNewCount++
+			if len(outputSchema.GetForceNewConditions(resourceType, path)) > 0 {
+				conditionalCount++
+			}
+		}
+	}
+	fmt.Printf("Found %d resources in the provider source, %d in the provider schema\n", len(forceNew), len(outputSchema.ResourceSchemas))
+	fmt.Printf("Extracted schema with %d ForceNew attributes, %d of them conditional (%d not in the provider schema, %d unrecognized predicates)\n", forceNewCount, conditionalCount, len(unmatched), len(skipped))
+	fmt.Printf("Written to %s\n", *output)
+}
+
+// mergeForceNew marks the ForceNew attributes found in the provider source in the schema.
+// A ForceNew block marks all of its attributes. Returns the "type.path" of attributes that
+// are not in the schema, sorted.
+func mergeForceNew(s *schema.Schema, forceNew map[string][]string) []string {
+	var unmatched []string
+	for resourceType, paths := range forceNew {
+		rs, ok := s.ResourceSchemas[resourceType]
+		for _, path := range paths {
+			if !ok || rs.Block == nil || !markForceNew(rs.Block, path) {
+				unmatched = append(unmatched, resourceType+"."+path)
+			}
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// markForceNew marks the attribute, or all attributes of the block, at path as ForceNew.
+// Returns false if the path is not in the block.
+func markForceNew(block *schema.BlockSchema, path string) bool {
+	name, rest, nested := strings.Cut(path, ".")
+	if attr, ok := block.Attributes[name]; ok && !nested {
+		attr.ForceNew = true
+		return true
+	}
+	bt, ok := block.BlockTypes[name]
+	if !ok || bt.Block == nil {
+		return false
+	}
+	if nested {
+		return markForceNew(bt.Block, rest)
+	}
+	markAllForceNew(bt.Block)
+	return true
+}
+
+// markAllForceNew marks every attribute of a block and its nested blocks as ForceNew.
+func markAllForceNew(block *schema.BlockSchema) {
+	for _, attr := range block.Attributes {
+		attr.ForceNew = true
+	}
+	for _, bt := range block.BlockTypes {
+		if bt.Block != nil {
+			markAllForceNew(bt.Block)
+		}
+	}
+}
+
+// mergeForceNewConditions attaches the conditions found in the provider source to the
+// attributes of the schema. Attributes that are always ForceNew are left unconditional.
+// Returns the "type.path" of attributes that are not in the schema, sorted.
+func mergeForceNewConditions(s *schema.Schema, conditions map[string]map[string][]schema.ForceNewCondition) []string {
+	var unmatched []string
+	for resourceType, byPath := range conditions {
+		rs, ok := s.ResourceSchemas[resourceType]
+		for path, pathConditions := range byPath {
+			var attr *schema.AttributeSchema
+			if ok && rs.Block != nil {
+				attr = attributeAt(rs.Block, path)
+			}
+			if attr == nil {
+				unmatched = append(unmatched, resourceType+"."+path)
+				continue
+			}
+			if !attr.ForceNew {
+				attr.ForceNewIf = pathConditions
+			}
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// attributeAt returns the attribute at a dot-separated path in a block, or nil.
+func attributeAt(block *schema.BlockSchema, path string) *schema.AttributeSchema {
+	name, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		return block.Attributes[name]
+	}
+	bt, ok := block.BlockTypes[name]
+	if !ok || bt.Block == nil {
+		return nil
+	}
+	return attributeAt(bt.Block, rest)
+}
+
+// mergeDefaults sets the provider defaults found in the provider source on the attributes
+// of the schema. Returns the "type.path" of attributes that are not in the schema, sorted.
+func mergeDefaults(s *schema.Schema, defaults map[string]map[string]interface{}) []string {
+	var unmatched []string
+	for resourceType, byPath := range defaults {
+		rs, ok := s.ResourceSchemas[resourceType]
+		for path, def := range byPath {
+			var attr *schema.AttributeSchema
+			if ok && rs.Block != nil {
+				attr = attributeAt(rs.Block, path)
+			}
+			if attr == nil {
+				unmatched = append(unmatched, resourceType+"."+path)
+				continue
+			}
+			attr.Default = def
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// mergeDeprecationMessages sets the deprecation messages found in the provider source on
+// the resource types and attributes of the schema; an empty path is the resource type itself.
+// Returns the "type.path" of attributes that are not in the schema, sorted.
+func mergeDeprecationMessages(s *schema.Schema, messages map[string]map[string]string) []string {
+	var unmatched []string
+	for resourceType, byPath := range messages {
+		rs, ok := s.ResourceSchemas[resourceType]
+		for path, message := range byPath {
+			if path == "" {
+				if ok && rs.Block != nil {
+					rs.Block.Deprecated = true
+					rs.Block.DeprecationMessage = message
+				}
+				continue
+			}
+			var attr *schema.AttributeSchema
+			if ok && rs.Block != nil {
+				attr = attributeAt(rs.Block, path)
+			}
+			if attr == nil {
+				unmatched = append(unmatched, resourceType+"."+path)
+				continue
+			}
+			attr.Deprecated = true
+			attr.DeprecationMessage = message
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// providerSchemaOutput is the output of `terraform providers schema -json`.
+// It is decoded into its own types rather than schema.Schema, so that changes to the
+// embedded format do not break reading Terraform's format, and the reverse.
+type providerSchemaOutput struct {
+	ProviderSchemas map[string]*providerSchema `json:"provider_schemas"`
+}
+
+// providerSchema is the schema of a single provider.
+type providerSchema struct {
+	ResourceSchemas map[string]*providerResource `json:"resource_schemas"`
+}
+
+// providerResource is the schema of a resource type.
+type providerResource struct {
+	Block *providerBlock `json:"block"`
+}
+
+// providerBlock is a block of a resource schema.
+type providerBlock struct {
+	Attributes map[string]*providerAttribute   `json:"attributes"`
+	BlockTypes map[string]*providerNestedBlock `json:"block_types"`
+	Deprecated bool                            `json:"deprecated"`
+}
+
+// providerAttribute is an attribute of a block.
+type providerAttribute struct {
+	Type        interface{} `json:"type"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required"`
+	Optional    bool        `json:"optional"`
+	Computed    bool        `json:"computed"`
+	Sensitive   bool        `json:"sensitive"`
+	Deprecated  bool        `json:"deprecated"`
+}
+
+// providerNestedBlock is a nested block type of a block.
+type providerNestedBlock struct {
+	NestingMode string         `json:"nesting_mode"`
+	Block       *providerBlock `json:"block"`
+	MinItems    int            `json:"min_items"`
+	MaxItems    int            `json:"max_items"`
+}
+
+// parseProviderSchema parses the output of `terraform providers schema -json` and
+// returns the resource schemas of a provider, such as "registry.terraform.io/hashicorp/azurerm".
+func parseProviderSchema(data []byte, providerKey string) (*schema.Schema, error) {
+	var output providerSchemaOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("parse terraform schema: %w", err)
+	}
+	provider, ok := output.ProviderSchemas[providerKey]
+	if !ok {
+		return nil, fmt.Errorf("provider %s not found in schema", providerKey)
+	}
+
+	s := &schema.Schema{ResourceSchemas: make(map[string]*schema.ResourceSchema, len(provider.ResourceSchemas))}
+	for resourceType, resource := range provider.ResourceSchemas {
+		s.ResourceSchemas[resourceType] = &schema.ResourceSchema{Block: convertBlock(resource.Block)}
+	}
+	return s, nil
+}
+
+// convertBlock converts a block of the provider schema to the embedded format.
+func convertBlock(block *providerBlock) *schema.BlockSchema {
+	if block == nil {
+		return nil
+	}
+	out := &schema.BlockSchema{Deprecated: block.Deprecated}
+	if len(block.Attributes) > 0 {
+		out.Attributes = make(map[string]*schema.AttributeSchema, len(block.Attributes))
+	}
+	for name, attr := range block.Attributes {
+		out.Attributes[name] = &schema.AttributeSchema{
+			Type:        attr.Type,
+			Description: attr.Description,
+			Required:    attr.Required,
+			Optional:    attr.Optional,
+			Computed:    attr.Computed,
+			Sensitive:   attr.Sensitive,
+			Deprecated:  attr.Deprecated,
+		}
+	}
+	if len(block.BlockTypes) > 0 {
+		out.BlockTypes = make(map[string]*schema.NestedBlockSchema, len(block.BlockTypes))
+	}
+	for name, nested := range block.BlockTypes {
+		out.BlockTypes[name] = &schema.NestedBlockSchema{
+			NestingMode: nested.NestingMode,
+			Block:       convertBlock(nested.Block),
+			MinItems:    nested.MinItems,
+			MaxItems:    nested.MaxItems,
+		}
+	}
+	return out
+}
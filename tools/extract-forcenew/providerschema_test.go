@@ -0,0 +1,144 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// terraformSchemaOutput is shaped like `terraform providers schema -json` output, including
+// the fields the tool ignores and the deprecated bools that the embedded format once
+// decoded as strings.
+const terraformSchemaOutput = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/hashicorp/azurerm": {
+      "provider": {
+        "version": 0,
+        "block": {
+          "attributes": {
+            "subscription_id": {"type": "string", "description": "The Subscription ID.", "description_kind": "plain", "optional": true}
+          },
+          "description_kind": "plain"
+        }
+      },
+      "resource_schemas": {
+        "azurerm_storage_account": {
+          "version": 4,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "description_kind": "plain", "optional": true, "computed": true},
+              "name": {"type": "string", "description_kind": "plain", "required": true},
+              "account_kind": {"type": "string", "description_kind": "plain", "optional": true},
+              "enable_https_traffic_only": {"type": "bool", "description_kind": "plain", "optional": true, "computed": true, "deprecated": true},
+              "primary_access_key": {"type": "string", "description_kind": "plain", "computed": true, "sensitive": true},
+              "tags": {"type": ["map", "string"], "description_kind": "plain", "optional": true}
+            },
+            "block_types": {
+              "network_rules": {
+                "nesting_mode": "list",
+                "block": {
+                  "attributes": {
+                    "bypass": {"type": ["set", "string"], "description_kind": "plain", "optional": true, "computed": true}
+                  },
+                  "description_kind": "plain"
+                },
+                "max_items": 1
+              },
+              "timeouts": {
+                "nesting_mode": "single",
+                "block": {
+                  "attributes": {
+                    "create": {"type": "string", "description_kind": "plain", "optional": true}
+                  },
+                  "description_kind": "plain"
+                }
+              }
+            },
+            "description_kind": "plain"
+          }
+        },
+        "azurerm_app_service": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "name": {"type": "string", "description_kind": "plain", "required": true}
+            },
+            "description_kind": "plain",
+            "deprecated": true
+          }
+        }
+      },
+      "data_source_schemas": {
+        "azurerm_storage_account": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "name": {"type": "string", "description_kind": "plain", "required": true}
+            },
+            "description_kind": "plain"
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestParseProviderSchema(t *testing.T) {
+	s, err := parseProviderSchema([]byte(terraformSchemaOutput), "registry.terraform.io/hashicorp/azurerm")
+	if err != nil {
+		t.Fatalf("parseProviderSchema failed: %v", err)
+	}
+
+	types := s.GetResourceTypes()
+	if len(types) != 2 {
+		t.Errorf("Expected the 2 resource types without data sources, got %v", types)
+	}
+	if attr := s.GetAttribute("azurerm_storage_account", "enable_https_traffic_only"); attr == nil || !attr.Deprecated {
+		t.Errorf("Expected enable_https_traffic_only to be deprecated, got %+v", attr)
+	}
+	if _, ok := s.GetResourceDeprecation("azurerm_app_service"); !ok {
+		t.Error("Expected azurerm_app_service to be deprecated")
+	}
+	if attr := s.GetAttribute("azurerm_storage_account", "primary_access_key"); attr == nil || !attr.Sensitive || !attr.Computed {
+		t.Errorf("Expected primary_access_key to be computed and sensitive, got %+v", attr)
+	}
+	if ty := s.GetAttribute("azurerm_storage_account", "network_rules.bypass").CtyType(); !ty.Equals(cty.Set(cty.String)) {
+		t.Errorf("Expected network_rules.bypass to be a set of strings, got %#v", ty)
+	}
+
+	// The source analysis is merged into the parsed schema
+	unmatched := mergeForceNew(s, map[string][]string{"azurerm_storage_account": {"name", "account_kind", "network_rules"}})
+	if len(unmatched) != 0 {
+		t.Errorf("unmatched = %v, want none", unmatched)
+	}
+	got := s.GetForceNewAttributes("azurerm_storage_account")
+	sort.Strings(got)
+	want := []string{"account_kind", "name", "network_rules.bypass"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetForceNewAttributes() = %v, want %v", got, want)
+	}
+}
+
+func TestParseProviderSchema_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		key     string
+		wantErr string
+	}{
+		{"invalid JSON", "not json", "registry.terraform.io/hashicorp/azurerm", "parse terraform schema"},
+		{"missing provider", terraformSchemaOutput, "registry.terraform.io/hashicorp/azuread", "provider registry.terraform.io/hashicorp/azuread not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseProviderSchema([]byte(tt.data), tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -45,6 +45,7 @@ type ResourceSchema struct {
 type BlockSchema struct {
 	Attributes map[string]*AttributeSchema   `json:"attributes,omitempty"`
 	BlockTypes map[string]*NestedBlockSchema `json:"block_types,omitempty"`
+	Deprecated bool                          `json:"deprecated,omitempty"`
 }
 
 // AttributeSchema represents an attribute.
@@ -56,7 +57,7 @@ type AttributeSchema struct {
 	Computed    bool        `json:"computed,omitempty"`
 	ForceNew    bool        `json:"force_new,omitempty"`
 	Sensitive   bool        `json:"sensitive,omitempty"`
-	Deprecated  string      `json:"deprecated,omitempty"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
 }
 
 // NestedBlockSchema represents a nested block.
@@ -0,0 +1,134 @@
+// Package main provides a tool to measure azurerm_force_new against a Terraform plan.
+// Static analysis has blind spots: attributes missing from the ForceNew schema, values
+// that cannot be resolved without state, and provider behavior the schema cannot express.
+// This tool runs azurerm_force_new on an old and a new configuration, compares the
+// resources it reports as recreated with the resources that the plan of the new
+// configuration replaces, and reports precision, recall and candidate overlay entries.
+//
+// Usage:
+//
+//	terraform plan -out plan.out && terraform show -json plan.out > plan.json
+//	go run ./tools/plan-oracle -plan plan.json -old /tmp/main -new .
+//
+// The plan must be made against state that matches the old configuration, so that its
+// replacements are caused by the change from the old to the new configuration.
+// Findings are compared per resource block, ignoring instance keys. Candidate overlay
+// entries are a starting point for schema/overlay.json, and need to be confirmed
+// against the provider documentation.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/rules"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+func main() {
+	planPath := flag.String("plan", "", "Path to the output of `terraform show -json` for a saved plan")
+	oldDir := flag.String("old", "", "Root module directory of the old configuration")
+	newDir := flag.String("new", "", "Root module directory of the new configuration")
+	jsonOutput := flag.Bool("json", false, "Print the report as JSON")
+	minPrecision := flag.Float64("min-precision", 0, "Exit with status 1 if precision is below this value (0-1)")
+	minRecall := flag.Float64("min-recall", 0, "Exit with status 1 if recall is below this value (0-1)")
+	flag.Parse()
+
+	if *planPath == "" || *oldDir == "" || *newDir == "" {
+		fmt.Fprintln(os.Stderr, "-plan, -old and -new are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	data, err := os.ReadFile(*planPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading plan: %v\n", err)
+		os.Exit(1)
+	}
+	plan, err := parsePlan(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	runner, err := newDirRunner(*oldDir, *newDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configurations: %v\n", err)
+		os.Exit(1)
+	}
+	if err := rules.NewAzurermForceNewRule().Check(runner); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running azurerm_force_new: %v\n", err)
+		os.Exit(1)
+	}
+
+	reported, findings := reportedReplacements(runner.issues)
+	r := compare(reported, plannedReplacements(plan, schema.Load()), findings)
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(r); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding report: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		printReport(os.Stdout, r)
+	}
+
+	if r.Precision < *minPrecision || r.Recall < *minRecall {
+		os.Exit(1)
+	}
+}
+
+// printReport prints a report for humans.
+func printReport(w io.Writer, r *report) {
+	fmt.Fprintf(w, "True positives:  %d\n", len(r.TruePositives))
+	fmt.Fprintf(w, "False positives: %d\n", len(r.FalsePositives))
+	fmt.Fprintf(w, "False negatives: %d\n", len(r.FalseNegatives))
+	fmt.Fprintf(w, "Precision: %.1f%%\n", r.Precision*100)
+	fmt.Fprintf(w, "Recall:    %.1f%%\n", r.Recall*100)
+
+	printResults(w, "False positives (reported, not replaced in the plan)", r.FalsePositives)
+	printResults(w, "False negatives (replaced in the plan, not reported)", r.FalseNegatives)
+
+	if len(r.Suggestions) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(r.Suggestions))
+	for key := range r.Suggestions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprintln(w, "\nCandidate overlay entries (schema/overlay.json):")
+	for _, key := range keys {
+		suggestion := r.Suggestions[key]
+		fmt.Fprintf(w, "  %q: {\"force_new\": %t}  # %s\n", key, suggestion.ForceNew, suggestion.Reason)
+	}
+}
+
+// printResults prints a list of resource results under a heading, if there are any.
+func printResults(w io.Writer, heading string, results []resourceResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", heading)
+	for _, result := range results {
+		var details []string
+		if len(result.Reported) > 0 {
+			details = append(details, "reported: "+strings.Join(result.Reported, ", "))
+		}
+		if len(result.Planned) > 0 {
+			details = append(details, "replace_paths: "+strings.Join(result.Planned, ", "))
+		}
+		fmt.Fprintf(w, "  %s", result.Address)
+		if len(details) > 0 {
+			fmt.Fprintf(w, " (%s)", strings.Join(details, "; "))
+		}
+		fmt.Fprintln(w)
+	}
+}
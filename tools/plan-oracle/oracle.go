@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// findingPrefix starts the line of an issue message that holds the finding payload.
+const findingPrefix = "\nfinding: "
+
+// planOutput is the part of `terraform show -json` plan output that the oracle reads.
+type planOutput struct {
+	ResourceChanges []resourceChange `json:"resource_changes"`
+}
+
+// resourceChange is the planned change of a resource instance.
+type resourceChange struct {
+	Address      string `json:"address"`
+	Mode         string `json:"mode"`
+	Type         string `json:"type"`
+	ActionReason string `json:"action_reason"`
+	Change       struct {
+		Actions []string `json:"actions"`
+		// ReplacePaths are the attribute paths that force the replacement, with
+		// strings for attribute names and map keys, and numbers for list indexes.
+		ReplacePaths [][]interface{} `json:"replace_paths"`
+	} `json:"change"`
+}
+
+// finding is the part of the azurerm_force_new finding payload that the oracle reads.
+type finding struct {
+	Category     string `json:"category"`
+	ResourceType string `json:"resource_type"`
+	Address      string `json:"address"`
+	Attribute    string `json:"attribute"`
+	OldValue     string `json:"old_value"`
+	NewValue     string `json:"new_value"`
+}
+
+// resourceResult is the predicted and planned replacement of a resource.
+type resourceResult struct {
+	Address      string `json:"address"`
+	ResourceType string `json:"resource_type"`
+	// Reported are the attributes that azurerm_force_new reported, or "replace_triggered_by".
+	Reported []string `json:"reported,omitempty"`
+	// Planned are the attributes in the plan's replace_paths.
+	Planned []string `json:"planned,omitempty"`
+}
+
+// overlaySuggestion is a candidate correction for the schema overlay.
+type overlaySuggestion struct {
+	ForceNew bool   `json:"force_new"`
+	Reason   string `json:"reason"`
+}
+
+// report compares the replacements reported by azurerm_force_new with those in a plan.
+type report struct {
+	TruePositives  []resourceResult `json:"true_positives"`
+	FalsePositives []resourceResult `json:"false_positives"`
+	FalseNegatives []resourceResult `json:"false_negatives"`
+	Precision      float64          `json:"precision"`
+	Recall         float64          `json:"recall"`
+	// Suggestions are overlay candidates by "type.path", for attributes that the plan replaced
+	// but were not reported, or that were reported with resolved values but not replaced.
+	Suggestions map[string]overlaySuggestion `json:"suggestions,omitempty"`
+}
+
+// instanceKeys matches the instance keys of a resource address, e.g. `[0]` or `["a"]`.
+var instanceKeys = regexp.MustCompile(`\[[^\]]*\]`)
+
+// resourceAddress removes the instance keys from a resource instance address, since
+// findings are reported per resource block.
+func resourceAddress(address string) string {
+	return instanceKeys.ReplaceAllString(address, "")
+}
+
+// parsePlan parses `terraform show -json` plan output.
+func parsePlan(data []byte) (*planOutput, error) {
+	var plan planOutput
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// plannedReplacements returns the azurerm resources that a plan replaces because of their
+// configuration, by resource address. Replacements of tainted resources are left out.
+func plannedReplacements(plan *planOutput, s *schema.Schema) map[string]*resourceResult {
+	replaced := make(map[string]*resourceResult)
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode != "managed" || !strings.HasPrefix(rc.Type, "azurerm_") || rc.ActionReason == "replace_because_tainted" {
+			continue
+		}
+		if !contains(rc.Change.Actions, "delete") || !contains(rc.Change.Actions, "create") {
+			continue
+		}
+		address := resourceAddress(rc.Address)
+		result, ok := replaced[address]
+		if !ok {
+			result = &resourceResult{Address: address, ResourceType: rc.Type}
+			replaced[address] = result
+		}
+		if rc.ActionReason == "replace_by_triggers" {
+			result.Planned = appendUnique(result.Planned, "replace_triggered_by")
+		}
+		for _, path := range rc.Change.ReplacePaths {
+			result.Planned = appendUnique(result.Planned, attributePath(s, rc.Type, path))
+		}
+	}
+	return replaced
+}
+
+// attributePath converts a replace path to a schema attribute path, e.g.
+// ["identity", 0, "type"] to "identity.type". List indexes are dropped, and the path
+// ends at the first schema attribute, so that map keys such as tag names are dropped.
+func attributePath(s *schema.Schema, resourceType string, path []interface{}) string {
+	var parts []string
+	for _, step := range path {
+		name, ok := step.(string)
+		if !ok {
+			continue
+		}
+		parts = append(parts, name)
+		if s.GetAttribute(resourceType, strings.Join(parts, ".")) != nil {
+			break
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// reportedReplacements returns the azurerm resources that the issues report as recreated,
+// by resource address, and the findings of reported ForceNew attribute changes.
+func reportedReplacements(issues []issue) (map[string]*resourceResult, []finding) {
+	reported := make(map[string]*resourceResult)
+	var findings []finding
+	for _, is := range issues {
+		i := strings.LastIndex(is.Message, findingPrefix)
+		if i < 0 {
+			continue
+		}
+		var f finding
+		if err := json.Unmarshal([]byte(is.Message[i+len(findingPrefix):]), &f); err != nil {
+			continue
+		}
+		var attribute string
+		switch f.Category {
+		case "force_new":
+			attribute = f.Attribute
+			findings = append(findings, f)
+		case "replace_triggered_by":
+			attribute = "replace_triggered_by"
+		default:
+			continue // One-way changes are applied in place
+		}
+		result, ok := reported[f.Address]
+		if !ok {
+			result = &resourceResult{Address: f.Address, ResourceType: f.ResourceType}
+			reported[f.Address] = result
+		}
+		result.Reported = appendUnique(result.Reported, attribute)
+	}
+	return reported, findings
+}
+
+// compare builds the report of the reported and planned replacements.
+func compare(reported, planned map[string]*resourceResult, findings []finding) *report {
+	r := &report{Suggestions: make(map[string]overlaySuggestion)}
+	for address, result := range reported {
+		if plannedResult, ok := planned[address]; ok {
+			result.Planned = plannedResult.Planned
+			r.TruePositives = append(r.TruePositives, *result)
+		} else {
+			r.FalsePositives = append(r.FalsePositives, *result)
+		}
+	}
+	for address, result := range planned {
+		if _, ok := reported[address]; !ok {
+			r.FalseNegatives = append(r.FalseNegatives, *result)
+		}
+	}
+	for _, results := range [][]resourceResult{r.TruePositives, r.FalsePositives, r.FalseNegatives} {
+		sort.Slice(results, func(i, j int) bool { return results[i].Address < results[j].Address })
+	}
+	r.Precision = ratio(len(r.TruePositives), len(r.TruePositives)+len(r.FalsePositives))
+	r.Recall = ratio(len(r.TruePositives), len(r.TruePositives)+len(r.FalseNegatives))
+
+	// Planned attributes that were not reported are missing ForceNew facts
+	for _, result := range append(append([]resourceResult(nil), r.TruePositives...), r.FalseNegatives...) {
+		for _, attribute := range result.Planned {
+			if attribute != "replace_triggered_by" && !contains(result.Reported, attribute) {
+				r.Suggestions[result.ResourceType+"."+attribute] = overlaySuggestion{
+					ForceNew: true,
+					Reason:   fmt.Sprintf("replaced %s in the plan but was not reported", result.Address),
+				}
+			}
+		}
+	}
+	// Reported attributes of resources that were not replaced are wrong ForceNew facts,
+	// unless the values could not be resolved and the change may not have happened
+	for _, f := range findings {
+		if _, ok := planned[f.Address]; ok || isUnresolved(f.OldValue) || isUnresolved(f.NewValue) {
+			continue
+		}
+		key := f.ResourceType + "." + f.Attribute
+		if _, ok := r.Suggestions[key]; !ok {
+			r.Suggestions[key] = overlaySuggestion{
+				Reason: fmt.Sprintf("reported for %s (old: %s, new: %s) but not replaced in the plan", f.Address, f.OldValue, f.NewValue),
+			}
+		}
+	}
+	return r
+}
+
+// isUnresolved reports whether a displayed finding value is a placeholder for a value that
+// could not be statically determined.
+func isUnresolved(value string) bool {
+	switch value {
+	case "<dynamic>", "<unknown>", "<sensitive>":
+		return true
+	}
+	return strings.Contains(value, "${")
+}
+
+// ratio returns n/d, or 1 if there is nothing to measure.
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
+
+// contains reports whether a slice contains a value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// appendUnique appends a value to a slice if it is not in it yet.
+func appendUnique(values []string, value string) []string {
+	if contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jokarl/tfbreak-ruleset-azurerm/rules"
+	"github.com/jokarl/tfbreak-ruleset-azurerm/schema"
+)
+
+// planFixture replaces a resource group because of its location, a storage account because
+// of a nested attribute, and a tainted virtual network, and updates a resource in place.
+const planFixture = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "azurerm_resource_group.main",
+      "mode": "managed",
+      "type": "azurerm_resource_group",
+      "change": {"actions": ["delete", "create"], "replace_paths": [["location"]]}
+    },
+    {
+      "address": "azurerm_storage_account.main[\"a\"]",
+      "mode": "managed",
+      "type": "azurerm_storage_account",
+      "change": {"actions": ["create", "delete"], "replace_paths": [["account_kind"], ["tags", "env"]]}
+    },
+    {
+      "address": "azurerm_storage_account.main[\"b\"]",
+      "mode": "managed",
+      "type": "azurerm_storage_account",
+      "change": {"actions": ["delete", "create"], "replace_paths": [["account_kind"]]}
+    },
+    {
+      "address": "azurerm_virtual_network.main",
+      "mode": "managed",
+      "type": "azurerm_virtual_network",
+      "action_reason": "replace_because_tainted",
+      "change": {"actions": ["delete", "create"]}
+    },
+    {
+      "address": "azurerm_key_vault.main",
+      "mode": "managed",
+      "type": "azurerm_key_vault",
+      "change": {"actions": ["update"]}
+    },
+    {
+      "address": "data.azurerm_client_config.current",
+      "mode": "data",
+      "type": "azurerm_client_config",
+      "change": {"actions": ["read"]}
+    }
+  ]
+}`
+
+func TestPlannedReplacements(t *testing.T) {
+	plan, err := parsePlan([]byte(planFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := plannedReplacements(plan, schema.Load())
+	want := map[string]*resourceResult{
+		"azurerm_resource_group.main": {
+			Address: "azurerm_resource_group.main", ResourceType: "azurerm_resource_group",
+			Planned: []string{"location"},
+		},
+		"azurerm_storage_account.main": {
+			Address: "azurerm_storage_account.main", ResourceType: "azurerm_storage_account",
+			Planned: []string{"account_kind", "tags"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plannedReplacements() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePlan_Invalid(t *testing.T) {
+	if _, err := parsePlan([]byte("not json")); err == nil {
+		t.Error("parsePlan() succeeded, want an error")
+	}
+}
+
+func TestAttributePath(t *testing.T) {
+	s := schema.Load()
+	tests := []struct {
+		path []interface{}
+		want string
+	}{
+		{[]interface{}{"location"}, "location"},
+		{[]interface{}{"identity", float64(0), "type"}, "identity.type"},
+		{[]interface{}{"tags", "env"}, "tags"},
+		{[]interface{}{"unknown", "nested"}, "unknown.nested"},
+	}
+	for _, tt := range tests {
+		if got := attributePath(s, "azurerm_storage_account", tt.path); got != tt.want {
+			t.Errorf("attributePath(%v) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReportedReplacements(t *testing.T) {
+	issues := []issue{
+		{Message: "Changing location forces recreation of azurerm_resource_group.main.\n" +
+			`finding: {"version":1,"category":"force_new","resource_type":"azurerm_resource_group","address":"azurerm_resource_group.main","attribute":"location","old_value":"\"westeurope\"","new_value":"\"northeurope\""}`},
+		{Message: "azurerm_subnet.main is replaced when azurerm_resource_group.main is replaced.\n" +
+			`finding: {"version":1,"category":"replace_triggered_by","resource_type":"azurerm_subnet","address":"azurerm_subnet.main"}`},
+		{Message: "One-way change.\n" +
+			`finding: {"version":1,"category":"one_way","resource_type":"azurerm_storage_account","address":"azurerm_storage_account.main","attribute":"account_tier"}`},
+		{Message: "Deprecated ForceNew configuration."},
+		{Message: "Broken payload.\nfinding: {"},
+	}
+
+	reported, findings := reportedReplacements(issues)
+	want := map[string]*resourceResult{
+		"azurerm_resource_group.main": {
+			Address: "azurerm_resource_group.main", ResourceType: "azurerm_resource_group",
+			Reported: []string{"location"},
+		},
+		"azurerm_subnet.main": {
+			Address: "azurerm_subnet.main", ResourceType: "azurerm_subnet",
+			Reported: []string{"replace_triggered_by"},
+		},
+	}
+	if !reflect.DeepEqual(reported, want) {
+		t.Errorf("reportedReplacements() = %+v, want %+v", reported, want)
+	}
+	if len(findings) != 1 || findings[0].Attribute != "location" {
+		t.Errorf("findings = %+v, want the location finding", findings)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	reported := map[string]*resourceResult{
+		"azurerm_resource_group.main": {
+			Address: "azurerm_resource_group.main", ResourceType: "azurerm_resource_group",
+			Reported: []string{"location"},
+		},
+		"azurerm_key_vault.main": {
+			Address: "azurerm_key_vault.main", ResourceType: "azurerm_key_vault",
+			Reported: []string{"tenant_id"},
+		},
+		"azurerm_subnet.main": {
+			Address: "azurerm_subnet.main", ResourceType: "azurerm_subnet",
+			Reported: []string{"name"},
+		},
+	}
+	planned := map[string]*resourceResult{
+		"azurerm_resource_group.main": {
+			Address: "azurerm_resource_group.main", ResourceType: "azurerm_resource_group",
+			Planned: []string{"location", "managed_by"},
+		},
+		"azurerm_storage_account.main": {
+			Address: "azurerm_storage_account.main", ResourceType: "azurerm_storage_account",
+			Planned: []string{"account_kind"},
+		},
+	}
+	findings := []finding{
+		{Category: "force_new", ResourceType: "azurerm_resource_group", Address: "azurerm_resource_group.main", Attribute: "location", OldValue: `"westeurope"`, NewValue: `"northeurope"`},
+		{Category: "force_new", ResourceType: "azurerm_key_vault", Address: "azurerm_key_vault.main", Attribute: "tenant_id", OldValue: `"a"`, NewValue: `"b"`},
+		{Category: "force_new", ResourceType: "azurerm_subnet", Address: "azurerm_subnet.main", Attribute: "name", OldValue: `"a"`, NewValue: "${var.name}"},
+	}
+
+	r := compare(reported, planned, findings)
+
+	if len(r.TruePositives) != 1 || r.TruePositives[0].Address != "azurerm_resource_group.main" {
+		t.Errorf("TruePositives = %+v", r.TruePositives)
+	}
+	if !reflect.DeepEqual(r.TruePositives[0].Planned, []string{"location", "managed_by"}) {
+		t.Errorf("TruePositives[0].Planned = %v", r.TruePositives[0].Planned)
+	}
+	if len(r.FalsePositives) != 2 || r.FalsePositives[0].Address != "azurerm_key_vault.main" || r.FalsePositives[1].Address != "azurerm_subnet.main" {
+		t.Errorf("FalsePositives = %+v", r.FalsePositives)
+	}
+	if len(r.FalseNegatives) != 1 || r.FalseNegatives[0].Address != "azurerm_storage_account.main" {
+		t.Errorf("FalseNegatives = %+v", r.FalseNegatives)
+	}
+	if r.Precision != 1.0/3 || r.Recall != 0.5 {
+		t.Errorf("Precision, Recall = %v, %v, want 1/3, 0.5", r.Precision, r.Recall)
+	}
+
+	var keys []string
+	for key, suggestion := range r.Suggestions {
+		keys = append(keys, key)
+		wantForceNew := key != "azurerm_key_vault.tenant_id"
+		if suggestion.ForceNew != wantForceNew {
+			t.Errorf("Suggestions[%q].ForceNew = %t, want %t", key, suggestion.ForceNew, wantForceNew)
+		}
+	}
+	if len(keys) != 3 {
+		t.Errorf("Suggestions = %v, want managed_by, account_kind and tenant_id (unresolved values are not suggested)", r.Suggestions)
+	}
+}
+
+func TestCompare_Empty(t *testing.T) {
+	r := compare(nil, nil, nil)
+	if r.Precision != 1 || r.Recall != 1 {
+		t.Errorf("Precision, Recall = %v, %v, want 1, 1", r.Precision, r.Recall)
+	}
+}
+
+func TestDirRunner_ForceNew(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(oldDir, "main.tf"), `
+resource "azurerm_resource_group" "main" {
+  name     = "rg"
+  location = "westeurope"
+}
+`)
+	writeFile(t, filepath.Join(newDir, "main.tf"), `
+resource "azurerm_resource_group" "main" {
+  name     = "rg"
+  location = "northeurope"
+}
+`)
+	writeFile(t, filepath.Join(newDir, "README.md"), "not terraform")
+
+	runner, err := newDirRunner(oldDir, newDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rules.NewAzurermForceNewRule().Check(runner); err != nil {
+		t.Fatal(err)
+	}
+
+	reported, _ := reportedReplacements(runner.issues)
+	result, ok := reported["azurerm_resource_group.main"]
+	if !ok || !reflect.DeepEqual(result.Reported, []string{"location"}) {
+		t.Errorf("reported = %+v, want azurerm_resource_group.main with location", reported)
+	}
+}
+
+func TestNewDirRunner_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := newDirRunner(filepath.Join(dir, "missing"), dir); err == nil {
+		t.Error("newDirRunner() with a missing directory succeeded, want an error")
+	}
+
+	writeFile(t, filepath.Join(dir, "main.tf"), `resource "azurerm_resource_group" "main" {`)
+	if _, err := newDirRunner(dir, dir); err == nil {
+		t.Error("newDirRunner() with invalid HCL succeeded, want an error")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/jokarl/tfbreak-plugin-sdk/hclext"
+	"github.com/jokarl/tfbreak-plugin-sdk/tflint"
+)
+
+// issue is an issue emitted by a rule.
+type issue struct {
+	Message string
+	Range   hcl.Range
+}
+
+// dirRunner is a tflint.Runner over the Terraform files of an old and a new root module
+// directory. Attributes carry only their expression, as with the local tfbreak runner,
+// and rules run with their default configuration.
+type dirRunner struct {
+	oldFiles []*hcl.File
+	newFiles []*hcl.File
+	issues   []issue
+}
+
+// newDirRunner parses the Terraform files of the old and new root module directories.
+func newDirRunner(oldDir, newDir string) (*dirRunner, error) {
+	oldFiles, err := parseDir(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := parseDir(newDir)
+	if err != nil {
+		return nil, err
+	}
+	return &dirRunner{oldFiles: oldFiles, newFiles: newFiles}, nil
+}
+
+// parseDir parses the .tf files of a directory in file name order. File names keep the
+// directory, so that rules can resolve local module sources relative to them.
+func parseDir(dir string) ([]*hcl.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tf") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	parser := hclparse.NewParser()
+	files := make([]*hcl.File, 0, len(names))
+	for _, name := range names {
+		file, diags := parser.ParseHCLFile(filepath.Join(dir, name))
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Join(dir, name), diags)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// GetOldModuleContent retrieves content from the old configuration.
+func (r *dirRunner) GetOldModuleContent(schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return filesContent(r.oldFiles, schema)
+}
+
+// GetNewModuleContent retrieves content from the new configuration.
+func (r *dirRunner) GetNewModuleContent(schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return filesContent(r.newFiles, schema)
+}
+
+// GetOldResourceContent retrieves resources of a type from the old configuration.
+func (r *dirRunner) GetOldResourceContent(resourceType string, schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return resourceContent(r.oldFiles, resourceType, schema)
+}
+
+// GetNewResourceContent retrieves resources of a type from the new configuration.
+func (r *dirRunner) GetNewResourceContent(resourceType string, schema *hclext.BodySchema, _ *tflint.GetModuleContentOption) (*hclext.BodyContent, error) {
+	return resourceContent(r.newFiles, resourceType, schema)
+}
+
+// EmitIssue records an issue.
+func (r *dirRunner) EmitIssue(_ tflint.Rule, message string, issueRange hcl.Range) error {
+	r.issues = append(r.issues, issue{Message: message, Range: issueRange})
+	return nil
+}
+
+// DecodeRuleConfig leaves the rule configuration at its defaults.
+func (r *dirRunner) DecodeRuleConfig(_ string, _ any) error {
+	return nil
+}
+
+// filesContent extracts the content of a set of files.
+func filesContent(files []*hcl.File, schema *hclext.BodySchema) (*hclext.BodyContent, error) {
+	content := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	for _, file := range files {
+		fileContent, err := bodyContent(file.Body, schema)
+		if err != nil {
+			return nil, err
+		}
+		for name, attr := range fileContent.Attributes {
+			content.Attributes[name] = attr
+		}
+		content.Blocks = append(content.Blocks, fileContent.Blocks...)
+	}
+	return content, nil
+}
+
+// bodyContent extracts the content of a body and its nested blocks.
+func bodyContent(body hcl.Body, schema *hclext.BodySchema) (*hclext.BodyContent, error) {
+	content := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	if schema == nil {
+		return content, nil
+	}
+	hclContent, _, diags := body.PartialContent(hclext.ToHCLBodySchema(schema))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	for name, attr := range hclContent.Attributes {
+		content.Attributes[name] = hclext.FromHCLAttribute(attr)
+	}
+	for _, hclBlock := range hclContent.Blocks {
+		block := hclext.FromHCLBlock(hclBlock)
+		for _, blockSchema := range schema.Blocks {
+			if blockSchema.Type != hclBlock.Type {
+				continue
+			}
+			nested, err := bodyContent(hclBlock.Body, blockSchema.Body)
+			if err != nil {
+				return nil, err
+			}
+			block.Body = nested
+		}
+		content.Blocks = append(content.Blocks, block)
+	}
+	return content, nil
+}
+
+// resourceContent retrieves the resources of a type from a set of files.
+func resourceContent(files []*hcl.File, resourceType string, schema *hclext.BodySchema) (*hclext.BodyContent, error) {
+	content, err := filesContent(files, &hclext.BodySchema{
+		Blocks: []hclext.BlockSchema{{Type: "resource", LabelNames: []string{"type", "name"}, Body: schema}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources := &hclext.BodyContent{Attributes: make(map[string]*hclext.Attribute)}
+	for _, block := range content.Blocks {
+		if block.Labels[0] == resourceType {
+			resources.Blocks = append(resources.Blocks, block)
+		}
+	}
+	return resources, nil
+}